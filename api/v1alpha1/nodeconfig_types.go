@@ -34,6 +34,9 @@ type NodeConfigSpec struct {
 	Instance   InstanceOptions   `json:"instance,omitempty"`
 	Kubelet    KubeletOptions    `json:"kubelet,omitempty"`
 	Hybrid     *HybridOptions    `json:"hybrid,omitempty"`
+	Proxy      ProxyOptions      `json:"proxy,omitempty"`
+	Trust      TrustOptions      `json:"trust,omitempty"`
+	CNI        CNIOptions        `json:"cni,omitempty"`
 }
 
 // ClusterDetails contains the coordinates of your EKS cluster.
@@ -71,6 +74,11 @@ type KubeletOptions struct {
 	// Flags are [command-line `kubelet`` arguments](https://kubernetes.io/docs/reference/command-line-tools-reference/kubelet/).
 	// that will be appended to the defaults.
 	Flags []string `json:"flags,omitempty"`
+
+	// NodeIPInterface is the name of the network interface whose address is used
+	// as the node IP on hybrid nodes. By default the address in the cluster's
+	// remote node networks is used. Ignored when the `--node-ip` flag is set.
+	NodeIPInterface string `json:"nodeIPInterface,omitempty"`
 }
 
 // ContainerdOptions are additional parameters passed to `containerd`.
@@ -79,17 +87,202 @@ type ContainerdOptions struct {
 	// that will be [imported](https://github.com/containerd/containerd/blob/32169d591dbc6133ef7411329b29d0c0433f8c4d/docs/man/containerd-config.toml.5.md?plain=1#L146-L154)
 	// by the default configuration file.
 	Config string `json:"config,omitempty"`
+
+	// Registries configure how `containerd` pulls images from each registry. nodeadm renders a
+	// [`hosts.toml`](https://github.com/containerd/containerd/blob/main/docs/hosts.md) file for each of them
+	// in `/etc/containerd/certs.d/<host>/`.
+	Registries []RegistryOptions `json:"registries,omitempty"`
+
+	// Runtimes are additional runtime handlers, such as gVisor or Kata Containers, configured in the
+	// `containerd` CRI plugin next to the default `runc` handler. Pods select them through the
+	// `handler` of a [RuntimeClass](https://kubernetes.io/docs/concepts/containers/runtime-class/).
+	Runtimes []ContainerdRuntime `json:"runtimes,omitempty"`
+}
+
+// ContainerdRuntime is a runtime handler of the `containerd` CRI plugin.
+type ContainerdRuntime struct {
+	// Name is the name of the runtime handler, referenced by the `handler` of a RuntimeClass.
+	// It must be a DNS label, such as `runsc` or `kata`.
+	Name string `json:"name"`
+
+	// RuntimeType is the `containerd` shim that runs the containers, such as `io.containerd.runsc.v1`
+	// for gVisor or `io.containerd.kata.v2` for Kata Containers.
+	RuntimeType string `json:"runtimeType"`
+
+	// BinaryPath is the absolute path to the shim binary. Defaults to the shim named after the runtime
+	// type, such as `containerd-shim-runsc-v1`, found in the `PATH`.
+	BinaryPath string `json:"binaryPath,omitempty"`
+
+	// Options are passed to the shim, such as `TypeUrl` and `ConfigPath` for gVisor. The values keep
+	// their type in the `containerd` config: strings, numbers, booleans or arrays of them.
+	Options map[string]runtime.RawExtension `json:"options,omitempty"`
+}
+
+// RegistryOptions configure the hosts `containerd` uses to pull images from a registry.
+type RegistryOptions struct {
+	// Host is the registry namespace as it appears in image references, such as `docker.io`,
+	// `public.ecr.aws` or `registry.example.com:5000`. Use `_default` to configure every registry
+	// without configuration of its own.
+	Host string `json:"host"`
+
+	// Server is the URL of the upstream registry, used when none of the mirrors can serve a request.
+	// Defaults to `https://<host>`, or `https://registry-1.docker.io` for `docker.io`.
+	Server string `json:"server,omitempty"`
+
+	// Mirrors are tried in order before the upstream registry.
+	Mirrors []RegistryMirror `json:"mirrors,omitempty"`
+}
+
+// RegistryMirror is a registry host, such as a pull-through cache, that serves images on behalf of
+// the upstream registry.
+type RegistryMirror struct {
+	// Endpoint is the URL of the mirror, such as `https://mirror.example.com:5000`.
+	Endpoint string `json:"endpoint"`
+
+	// Capabilities are the operations the mirror is used for. Defaults to `pull` and `resolve`.
+	Capabilities []RegistryCapability `json:"capabilities,omitempty"`
+
+	// SkipVerify disables the verification of the mirror's TLS certificate.
+	SkipVerify bool `json:"skipVerify,omitempty"`
+
+	// CAFiles are paths to PEM-encoded certificate authorities used to verify the mirror's TLS
+	// certificate. The bundles from `spec.trust.additionalCABundles` are always included.
+	CAFiles []string `json:"caFiles,omitempty"`
+
+	// Auth are the credentials sent to the mirror.
+	Auth *RegistryAuth `json:"auth,omitempty"`
+}
+
+// RegistryCapability is an operation a registry host can be used for.
+// +kubebuilder:validation:Enum={pull, resolve, push}
+type RegistryCapability string
+
+const (
+	// RegistryCapabilityPull allows fetching image content by digest.
+	RegistryCapabilityPull RegistryCapability = "pull"
+
+	// RegistryCapabilityResolve allows resolving image tags to digests.
+	RegistryCapabilityResolve RegistryCapability = "resolve"
+
+	// RegistryCapabilityPush allows pushing images.
+	RegistryCapabilityPush RegistryCapability = "push"
+)
+
+// RegistryAuth are the basic authentication credentials of a registry mirror.
+type RegistryAuth struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
 }
 
 // InstanceOptions determines how the node's operating system and devices are configured.
 type InstanceOptions struct {
 	LocalStorage LocalStorageOptions `json:"localStorage,omitempty"`
+	DataDirs     DataDirOptions      `json:"dataDirs,omitempty"`
+	Swap         SwapOptions         `json:"swap,omitempty"`
+	// Sysctls are kernel parameters, such as `net.core.somaxconn: "4096"`, merged with the
+	// defaults nodeadm writes to `/etc/sysctl.d/99-nodeadm.conf`.
+	Sysctls map[string]string `json:"sysctls,omitempty"`
+	// KernelModules are loaded on boot in addition to the ones the container runtime needs,
+	// such as `ip_vs` for `kube-proxy` in IPVS mode.
+	KernelModules []string        `json:"kernelModules,omitempty"`
+	Firewall      FirewallOptions `json:"firewall,omitempty"`
+}
+
+// CNIOptions describe the CNI plugin of the cluster, so `nodeadm` opens its ports in the host
+// firewall.
+type CNIOptions struct {
+	// Plugin is the CNI plugin running on the node. When empty, `nodeadm init` checks that the
+	// Cilium or Calico VXLAN port is open instead of opening the CNI ports.
+	Plugin CNIPlugin `json:"plugin,omitempty"`
+
+	// Encapsulation is how pod traffic between nodes is tunneled. Defaults to `vxlan`.
+	Encapsulation CNIEncapsulation `json:"encapsulation,omitempty"`
+
+	// BGP opens the BGP port (179/tcp), for Calico BGP networking or the Cilium BGP control plane.
+	BGP bool `json:"bgp,omitempty"`
+}
+
+// CNIPlugin is the CNI plugin of the cluster.
+// +kubebuilder:validation:Enum={cilium, calico, other}
+type CNIPlugin string
+
+const (
+	// CNIPluginCilium opens the Cilium health (4240/tcp) and Hubble (4244/tcp) ports, and 8472/udp
+	// for VXLAN.
+	CNIPluginCilium CNIPlugin = "cilium"
+
+	// CNIPluginCalico opens the Calico Typha port (5473/tcp), and 4789/udp for VXLAN.
+	CNIPluginCalico CNIPlugin = "calico"
+
+	// CNIPluginOther only opens the encapsulation and BGP ports, 4789/udp for VXLAN.
+	CNIPluginOther CNIPlugin = "other"
+)
+
+// CNIEncapsulation is how pod traffic between nodes is tunneled.
+// +kubebuilder:validation:Enum={vxlan, geneve, none}
+type CNIEncapsulation string
+
+const (
+	CNIEncapsulationVXLAN CNIEncapsulation = "vxlan"
+
+	// CNIEncapsulationGeneve opens 6081/udp. Calico doesn't support it.
+	CNIEncapsulationGeneve CNIEncapsulation = "geneve"
+
+	// CNIEncapsulationNone is for native routing, usually with BGP.
+	CNIEncapsulationNone CNIEncapsulation = "none"
+)
+
+// FirewallOptions configure the firewall `nodeadm` opens the node ports in.
+type FirewallOptions struct {
+	// Backend is detected from the host when empty: `firewalld` or `ufw` when they are running,
+	// otherwise `iptables` or `nftables` when their input rules drop traffic.
+	Backend FirewallBackend `json:"backend,omitempty"`
+}
+
+// FirewallBackend is the firewall `nodeadm` adds the rules to.
+// +kubebuilder:validation:Enum={firewalld, ufw, nftables, iptables}
+type FirewallBackend string
+
+const (
+	FirewallBackendFirewalld FirewallBackend = "firewalld"
+	FirewallBackendUFW       FirewallBackend = "ufw"
+
+	// FirewallBackendNftables adds the rules to a `nodeadm-input` chain, in the table of the
+	// host's input chain.
+	FirewallBackendNftables FirewallBackend = "nftables"
+
+	// FirewallBackendIptables adds the rules to a `NODEADM-INPUT` chain of the `filter` table.
+	FirewallBackendIptables FirewallBackend = "iptables"
+)
+
+// DataDirOptions relocate the directories where `containerd` and `kubelet` store their data,
+// for example to a dedicated disk. `nodeadm init` warns when a directory is on the root
+// filesystem or its filesystem has little free space.
+type DataDirOptions struct {
+	// ContainerdRoot is the directory where `containerd` stores images and container snapshots.
+	// Defaults to `/var/lib/containerd`.
+	ContainerdRoot string `json:"containerdRoot,omitempty"`
+	// ContainerdState is the directory where `containerd` stores its transient state, such as
+	// sockets and mounts. Defaults to `/run/containerd`.
+	ContainerdState string `json:"containerdState,omitempty"`
+	// KubeletRoot is the `kubelet` root directory, passed as `--root-dir`, where pod volumes
+	// are stored. Defaults to `/var/lib/kubelet`.
+	KubeletRoot string `json:"kubeletRoot,omitempty"`
+	// PodLogs is the directory where container logs are stored, set as the `kubelet` `podLogsDir`.
+	// Requires Kubernetes 1.29 or later. Defaults to `/var/log/pods`.
+	PodLogs string `json:"podLogs,omitempty"`
 }
 
 // LocalStorageOptions control how [EC2 instance stores](https://docs.aws.amazon.com/AWSEC2/latest/UserGuide/InstanceStorage.html)
 // are used when available.
 type LocalStorageOptions struct {
 	Strategy LocalStorageStrategy `json:"strategy,omitempty"`
+	// Devices are the disks to set up instead of the EC2 instance stores, for hosts that don't
+	// have any, such as hybrid nodes. For example `/dev/nvme1n1` or `/dev/disk/by-id/...`.
+	Devices []string `json:"devices,omitempty"`
+	// OverwriteDevices lets the `RAID0` strategy create the array on disks that already have a
+	// filesystem, partition table or RAID signature, destroying their data.
+	OverwriteDevices bool `json:"overwriteDevices,omitempty"`
 }
 
 // LocalStorageStrategy specifies how to handle an instance's local storage devices.
@@ -104,6 +297,52 @@ const (
 	LocalStorageMount LocalStorageStrategy = "Mount"
 )
 
+// SwapOptions control how the node's swap is handled.
+type SwapOptions struct {
+	// Mode defaults to `disable`.
+	Mode SwapMode `json:"mode,omitempty"`
+}
+
+// SwapMode specifies how to handle the swap of the node.
+// +kubebuilder:validation:Enum={disable, limited, disable-partitions}
+type SwapMode string
+
+const (
+	// SwapModeDisable turns off file swap and removes it from `/etc/fstab`. Nodes with partition swap
+	// fail to initialize.
+	SwapModeDisable SwapMode = "disable"
+
+	// SwapModeLimited keeps swap on and lets Burstable pods use it with the `kubelet` `LimitedSwap`
+	// behavior. Requires Kubernetes 1.28 or later.
+	SwapModeLimited SwapMode = "limited"
+
+	// SwapModeDisablePartitions turns off all swap, including partitions, and masks the swap units so
+	// it stays off after a reboot.
+	SwapModeDisablePartitions SwapMode = "disable-partitions"
+)
+
+// ProxyOptions configure the HTTP proxy used by `nodeadm` and the daemons it manages
+// (`containerd`, `kubelet`, `amazon-ssm-agent` and `aws_signing_helper_update`).
+type ProxyOptions struct {
+	// HTTPProxy is the URL of the proxy used for HTTP requests.
+	HTTPProxy string `json:"httpProxy,omitempty"`
+
+	// HTTPSProxy is the URL of the proxy used for HTTPS requests.
+	HTTPSProxy string `json:"httpsProxy,omitempty"`
+
+	// NoProxy is a list of hosts, domains, IP addresses and CIDRs that are reached without the proxy.
+	// The cluster's service CIDR, API server host and remote pod networks are always included.
+	NoProxy []string `json:"noProxy,omitempty"`
+}
+
+// TrustOptions configure additional certificate authorities trusted by the node.
+type TrustOptions struct {
+	// AdditionalCABundles are PEM-encoded certificate authority bundles trusted in addition to the
+	// operating system's defaults, such as the CA of a TLS-inspecting proxy. They are used by `nodeadm`,
+	// installed in the operating system's trust store and used by `containerd` to pull images.
+	AdditionalCABundles []string `json:"additionalCABundles,omitempty"`
+}
+
 // HybridOptions defines the options specific to hybrid node enrollment.
 type HybridOptions struct {
 	// EnableCredentialsFile enables a shared credentials file on the host at /eks-hybrid/.aws/credentials
@@ -14,12 +14,16 @@ import (
 	"github.com/aws/eks-hybrid/internal/aws/sts"
 	"github.com/aws/eks-hybrid/internal/cli"
 	"github.com/aws/eks-hybrid/internal/configprovider"
+	"github.com/aws/eks-hybrid/internal/containerd"
 	"github.com/aws/eks-hybrid/internal/creds"
+	"github.com/aws/eks-hybrid/internal/cri"
 	"github.com/aws/eks-hybrid/internal/errors"
+	"github.com/aws/eks-hybrid/internal/iptables"
 	"github.com/aws/eks-hybrid/internal/kubelet"
 	"github.com/aws/eks-hybrid/internal/kubernetes"
 	"github.com/aws/eks-hybrid/internal/logger"
 	"github.com/aws/eks-hybrid/internal/node"
+	"github.com/aws/eks-hybrid/internal/system"
 	"github.com/aws/eks-hybrid/internal/validation"
 )
 
@@ -102,6 +106,14 @@ func (c *debug) Run(log *zap.Logger, opts *cli.GlobalOptions) error {
 			validation.New("k8s-vpc-network", apiServerValidator.CheckVPCEndpointAccess),
 		),
 	)
+	if nodeConfig.IsHybridNode() {
+		runner.Register(validation.New("pod-network", node.NewPodNetworkValidator(awsConfig, kubelet.New()).Run))
+	}
+	runner.Register(validation.New("iptables-backend", iptables.ValidateBackend))
+	runner.Register(system.CNIValidations(nodeConfig)...)
+	if cri.InstalledRuntime() == cri.Containerd {
+		runner.Register(validation.New("containerd-runtime-handlers", containerd.ValidateRuntimeHandlers))
+	}
 
 	if err := runner.Sequentially(ctx, nodeConfig); err != nil {
 		fmt.Println("")
@@ -11,6 +11,8 @@ import (
 
 	"github.com/aws/eks-hybrid/internal/cli"
 	"github.com/aws/eks-hybrid/internal/containerd"
+	"github.com/aws/eks-hybrid/internal/cri"
+	"github.com/aws/eks-hybrid/internal/crio"
 	"github.com/aws/eks-hybrid/internal/flows"
 	"github.com/aws/eks-hybrid/internal/logger"
 	"github.com/aws/eks-hybrid/internal/node"
@@ -21,9 +23,6 @@ import (
 const (
 	installValidation      = "install-validation"
 	cniPortCheckValidation = "cni-validation"
-	calicoVxLanPort        = "4789"
-	ciliumVxLanPort        = "8472"
-	vxLanProtocol          = "udp"
 )
 
 const initHelpText = `Examples:
@@ -39,6 +38,7 @@ func NewInitCommand() cli.Command {
 	init.cmd.String(&init.configSource, "c", "config-source", "Source of node configuration. The format is a URI with supported schemes: [file, imds].")
 	init.cmd.StringSlice(&init.daemons, "d", "daemon", "Specify one or more of `containerd` and `kubelet`. This is intended for testing and should not be used in a production environment.")
 	init.cmd.StringSlice(&init.skipPhases, "s", "skip", "Phases of the bootstrap to skip. Allowed values: [install-validation, cni-validation, node-ip-validation, kubelet-cert-validation, preprocess, config, run].")
+	init.cmd.Bool(&init.force, "f", "force", "Join the cluster even when a node with the same name belongs to a different machine. The node takes over the existing Node object.")
 	init.cmd.Description = "Initialize this instance as a node in an EKS cluster"
 	init.cmd.AdditionalHelpAppend = initHelpText
 	return &init
@@ -49,6 +49,7 @@ type initCmd struct {
 	configSource string
 	skipPhases   []string
 	daemons      []string
+	force        bool
 }
 
 func (c *initCmd) Flaggy() *flaggy.Subcommand {
@@ -74,7 +75,7 @@ func (c *initCmd) Run(log *zap.Logger, opts *cli.GlobalOptions) error {
 
 	if !slices.Contains(c.skipPhases, installValidation) {
 		log.Info("Loading installed components")
-		_, err = tracker.GetInstalledArtifacts()
+		installed, err := tracker.GetInstalledArtifacts()
 		if err != nil && os.IsNotExist(err) {
 			log.Info("Nodeadm components are not installed. Please run `nodeadm install` before running init")
 			return nil
@@ -82,56 +83,39 @@ func (c *initCmd) Run(log *zap.Logger, opts *cli.GlobalOptions) error {
 			return err
 		}
 
-		if err := containerd.ValidateSystemdUnitFile(); err != nil {
+		if cri.FromTracker(installed.Artifacts) == cri.Crio {
+			if err := crio.ValidateSystemdUnitFile(); err != nil {
+				return fmt.Errorf("a systemd unit file for cri-o is required to init the node: %w", err)
+			}
+		} else if err := containerd.ValidateSystemdUnitFile(); err != nil {
 			return fmt.Errorf("a systemd unit file for containerd is required to init the node: %w", err)
 		}
 	}
 
-	// Check if either of cilium or calico vxlan port are open
-	if !slices.Contains(c.skipPhases, cniPortCheckValidation) {
-		log.Info("Validating firewall ports for cilium and calico")
-		if err := validateFirewallOpenPorts(); err != nil {
-			return fmt.Errorf("Cilium (%s/%s) or Calico (%s/%s) VxLan ports are not open on the host. If you are not using VxLan, this validation can by bypassed with --skip %s",
-				ciliumVxLanPort, vxLanProtocol, calicoVxLanPort, vxLanProtocol, cniPortCheckValidation)
-		}
-	}
 	nodeProvider, err := node.NewNodeProvider(c.configSource, c.skipPhases, log)
 	if err != nil {
 		return err
 	}
 
+	// Without spec.cni, check if either of cilium or calico vxlan port are open
+	if !slices.Contains(c.skipPhases, cniPortCheckValidation) {
+		log.Info("Validating firewall ports for the CNI")
+		nodeConfig := nodeProvider.GetNodeConfig()
+		firewallManager, err := system.NewFirewallManager(nodeConfig.Spec.Instance.Firewall.Backend)
+		if err != nil {
+			return err
+		}
+		if err := system.ValidateCNIPorts(nodeConfig, firewallManager); err != nil {
+			return fmt.Errorf("%w. Set spec.cni to have nodeadm open the CNI ports, or bypass this validation with --skip %s", err, cniPortCheckValidation)
+		}
+	}
+
 	initer := &flows.Initer{
 		NodeProvider: nodeProvider,
 		SkipPhases:   c.skipPhases,
+		Force:        c.force,
 		Logger:       log,
 	}
 
 	return initer.Run(ctx)
 }
-
-func validateFirewallOpenPorts() error {
-	firewallManager := system.NewFirewallManager()
-	enabled, err := firewallManager.IsEnabled()
-	if err != nil {
-		return err
-	}
-	if !enabled {
-		return nil
-	}
-	if err := firewallManager.FlushRules(); err != nil {
-		return err
-	}
-	ciliumVxlanPortOpen, err := firewallManager.IsPortOpen(ciliumVxLanPort, vxLanProtocol)
-	if err != nil {
-		return err
-	}
-	calicoVxlanPortOpen, err := firewallManager.IsPortOpen(calicoVxLanPort, vxLanProtocol)
-	if err != nil {
-		return err
-	}
-
-	if !ciliumVxlanPortOpen && !calicoVxlanPortOpen {
-		return fmt.Errorf("both cilium and calico vxlan ports are closed")
-	}
-	return nil
-}
@@ -2,21 +2,37 @@ package install
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"strings"
 	"time"
 
+	sdk "github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/integrii/flaggy"
 	"go.uber.org/zap"
+	"k8s.io/utils/strings/slices"
 
+	"github.com/aws/eks-hybrid/internal/api"
+	"github.com/aws/eks-hybrid/internal/artifact"
 	"github.com/aws/eks-hybrid/internal/aws"
 	"github.com/aws/eks-hybrid/internal/cli"
+	"github.com/aws/eks-hybrid/internal/configprovider"
 	"github.com/aws/eks-hybrid/internal/containerd"
 	"github.com/aws/eks-hybrid/internal/creds"
+	"github.com/aws/eks-hybrid/internal/cri"
 	"github.com/aws/eks-hybrid/internal/flows"
+	"github.com/aws/eks-hybrid/internal/gvisor"
+	"github.com/aws/eks-hybrid/internal/kubelet"
 	"github.com/aws/eks-hybrid/internal/logger"
+	"github.com/aws/eks-hybrid/internal/node"
 	"github.com/aws/eks-hybrid/internal/packagemanager"
+	"github.com/aws/eks-hybrid/internal/proxy"
 	"github.com/aws/eks-hybrid/internal/ssm"
+	"github.com/aws/eks-hybrid/internal/trust"
 )
 
+const versionSkewValidation = "version-skew-validation"
+
 const installHelpText = `Examples:
   # Install Kubernetes version 1.31 with AWS Systems Manager (SSM) as the credential provider
   nodeadm install 1.31 --credential-provider ssm
@@ -24,6 +40,31 @@ const installHelpText = `Examples:
   # Install Kubernetes version 1.31 with AWS IAM Roles Anywhere as the credential provider and Docker as the containerd source
   nodeadm install 1.31 --credential-provider iam-ra --containerd-source docker
 
+  # Install Kubernetes version 1.32 with containerd 2.x from the Docker repos
+  nodeadm install 1.32 --credential-provider ssm --containerd-source docker --containerd-version 2
+
+  # Install Kubernetes version 1.31 with CRI-O as the container runtime
+  nodeadm install 1.31 --credential-provider ssm --runtime crio
+
+  # Install Kubernetes version 1.31 without kubectl and cni-plugins
+  nodeadm install 1.31 --credential-provider ssm --exclude kubectl,cniPlugins
+
+  # Install Kubernetes version 1.31 with crictl
+  nodeadm install 1.31 --credential-provider ssm --with crictl
+
+  # Install Kubernetes version 1.31 with the gVisor runtime from a mirror of its releases
+  nodeadm install 1.31 --credential-provider ssm --with gvisor --gvisor-source https://mirror.example.com/gvisor/release/latest
+
+  # Install Kubernetes version 1.31 validating the version skew against the cluster from the node configuration
+  nodeadm install 1.31 --credential-provider ssm --config-source file://nodeConfig.yaml
+
+  # Install Kubernetes version 1.31 validating the version skew against a 1.32 cluster
+  nodeadm install 1.31 --credential-provider ssm --cluster-version 1.32
+
+The kubelet version skew is validated against --cluster-version when set, otherwise against the cluster
+version from the API server when the node already has a kubelet kubeconfig, or from EKS with
+--config-source. Without any of them the validation is skipped with a warning.
+
 Documentation:
   https://docs.aws.amazon.com/eks/latest/userguide/hybrid-nodes-nodeadm.html#_install`
 
@@ -32,6 +73,7 @@ func NewCommand() cli.Command {
 		timeout: 20 * time.Minute,
 	}
 	cmd.region = ssm.DefaultSsmInstallerRegion
+	cmd.gvisorSource = gvisor.DefaultSource
 
 	fc := flaggy.NewSubcommand("install")
 	fc.Description = "Install components required to join an EKS cluster"
@@ -39,8 +81,17 @@ func NewCommand() cli.Command {
 	fc.AddPositionalValue(&cmd.kubernetesVersion, "KUBERNETES_VERSION", 1, true, "The major[.minor[.patch]] version of Kubernetes to install.")
 	fc.String(&cmd.credentialProvider, "p", "credential-provider", "Credential process to install. Allowed values: [ssm, iam-ra].")
 	fc.String(&cmd.containerdSource, "s", "containerd-source", "Source for containerd artifact. Allowed values: [none, distro, docker].")
+	fc.String(&cmd.containerdVersion, "", "containerd-version", "Major version of containerd to install. Allowed values: ["+strings.Join(containerd.SupportedMajorVersions, ", ")+"].")
+	fc.String(&cmd.runtime, "", "runtime", "Container runtime to install and configure. CRI-O is installed from the package repos configured on the host, the containerd flags are ignored. Allowed values: ["+strings.Join(cri.SupportedRuntimes, ", ")+"].")
+	fc.String(&cmd.gvisorSource, "", "gvisor-source", "URL of the gVisor release installed with --with gvisor. Defaults to "+gvisor.DefaultSource+".")
 	fc.String(&cmd.region, "r", "region", "AWS region for downloading regional artifacts.")
 	fc.Duration(&cmd.timeout, "t", "timeout", "Maximum install command duration. Input follows duration format. Example: 1h23s")
+	fc.String(&cmd.configSource, "c", "config-source", "Optional source of node configuration, used to look up the cluster version for version skew validation and to configure the HTTP proxy and additional CA bundles. The format is a URI with supported schemes: [file, imds].")
+	fc.String(&cmd.clusterVersion, "", "cluster-version", "Optional Kubernetes version of the cluster control plane, used for version skew validation without --config-source.")
+	fc.StringSlice(&cmd.skipPhases, "", "skip", "Phases of the install to skip. Allowed values: [version-skew-validation].")
+	fc.StringSlice(&cmd.components, "", "components", "Components to install. Defaults to all non-optional components. Allowed values: ["+strings.Join(artifact.All(), ", ")+"].")
+	fc.StringSlice(&cmd.exclude, "", "exclude", "Components to exclude from the install. Allowed values: ["+strings.Join(artifact.All(), ", ")+"].")
+	fc.StringSlice(&cmd.with, "", "with", "Optional components to install in addition to the selected ones. Allowed values: ["+strings.Join(artifact.Optional, ", ")+"].")
 	cmd.flaggy = fc
 
 	return &cmd
@@ -51,8 +102,17 @@ type command struct {
 	kubernetesVersion  string
 	credentialProvider string
 	containerdSource   string
+	containerdVersion  string
+	runtime            string
+	gvisorSource       string
 	region             string
 	timeout            time.Duration
+	configSource       string
+	clusterVersion     string
+	skipPhases         []string
+	components         []string
+	exclude            []string
+	with               []string
 }
 
 func (c *command) Flaggy() *flaggy.Subcommand {
@@ -79,14 +139,43 @@ func (c *command) Run(log *zap.Logger, opts *cli.GlobalOptions) error {
 		return err
 	}
 
-	// Default containerd source to distro
-	if c.containerdSource == "" {
-		c.containerdSource = string(containerd.ContainerdSourceDistro)
+	components, err := artifact.NewSelection(c.components, c.exclude)
+	if err != nil {
+		return err
 	}
-	containerdSource := containerd.GetContainerdSource(c.containerdSource)
-	if err := containerd.ValidateContainerdSource(containerdSource); err != nil {
+	if err := components.AddOptional(c.with); err != nil {
 		return err
 	}
+	if err := credentialProvider.ValidateSelection(components); err != nil {
+		return err
+	}
+
+	if c.runtime == "" {
+		c.runtime = string(cri.DefaultRuntime)
+	}
+	if err := cri.ValidateRuntime(c.runtime); err != nil {
+		return err
+	}
+
+	// containerd is neither installed nor configured when CRI-O is the runtime
+	containerdSource := containerd.ContainerdSourceNone
+	if cri.Runtime(c.runtime) == cri.Containerd {
+		// Default containerd source to distro
+		if c.containerdSource == "" {
+			c.containerdSource = string(containerd.ContainerdSourceDistro)
+		}
+		containerdSource = containerd.GetContainerdSource(c.containerdSource)
+		if err := containerd.ValidateContainerdSource(containerdSource); err != nil {
+			return err
+		}
+		// without --containerd-version, containerd already on the host is
+		// kept whatever its version
+		if c.containerdVersion != "" {
+			if err := containerd.ValidateMajorVersion(c.containerdVersion); err != nil {
+				return err
+			}
+		}
+	}
 
 	log.Info("Creating package manager...")
 	packageManager, err := packagemanager.New(containerdSource, log)
@@ -94,6 +183,25 @@ func (c *command) Run(log *zap.Logger, opts *cli.GlobalOptions) error {
 		return err
 	}
 
+	var nodeConfig *api.NodeConfig
+	if c.configSource != "" {
+		log.Info("Loading configuration..", zap.String("configSource", c.configSource))
+		provider, err := configprovider.BuildConfigProvider(c.configSource)
+		if err != nil {
+			return err
+		}
+		nodeConfig, err = provider.Provide()
+		if err != nil {
+			return err
+		}
+		if err := trust.Configure(nodeConfig); err != nil {
+			return err
+		}
+		if err := proxy.Configure(nodeConfig); err != nil {
+			return err
+		}
+	}
+
 	ctx, cancel := context.WithTimeout(ctx, c.timeout)
 	defer cancel()
 
@@ -105,14 +213,71 @@ func (c *command) Run(log *zap.Logger, opts *cli.GlobalOptions) error {
 	}
 	log.Info("Using Kubernetes version", zap.Reflect("kubernetes version", awsSource.Eks.Version))
 
+	if !slices.Contains(c.skipPhases, versionSkewValidation) {
+		log.Info("Validating Kubernetes version skew...")
+		if err := validateVersionSkew(ctx, nodeConfig, c.clusterVersion, awsSource.Eks.Version); err != nil {
+			return fmt.Errorf("%w. This validation can be bypassed with --skip %s", err, versionSkewValidation)
+		}
+	}
+
 	installer := &flows.Installer{
 		AwsSource:          awsSource,
 		PackageManager:     packageManager,
 		ContainerdSource:   containerdSource,
+		ContainerdVersion:  c.containerdVersion,
+		Runtime:            cri.Runtime(c.runtime),
+		GvisorSource:       c.gvisorSource,
 		SsmRegion:          c.region,
 		CredentialProvider: credentialProvider,
+		Components:         components,
 		Logger:             log,
 	}
 
 	return installer.Run(ctx)
 }
+
+// validateVersionSkew validates the kubelet version to install against the control plane
+// version. The control plane version is clusterVersion if set, otherwise it's read from the
+// API server if the node already has a kubelet kubeconfig, or from EKS if a node config is
+// provided and the node AWS config can be loaded. If none is available the validation is
+// skipped.
+func validateVersionSkew(ctx context.Context, nodeConfig *api.NodeConfig, clusterVersion, targetVersion string) error {
+	if clusterVersion != "" {
+		return node.ValidateKubeletVersionSkew(clusterVersion, targetVersion, "")
+	}
+
+	var cluster *api.ClusterDetails
+	var awsConfig *sdk.Config
+	if nodeConfig != nil {
+		cluster = &nodeConfig.Spec.Cluster
+		awsConfig = loadNodeAwsConfig(ctx, nodeConfig)
+	}
+
+	controlPlaneVersion, err := node.ControlPlaneVersion(ctx, kubelet.New(), cluster, awsConfig)
+	if errors.Is(err, node.ErrControlPlaneVersionUnknown) {
+		logger.FromContext(ctx).Warn("Cluster version is unknown, the kubelet version skew was not validated. " +
+			"Pass --config-source with the node configuration or --cluster-version to validate it")
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("getting control plane version: %w", err)
+	}
+
+	return node.ValidateKubeletVersionSkew(controlPlaneVersion, targetVersion, "")
+}
+
+// loadNodeAwsConfig returns the AWS config of the node provider, or nil when
+// it can't be loaded, like on hybrid nodes that aren't inited yet.
+func loadNodeAwsConfig(ctx context.Context, nodeConfig *api.NodeConfig) *sdk.Config {
+	log := logger.FromContext(ctx)
+	nodeProvider, err := node.NewNodeProviderFromConfig(nodeConfig, nil, log)
+	if err != nil {
+		log.Info("Can't load the node AWS config to describe the cluster", zap.Error(err))
+		return nil
+	}
+	defer nodeProvider.Cleanup()
+	if err := nodeProvider.LoadAws(ctx); err != nil {
+		log.Info("Can't load the node AWS config to describe the cluster", zap.Error(err))
+		return nil
+	}
+	return nodeProvider.GetConfig()
+}
@@ -10,6 +10,8 @@ import (
 	"github.com/aws/eks-hybrid/cmd/nodeadm/debug"
 	initcmd "github.com/aws/eks-hybrid/cmd/nodeadm/init"
 	"github.com/aws/eks-hybrid/cmd/nodeadm/install"
+	"github.com/aws/eks-hybrid/cmd/nodeadm/reconfigure"
+	"github.com/aws/eks-hybrid/cmd/nodeadm/selfupdate"
 	"github.com/aws/eks-hybrid/cmd/nodeadm/uninstall"
 	"github.com/aws/eks-hybrid/cmd/nodeadm/upgrade"
 	"github.com/aws/eks-hybrid/cmd/nodeadm/version"
@@ -36,6 +38,8 @@ func main() {
 		install.NewCommand(),
 		uninstall.NewCommand(),
 		upgrade.NewUpgradeCommand(),
+		reconfigure.NewCommand(),
+		selfupdate.NewCommand(),
 		debug.NewCommand(),
 	}
 
@@ -0,0 +1,78 @@
+package reconfigure
+
+import (
+	"context"
+	"os"
+
+	"github.com/integrii/flaggy"
+	"go.uber.org/zap"
+
+	"github.com/aws/eks-hybrid/internal/cli"
+	"github.com/aws/eks-hybrid/internal/flows"
+	"github.com/aws/eks-hybrid/internal/logger"
+	"github.com/aws/eks-hybrid/internal/node"
+)
+
+const reconfigureHelpText = `Examples:
+  # Apply an updated configuration, restarting only the daemons whose configuration changed
+  nodeadm reconfigure --config-source file://nodeConfig.yaml
+
+  # Preview the configuration changes without applying them
+  nodeadm reconfigure --config-source file://nodeConfig.yaml --diff
+
+Documentation:
+  https://docs.aws.amazon.com/eks/latest/userguide/hybrid-nodes-nodeadm.html`
+
+func NewCommand() cli.Command {
+	cmd := command{}
+	cmd.flaggy = flaggy.NewSubcommand("reconfigure")
+	cmd.flaggy.String(&cmd.configSource, "c", "config-source", "Source of node configuration. The format is a URI with supported schemes: [file, imds].")
+	cmd.flaggy.Bool(&cmd.diff, "", "diff", "Print the differences between the rendered and the current configuration files without applying them.")
+	cmd.flaggy.StringSlice(&cmd.skipPhases, "s", "skip", "Validations to skip. Allowed values: [node-ip-validation, kubelet-cert-validation].")
+	cmd.flaggy.Description = "Re-render the configuration of an initialized node and restart only the daemons whose configuration changed"
+	cmd.flaggy.AdditionalHelpAppend = reconfigureHelpText
+	return &cmd
+}
+
+type command struct {
+	flaggy       *flaggy.Subcommand
+	configSource string
+	diff         bool
+	skipPhases   []string
+}
+
+func (c *command) Flaggy() *flaggy.Subcommand {
+	return c.flaggy
+}
+
+func (c *command) Run(log *zap.Logger, opts *cli.GlobalOptions) error {
+	ctx := context.Background()
+	ctx = logger.NewContext(ctx, log)
+
+	log.Info("Checking user is root..")
+	root, err := cli.IsRunningAsRoot()
+	if err != nil {
+		return err
+	} else if !root {
+		return cli.ErrMustRunAsRoot
+	}
+
+	if c.configSource == "" {
+		flaggy.ShowHelpAndExit("--config-source is a required flag. The format is a URI with supported schemes: [file, imds]." +
+			" For example on hybrid nodes --config-source file://nodeConfig.yaml")
+	}
+
+	nodeProvider, err := node.NewNodeProvider(c.configSource, c.skipPhases, log)
+	if err != nil {
+		return err
+	}
+
+	reconfigurer := &flows.Reconfigurer{
+		NodeProvider: nodeProvider,
+		DryRun:       c.diff,
+		Output:       os.Stdout,
+		Logger:       log,
+	}
+
+	return reconfigurer.Run(ctx)
+}
@@ -0,0 +1,128 @@
+package selfupdate
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/integrii/flaggy"
+	"go.uber.org/zap"
+
+	"github.com/aws/eks-hybrid/cmd/nodeadm/version"
+	"github.com/aws/eks-hybrid/internal/aws"
+	"github.com/aws/eks-hybrid/internal/cli"
+	"github.com/aws/eks-hybrid/internal/logger"
+	"github.com/aws/eks-hybrid/internal/selfupdate"
+)
+
+const selfUpdateHelpText = `Examples:
+  # Update nodeadm to the latest release
+  nodeadm self-update
+
+  # Update nodeadm to a specific release
+  nodeadm self-update v1.0.6
+
+  # Check whether a newer nodeadm release is available
+  nodeadm self-update --check
+
+  # Update nodeadm from a mirror of the release artifacts
+  nodeadm self-update --mirror https://mirror.example.com/eks-hybrid
+
+Documentation:
+  https://docs.aws.amazon.com/eks/latest/userguide/hybrid-nodes-nodeadm.html`
+
+func NewCommand() cli.Command {
+	cmd := command{
+		timeout: 10 * time.Minute,
+	}
+
+	fc := flaggy.NewSubcommand("self-update")
+	fc.Description = "Update the nodeadm executable to the latest or a specific release"
+	fc.AdditionalHelpAppend = selfUpdateHelpText
+	fc.AddPositionalValue(&cmd.version, "VERSION", 1, false, "The nodeadm version to install. Defaults to the latest release.")
+	fc.Bool(&cmd.check, "", "check", "Only report whether an update is available, without installing it.")
+	fc.String(&cmd.mirror, "m", "mirror", "Base URL of a mirror of the release manifest and artifacts.")
+	fc.Duration(&cmd.timeout, "t", "timeout", "Maximum self-update command duration. Input follows duration format. Example: 1h23s")
+	cmd.flaggy = fc
+
+	return &cmd
+}
+
+type command struct {
+	flaggy  *flaggy.Subcommand
+	version string
+	check   bool
+	mirror  string
+	timeout time.Duration
+}
+
+func (c *command) Flaggy() *flaggy.Subcommand {
+	return c.flaggy
+}
+
+func (c *command) Run(log *zap.Logger, opts *cli.GlobalOptions) error {
+	ctx := context.Background()
+	ctx = logger.NewContext(ctx, log)
+
+	if !c.check {
+		root, err := cli.IsRunningAsRoot()
+		if err != nil {
+			return err
+		}
+		if !root {
+			return cli.ErrMustRunAsRoot
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	log.Info("Looking up nodeadm release...", zap.String("version", c.version))
+	source, err := aws.GetNodeadmSource(ctx, c.version, c.mirror)
+	if err != nil {
+		return err
+	}
+
+	currentVersion := version.GitVersion
+	available := selfupdate.IsUpdateAvailable(currentVersion, source.Release.Version, c.version != "")
+	if c.check {
+		if available {
+			fmt.Printf("nodeadm %s is available (current version %s)\n", source.Release.Version, currentVersion)
+		} else {
+			fmt.Printf("nodeadm %s is up to date\n", currentVersion)
+		}
+		return nil
+	}
+	if !available {
+		log.Info("nodeadm is up to date", zap.String("version", currentVersion))
+		return nil
+	}
+
+	publicKey, err := selfupdate.PublicKey()
+	if err != nil {
+		return err
+	}
+
+	executable, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("finding nodeadm executable: %w", err)
+	}
+	executable, err = filepath.EvalSymlinks(executable)
+	if err != nil {
+		return fmt.Errorf("resolving nodeadm executable: %w", err)
+	}
+
+	log.Info("Updating nodeadm", zap.String("from", currentVersion), zap.String("to", source.Release.Version))
+	if err := selfupdate.Replace(ctx, selfupdate.ReplaceOptions{
+		Source:         source,
+		ExecutablePath: executable,
+		PublicKey:      publicKey,
+		Logger:         log,
+	}); err != nil {
+		return err
+	}
+	log.Info("Updated nodeadm", zap.String("version", source.Release.Version))
+	return nil
+}
@@ -19,6 +19,7 @@ import (
 	"github.com/aws/eks-hybrid/internal/logger"
 	"github.com/aws/eks-hybrid/internal/node"
 	"github.com/aws/eks-hybrid/internal/packagemanager"
+	"github.com/aws/eks-hybrid/internal/system"
 	"github.com/aws/eks-hybrid/internal/tracker"
 )
 
@@ -126,6 +127,7 @@ func (c *command) Run(log *zap.Logger, opts *cli.GlobalOptions) error {
 		PackageManager: packageManager,
 		Logger:         log,
 		CNIUninstall:   cni.Uninstall,
+		Aspects:        system.AllAspects(log),
 	}
 
 	if err := uninstaller.Run(ctx); err != nil {
@@ -134,7 +136,7 @@ func (c *command) Run(log *zap.Logger, opts *cli.GlobalOptions) error {
 
 	if c.force {
 		log.Info("Force mode enabled, cleaning up additional directories...")
-		cleanupManager := cleanup.New(log)
+		cleanupManager := cleanup.New(log, cleanup.WithDataDirs(installed.DataDirs...))
 		if err := cleanupManager.Cleanup(); err != nil {
 			return fmt.Errorf("cleaning up additional directories: %w", err)
 		}
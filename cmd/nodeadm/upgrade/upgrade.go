@@ -4,12 +4,14 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/integrii/flaggy"
 	"go.uber.org/zap"
 	"k8s.io/utils/strings/slices"
 
+	"github.com/aws/eks-hybrid/internal/artifact"
 	"github.com/aws/eks-hybrid/internal/aws"
 	"github.com/aws/eks-hybrid/internal/cli"
 	"github.com/aws/eks-hybrid/internal/containerd"
@@ -19,6 +21,7 @@ import (
 	"github.com/aws/eks-hybrid/internal/kubelet"
 	"github.com/aws/eks-hybrid/internal/logger"
 	"github.com/aws/eks-hybrid/internal/node"
+	"github.com/aws/eks-hybrid/internal/nodeprovider"
 	"github.com/aws/eks-hybrid/internal/packagemanager"
 	"github.com/aws/eks-hybrid/internal/tracker"
 )
@@ -27,6 +30,7 @@ const (
 	skipPodPreflightCheck  = "pod-validation"
 	skipNodePreflightCheck = "node-validation"
 	initNodePreflightCheck = "init-validation"
+	versionSkewValidation  = "version-skew-validation"
 )
 
 const upgradeHelpText = `Examples:
@@ -36,6 +40,12 @@ const upgradeHelpText = `Examples:
   # Upgrade all components with a custom timeout
   nodeadm upgrade 1.31 --config-source file:///root/nodeConfig.yaml --timeout 1h23s
 
+  # Upgrade all components except kubectl
+  nodeadm upgrade 1.31 --config-source file:///root/nodeConfig.yaml --exclude kubectl
+
+  # Upgrade all components without enforcing the kubelet version skew policy
+  nodeadm upgrade 1.31 --config-source file:///root/nodeConfig.yaml --skip version-skew-validation
+
 Documentation:
   https://docs.aws.amazon.com/eks/latest/userguide/hybrid-nodes-nodeadm.html#_upgrade`
 
@@ -49,8 +59,11 @@ func NewUpgradeCommand() cli.Command {
 	fc.AdditionalHelpAppend = upgradeHelpText
 	fc.AddPositionalValue(&cmd.kubernetesVersion, "KUBERNETES_VERSION", 1, true, "The major[.minor[.patch]] version of Kubernetes to install.")
 	fc.String(&cmd.configSource, "c", "config-source", "Source of node configuration. The format is a URI with supported schemes: [file, imds].")
-	fc.StringSlice(&cmd.skipPhases, "s", "skip", "Phases of the upgrade to skip. Allowed values: [init-validation, pod-validation, node-validation, node-ip-validation].")
+	fc.StringSlice(&cmd.skipPhases, "s", "skip", "Phases of the upgrade to skip. Allowed values: [init-validation, pod-validation, node-validation, node-ip-validation, version-skew-validation].")
 	fc.Duration(&cmd.timeout, "t", "timeout", "Maximum upgrade command duration. Input follows duration format. Example: 1h23s")
+	fc.StringSlice(&cmd.components, "", "components", "Installed components to upgrade. Defaults to all installed components. Allowed values: ["+strings.Join(artifact.All(), ", ")+"].")
+	fc.StringSlice(&cmd.exclude, "", "exclude", "Installed components to exclude from the upgrade. Allowed values: ["+strings.Join(artifact.All(), ", ")+"].")
+	fc.Bool(&cmd.force, "f", "force", "Upgrade even when a node with the same name belongs to a different machine. The node takes over the existing Node object.")
 	cmd.flaggy = fc
 	return &cmd
 }
@@ -61,6 +74,9 @@ type command struct {
 	skipPhases        []string
 	kubernetesVersion string
 	timeout           time.Duration
+	components        []string
+	exclude           []string
+	force             bool
 }
 
 func (c *command) Flaggy() *flaggy.Subcommand {
@@ -129,6 +145,18 @@ func (c *command) Run(log *zap.Logger, opts *cli.GlobalOptions) error {
 		return fmt.Errorf("upgrade does not support changing credential providers. Please uninstall and install with new credential provider")
 	}
 
+	// upgrade every installed component by default, including the optional ones
+	selected := c.components
+	if len(selected) == 0 {
+		selected = artifact.All()
+	}
+	// the credential provider artifacts can be left out, they stay installed
+	// and keep working at their current version
+	components, err := artifact.NewSelection(selected, c.exclude)
+	if err != nil {
+		return err
+	}
+
 	log.Info("Validating Kubernetes version", zap.Reflect("kubernetes version", c.kubernetesVersion))
 	// Create a Source for all AWS managed artifacts.
 	awsSource, err := aws.GetLatestSource(ctx, c.kubernetesVersion)
@@ -137,6 +165,13 @@ func (c *command) Run(log *zap.Logger, opts *cli.GlobalOptions) error {
 	}
 	log.Info("Using Kubernetes version", zap.Reflect("kubernetes version", awsSource.Eks.Version))
 
+	if !slices.Contains(c.skipPhases, versionSkewValidation) {
+		log.Info("Validating Kubernetes version skew...")
+		if err := validateVersionSkew(ctx, nodeProvider, awsSource.Eks.Version, installed.Artifacts.Kubelet); err != nil {
+			return fmt.Errorf("%w. This validation can be bypassed with --skip %s", err, versionSkewValidation)
+		}
+	}
+
 	log.Info("Creating daemon manager..")
 	daemonManager, err := daemon.NewDaemonManager()
 	if err != nil {
@@ -182,10 +217,32 @@ func (c *command) Run(log *zap.Logger, opts *cli.GlobalOptions) error {
 		PackageManager:     packageManager,
 		CredentialProvider: credsProvider,
 		Artifacts:          installed.Artifacts,
+		Components:         components,
 		DaemonManager:      daemonManager,
 		SkipPhases:         c.skipPhases,
+		Force:              c.force,
 		Logger:             log,
 	}
 
 	return upgrader.Run(ctx)
 }
+
+func validateVersionSkew(ctx context.Context, nodeProvider nodeprovider.NodeProvider, targetVersion string, kubeletInstalled bool) error {
+	// without the node credentials the version can still be read from the API server
+	if err := nodeProvider.LoadAws(ctx); err != nil {
+		logger.FromContext(ctx).Warn("Can't load the node AWS config to describe the cluster", zap.Error(err))
+	}
+	controlPlaneVersion, err := node.ControlPlaneVersion(ctx, kubelet.New(), &nodeProvider.GetNodeConfig().Spec.Cluster, nodeProvider.GetConfig())
+	if err != nil {
+		return fmt.Errorf("getting control plane version: %w", err)
+	}
+
+	var currentVersion string
+	if kubeletInstalled {
+		if currentVersion, err = kubelet.GetKubeletVersion(); err != nil {
+			return fmt.Errorf("getting installed kubelet version: %w", err)
+		}
+	}
+
+	return node.ValidateKubeletVersionSkew(controlPlaneVersion, targetVersion, currentVersion)
+}
@@ -21,6 +21,16 @@ func init() {
 // RegisterConversions adds conversion functions to the given scheme.
 // Public to allow building arbitrary schemes.
 func RegisterConversions(s *runtime.Scheme) error {
+	if err := s.AddGeneratedConversionFunc((*v1alpha1.CNIOptions)(nil), (*api.CNIOptions)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha1_CNIOptions_To_api_CNIOptions(a.(*v1alpha1.CNIOptions), b.(*api.CNIOptions), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*api.CNIOptions)(nil), (*v1alpha1.CNIOptions)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_api_CNIOptions_To_v1alpha1_CNIOptions(a.(*api.CNIOptions), b.(*v1alpha1.CNIOptions), scope)
+	}); err != nil {
+		return err
+	}
 	if err := s.AddGeneratedConversionFunc((*v1alpha1.ClusterDetails)(nil), (*api.ClusterDetails)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1alpha1_ClusterDetails_To_api_ClusterDetails(a.(*v1alpha1.ClusterDetails), b.(*api.ClusterDetails), scope)
 	}); err != nil {
@@ -41,6 +51,36 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
+	if err := s.AddGeneratedConversionFunc((*v1alpha1.ContainerdRuntime)(nil), (*api.ContainerdRuntime)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha1_ContainerdRuntime_To_api_ContainerdRuntime(a.(*v1alpha1.ContainerdRuntime), b.(*api.ContainerdRuntime), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*api.ContainerdRuntime)(nil), (*v1alpha1.ContainerdRuntime)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_api_ContainerdRuntime_To_v1alpha1_ContainerdRuntime(a.(*api.ContainerdRuntime), b.(*v1alpha1.ContainerdRuntime), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*v1alpha1.DataDirOptions)(nil), (*api.DataDirOptions)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha1_DataDirOptions_To_api_DataDirOptions(a.(*v1alpha1.DataDirOptions), b.(*api.DataDirOptions), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*api.DataDirOptions)(nil), (*v1alpha1.DataDirOptions)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_api_DataDirOptions_To_v1alpha1_DataDirOptions(a.(*api.DataDirOptions), b.(*v1alpha1.DataDirOptions), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*v1alpha1.FirewallOptions)(nil), (*api.FirewallOptions)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha1_FirewallOptions_To_api_FirewallOptions(a.(*v1alpha1.FirewallOptions), b.(*api.FirewallOptions), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*api.FirewallOptions)(nil), (*v1alpha1.FirewallOptions)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_api_FirewallOptions_To_v1alpha1_FirewallOptions(a.(*api.FirewallOptions), b.(*v1alpha1.FirewallOptions), scope)
+	}); err != nil {
+		return err
+	}
 	if err := s.AddGeneratedConversionFunc((*v1alpha1.HybridOptions)(nil), (*api.HybridOptions)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1alpha1_HybridOptions_To_api_HybridOptions(a.(*v1alpha1.HybridOptions), b.(*api.HybridOptions), scope)
 	}); err != nil {
@@ -121,6 +161,46 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
+	if err := s.AddGeneratedConversionFunc((*v1alpha1.ProxyOptions)(nil), (*api.ProxyOptions)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha1_ProxyOptions_To_api_ProxyOptions(a.(*v1alpha1.ProxyOptions), b.(*api.ProxyOptions), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*api.ProxyOptions)(nil), (*v1alpha1.ProxyOptions)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_api_ProxyOptions_To_v1alpha1_ProxyOptions(a.(*api.ProxyOptions), b.(*v1alpha1.ProxyOptions), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*v1alpha1.RegistryAuth)(nil), (*api.RegistryAuth)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha1_RegistryAuth_To_api_RegistryAuth(a.(*v1alpha1.RegistryAuth), b.(*api.RegistryAuth), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*api.RegistryAuth)(nil), (*v1alpha1.RegistryAuth)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_api_RegistryAuth_To_v1alpha1_RegistryAuth(a.(*api.RegistryAuth), b.(*v1alpha1.RegistryAuth), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*v1alpha1.RegistryMirror)(nil), (*api.RegistryMirror)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha1_RegistryMirror_To_api_RegistryMirror(a.(*v1alpha1.RegistryMirror), b.(*api.RegistryMirror), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*api.RegistryMirror)(nil), (*v1alpha1.RegistryMirror)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_api_RegistryMirror_To_v1alpha1_RegistryMirror(a.(*api.RegistryMirror), b.(*v1alpha1.RegistryMirror), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*v1alpha1.RegistryOptions)(nil), (*api.RegistryOptions)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha1_RegistryOptions_To_api_RegistryOptions(a.(*v1alpha1.RegistryOptions), b.(*api.RegistryOptions), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*api.RegistryOptions)(nil), (*v1alpha1.RegistryOptions)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_api_RegistryOptions_To_v1alpha1_RegistryOptions(a.(*api.RegistryOptions), b.(*v1alpha1.RegistryOptions), scope)
+	}); err != nil {
+		return err
+	}
 	if err := s.AddGeneratedConversionFunc((*v1alpha1.SSM)(nil), (*api.SSM)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1alpha1_SSM_To_api_SSM(a.(*v1alpha1.SSM), b.(*api.SSM), scope)
 	}); err != nil {
@@ -131,9 +211,53 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
+	if err := s.AddGeneratedConversionFunc((*v1alpha1.SwapOptions)(nil), (*api.SwapOptions)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha1_SwapOptions_To_api_SwapOptions(a.(*v1alpha1.SwapOptions), b.(*api.SwapOptions), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*api.SwapOptions)(nil), (*v1alpha1.SwapOptions)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_api_SwapOptions_To_v1alpha1_SwapOptions(a.(*api.SwapOptions), b.(*v1alpha1.SwapOptions), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*v1alpha1.TrustOptions)(nil), (*api.TrustOptions)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha1_TrustOptions_To_api_TrustOptions(a.(*v1alpha1.TrustOptions), b.(*api.TrustOptions), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*api.TrustOptions)(nil), (*v1alpha1.TrustOptions)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_api_TrustOptions_To_v1alpha1_TrustOptions(a.(*api.TrustOptions), b.(*v1alpha1.TrustOptions), scope)
+	}); err != nil {
+		return err
+	}
+	return nil
+}
+
+func autoConvert_v1alpha1_CNIOptions_To_api_CNIOptions(in *v1alpha1.CNIOptions, out *api.CNIOptions, s conversion.Scope) error {
+	out.Plugin = api.CNIPlugin(in.Plugin)
+	out.Encapsulation = api.CNIEncapsulation(in.Encapsulation)
+	out.BGP = in.BGP
 	return nil
 }
 
+// Convert_v1alpha1_CNIOptions_To_api_CNIOptions is an autogenerated conversion function.
+func Convert_v1alpha1_CNIOptions_To_api_CNIOptions(in *v1alpha1.CNIOptions, out *api.CNIOptions, s conversion.Scope) error {
+	return autoConvert_v1alpha1_CNIOptions_To_api_CNIOptions(in, out, s)
+}
+
+func autoConvert_api_CNIOptions_To_v1alpha1_CNIOptions(in *api.CNIOptions, out *v1alpha1.CNIOptions, s conversion.Scope) error {
+	out.Plugin = v1alpha1.CNIPlugin(in.Plugin)
+	out.Encapsulation = v1alpha1.CNIEncapsulation(in.Encapsulation)
+	out.BGP = in.BGP
+	return nil
+}
+
+// Convert_api_CNIOptions_To_v1alpha1_CNIOptions is an autogenerated conversion function.
+func Convert_api_CNIOptions_To_v1alpha1_CNIOptions(in *api.CNIOptions, out *v1alpha1.CNIOptions, s conversion.Scope) error {
+	return autoConvert_api_CNIOptions_To_v1alpha1_CNIOptions(in, out, s)
+}
+
 func autoConvert_v1alpha1_ClusterDetails_To_api_ClusterDetails(in *v1alpha1.ClusterDetails, out *api.ClusterDetails, s conversion.Scope) error {
 	out.Name = in.Name
 	out.Region = in.Region
@@ -168,6 +292,8 @@ func Convert_api_ClusterDetails_To_v1alpha1_ClusterDetails(in *api.ClusterDetail
 
 func autoConvert_v1alpha1_ContainerdOptions_To_api_ContainerdOptions(in *v1alpha1.ContainerdOptions, out *api.ContainerdOptions, s conversion.Scope) error {
 	out.Config = in.Config
+	out.Registries = *(*[]api.RegistryOptions)(unsafe.Pointer(&in.Registries))
+	out.Runtimes = *(*[]api.ContainerdRuntime)(unsafe.Pointer(&in.Runtimes))
 	return nil
 }
 
@@ -178,6 +304,8 @@ func Convert_v1alpha1_ContainerdOptions_To_api_ContainerdOptions(in *v1alpha1.Co
 
 func autoConvert_api_ContainerdOptions_To_v1alpha1_ContainerdOptions(in *api.ContainerdOptions, out *v1alpha1.ContainerdOptions, s conversion.Scope) error {
 	out.Config = in.Config
+	out.Registries = *(*[]v1alpha1.RegistryOptions)(unsafe.Pointer(&in.Registries))
+	out.Runtimes = *(*[]v1alpha1.ContainerdRuntime)(unsafe.Pointer(&in.Runtimes))
 	return nil
 }
 
@@ -186,6 +314,78 @@ func Convert_api_ContainerdOptions_To_v1alpha1_ContainerdOptions(in *api.Contain
 	return autoConvert_api_ContainerdOptions_To_v1alpha1_ContainerdOptions(in, out, s)
 }
 
+func autoConvert_v1alpha1_ContainerdRuntime_To_api_ContainerdRuntime(in *v1alpha1.ContainerdRuntime, out *api.ContainerdRuntime, s conversion.Scope) error {
+	out.Name = in.Name
+	out.RuntimeType = in.RuntimeType
+	out.BinaryPath = in.BinaryPath
+	out.Options = *(*api.InlineDocument)(unsafe.Pointer(&in.Options))
+	return nil
+}
+
+// Convert_v1alpha1_ContainerdRuntime_To_api_ContainerdRuntime is an autogenerated conversion function.
+func Convert_v1alpha1_ContainerdRuntime_To_api_ContainerdRuntime(in *v1alpha1.ContainerdRuntime, out *api.ContainerdRuntime, s conversion.Scope) error {
+	return autoConvert_v1alpha1_ContainerdRuntime_To_api_ContainerdRuntime(in, out, s)
+}
+
+func autoConvert_api_ContainerdRuntime_To_v1alpha1_ContainerdRuntime(in *api.ContainerdRuntime, out *v1alpha1.ContainerdRuntime, s conversion.Scope) error {
+	out.Name = in.Name
+	out.RuntimeType = in.RuntimeType
+	out.BinaryPath = in.BinaryPath
+	out.Options = *(*map[string]runtime.RawExtension)(unsafe.Pointer(&in.Options))
+	return nil
+}
+
+// Convert_api_ContainerdRuntime_To_v1alpha1_ContainerdRuntime is an autogenerated conversion function.
+func Convert_api_ContainerdRuntime_To_v1alpha1_ContainerdRuntime(in *api.ContainerdRuntime, out *v1alpha1.ContainerdRuntime, s conversion.Scope) error {
+	return autoConvert_api_ContainerdRuntime_To_v1alpha1_ContainerdRuntime(in, out, s)
+}
+
+func autoConvert_v1alpha1_DataDirOptions_To_api_DataDirOptions(in *v1alpha1.DataDirOptions, out *api.DataDirOptions, s conversion.Scope) error {
+	out.ContainerdRoot = in.ContainerdRoot
+	out.ContainerdState = in.ContainerdState
+	out.KubeletRoot = in.KubeletRoot
+	out.PodLogs = in.PodLogs
+	return nil
+}
+
+// Convert_v1alpha1_DataDirOptions_To_api_DataDirOptions is an autogenerated conversion function.
+func Convert_v1alpha1_DataDirOptions_To_api_DataDirOptions(in *v1alpha1.DataDirOptions, out *api.DataDirOptions, s conversion.Scope) error {
+	return autoConvert_v1alpha1_DataDirOptions_To_api_DataDirOptions(in, out, s)
+}
+
+func autoConvert_api_DataDirOptions_To_v1alpha1_DataDirOptions(in *api.DataDirOptions, out *v1alpha1.DataDirOptions, s conversion.Scope) error {
+	out.ContainerdRoot = in.ContainerdRoot
+	out.ContainerdState = in.ContainerdState
+	out.KubeletRoot = in.KubeletRoot
+	out.PodLogs = in.PodLogs
+	return nil
+}
+
+// Convert_api_DataDirOptions_To_v1alpha1_DataDirOptions is an autogenerated conversion function.
+func Convert_api_DataDirOptions_To_v1alpha1_DataDirOptions(in *api.DataDirOptions, out *v1alpha1.DataDirOptions, s conversion.Scope) error {
+	return autoConvert_api_DataDirOptions_To_v1alpha1_DataDirOptions(in, out, s)
+}
+
+func autoConvert_v1alpha1_FirewallOptions_To_api_FirewallOptions(in *v1alpha1.FirewallOptions, out *api.FirewallOptions, s conversion.Scope) error {
+	out.Backend = api.FirewallBackend(in.Backend)
+	return nil
+}
+
+// Convert_v1alpha1_FirewallOptions_To_api_FirewallOptions is an autogenerated conversion function.
+func Convert_v1alpha1_FirewallOptions_To_api_FirewallOptions(in *v1alpha1.FirewallOptions, out *api.FirewallOptions, s conversion.Scope) error {
+	return autoConvert_v1alpha1_FirewallOptions_To_api_FirewallOptions(in, out, s)
+}
+
+func autoConvert_api_FirewallOptions_To_v1alpha1_FirewallOptions(in *api.FirewallOptions, out *v1alpha1.FirewallOptions, s conversion.Scope) error {
+	out.Backend = v1alpha1.FirewallBackend(in.Backend)
+	return nil
+}
+
+// Convert_api_FirewallOptions_To_v1alpha1_FirewallOptions is an autogenerated conversion function.
+func Convert_api_FirewallOptions_To_v1alpha1_FirewallOptions(in *api.FirewallOptions, out *v1alpha1.FirewallOptions, s conversion.Scope) error {
+	return autoConvert_api_FirewallOptions_To_v1alpha1_FirewallOptions(in, out, s)
+}
+
 func autoConvert_v1alpha1_HybridOptions_To_api_HybridOptions(in *v1alpha1.HybridOptions, out *api.HybridOptions, s conversion.Scope) error {
 	out.EnableCredentialsFile = in.EnableCredentialsFile
 	out.IAMRolesAnywhere = (*api.IAMRolesAnywhere)(unsafe.Pointer(in.IAMRolesAnywhere))
@@ -246,6 +446,17 @@ func autoConvert_v1alpha1_InstanceOptions_To_api_InstanceOptions(in *v1alpha1.In
 	if err := Convert_v1alpha1_LocalStorageOptions_To_api_LocalStorageOptions(&in.LocalStorage, &out.LocalStorage, s); err != nil {
 		return err
 	}
+	if err := Convert_v1alpha1_DataDirOptions_To_api_DataDirOptions(&in.DataDirs, &out.DataDirs, s); err != nil {
+		return err
+	}
+	if err := Convert_v1alpha1_SwapOptions_To_api_SwapOptions(&in.Swap, &out.Swap, s); err != nil {
+		return err
+	}
+	out.Sysctls = *(*map[string]string)(unsafe.Pointer(&in.Sysctls))
+	out.KernelModules = *(*[]string)(unsafe.Pointer(&in.KernelModules))
+	if err := Convert_v1alpha1_FirewallOptions_To_api_FirewallOptions(&in.Firewall, &out.Firewall, s); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -258,6 +469,17 @@ func autoConvert_api_InstanceOptions_To_v1alpha1_InstanceOptions(in *api.Instanc
 	if err := Convert_api_LocalStorageOptions_To_v1alpha1_LocalStorageOptions(&in.LocalStorage, &out.LocalStorage, s); err != nil {
 		return err
 	}
+	if err := Convert_api_DataDirOptions_To_v1alpha1_DataDirOptions(&in.DataDirs, &out.DataDirs, s); err != nil {
+		return err
+	}
+	if err := Convert_api_SwapOptions_To_v1alpha1_SwapOptions(&in.Swap, &out.Swap, s); err != nil {
+		return err
+	}
+	out.Sysctls = *(*map[string]string)(unsafe.Pointer(&in.Sysctls))
+	out.KernelModules = *(*[]string)(unsafe.Pointer(&in.KernelModules))
+	if err := Convert_api_FirewallOptions_To_v1alpha1_FirewallOptions(&in.Firewall, &out.Firewall, s); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -269,6 +491,7 @@ func Convert_api_InstanceOptions_To_v1alpha1_InstanceOptions(in *api.InstanceOpt
 func autoConvert_v1alpha1_KubeletOptions_To_api_KubeletOptions(in *v1alpha1.KubeletOptions, out *api.KubeletOptions, s conversion.Scope) error {
 	out.Config = *(*api.InlineDocument)(unsafe.Pointer(&in.Config))
 	out.Flags = *(*[]string)(unsafe.Pointer(&in.Flags))
+	out.NodeIPInterface = in.NodeIPInterface
 	return nil
 }
 
@@ -280,6 +503,7 @@ func Convert_v1alpha1_KubeletOptions_To_api_KubeletOptions(in *v1alpha1.KubeletO
 func autoConvert_api_KubeletOptions_To_v1alpha1_KubeletOptions(in *api.KubeletOptions, out *v1alpha1.KubeletOptions, s conversion.Scope) error {
 	out.Config = *(*map[string]runtime.RawExtension)(unsafe.Pointer(&in.Config))
 	out.Flags = *(*[]string)(unsafe.Pointer(&in.Flags))
+	out.NodeIPInterface = in.NodeIPInterface
 	return nil
 }
 
@@ -290,6 +514,8 @@ func Convert_api_KubeletOptions_To_v1alpha1_KubeletOptions(in *api.KubeletOption
 
 func autoConvert_v1alpha1_LocalStorageOptions_To_api_LocalStorageOptions(in *v1alpha1.LocalStorageOptions, out *api.LocalStorageOptions, s conversion.Scope) error {
 	out.Strategy = api.LocalStorageStrategy(in.Strategy)
+	out.Devices = *(*[]string)(unsafe.Pointer(&in.Devices))
+	out.OverwriteDevices = in.OverwriteDevices
 	return nil
 }
 
@@ -300,6 +526,8 @@ func Convert_v1alpha1_LocalStorageOptions_To_api_LocalStorageOptions(in *v1alpha
 
 func autoConvert_api_LocalStorageOptions_To_v1alpha1_LocalStorageOptions(in *api.LocalStorageOptions, out *v1alpha1.LocalStorageOptions, s conversion.Scope) error {
 	out.Strategy = v1alpha1.LocalStorageStrategy(in.Strategy)
+	out.Devices = *(*[]string)(unsafe.Pointer(&in.Devices))
+	out.OverwriteDevices = in.OverwriteDevices
 	return nil
 }
 
@@ -391,6 +619,15 @@ func autoConvert_v1alpha1_NodeConfigSpec_To_api_NodeConfigSpec(in *v1alpha1.Node
 		return err
 	}
 	out.Hybrid = (*api.HybridOptions)(unsafe.Pointer(in.Hybrid))
+	if err := Convert_v1alpha1_ProxyOptions_To_api_ProxyOptions(&in.Proxy, &out.Proxy, s); err != nil {
+		return err
+	}
+	if err := Convert_v1alpha1_TrustOptions_To_api_TrustOptions(&in.Trust, &out.Trust, s); err != nil {
+		return err
+	}
+	if err := Convert_v1alpha1_CNIOptions_To_api_CNIOptions(&in.CNI, &out.CNI, s); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -413,6 +650,15 @@ func autoConvert_api_NodeConfigSpec_To_v1alpha1_NodeConfigSpec(in *api.NodeConfi
 		return err
 	}
 	out.Hybrid = (*v1alpha1.HybridOptions)(unsafe.Pointer(in.Hybrid))
+	if err := Convert_api_ProxyOptions_To_v1alpha1_ProxyOptions(&in.Proxy, &out.Proxy, s); err != nil {
+		return err
+	}
+	if err := Convert_api_TrustOptions_To_v1alpha1_TrustOptions(&in.Trust, &out.Trust, s); err != nil {
+		return err
+	}
+	if err := Convert_api_CNIOptions_To_v1alpha1_CNIOptions(&in.CNI, &out.CNI, s); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -421,6 +667,104 @@ func Convert_api_NodeConfigSpec_To_v1alpha1_NodeConfigSpec(in *api.NodeConfigSpe
 	return autoConvert_api_NodeConfigSpec_To_v1alpha1_NodeConfigSpec(in, out, s)
 }
 
+func autoConvert_v1alpha1_ProxyOptions_To_api_ProxyOptions(in *v1alpha1.ProxyOptions, out *api.ProxyOptions, s conversion.Scope) error {
+	out.HTTPProxy = in.HTTPProxy
+	out.HTTPSProxy = in.HTTPSProxy
+	out.NoProxy = *(*[]string)(unsafe.Pointer(&in.NoProxy))
+	return nil
+}
+
+// Convert_v1alpha1_ProxyOptions_To_api_ProxyOptions is an autogenerated conversion function.
+func Convert_v1alpha1_ProxyOptions_To_api_ProxyOptions(in *v1alpha1.ProxyOptions, out *api.ProxyOptions, s conversion.Scope) error {
+	return autoConvert_v1alpha1_ProxyOptions_To_api_ProxyOptions(in, out, s)
+}
+
+func autoConvert_api_ProxyOptions_To_v1alpha1_ProxyOptions(in *api.ProxyOptions, out *v1alpha1.ProxyOptions, s conversion.Scope) error {
+	out.HTTPProxy = in.HTTPProxy
+	out.HTTPSProxy = in.HTTPSProxy
+	out.NoProxy = *(*[]string)(unsafe.Pointer(&in.NoProxy))
+	return nil
+}
+
+// Convert_api_ProxyOptions_To_v1alpha1_ProxyOptions is an autogenerated conversion function.
+func Convert_api_ProxyOptions_To_v1alpha1_ProxyOptions(in *api.ProxyOptions, out *v1alpha1.ProxyOptions, s conversion.Scope) error {
+	return autoConvert_api_ProxyOptions_To_v1alpha1_ProxyOptions(in, out, s)
+}
+
+func autoConvert_v1alpha1_RegistryAuth_To_api_RegistryAuth(in *v1alpha1.RegistryAuth, out *api.RegistryAuth, s conversion.Scope) error {
+	out.Username = in.Username
+	out.Password = in.Password
+	return nil
+}
+
+// Convert_v1alpha1_RegistryAuth_To_api_RegistryAuth is an autogenerated conversion function.
+func Convert_v1alpha1_RegistryAuth_To_api_RegistryAuth(in *v1alpha1.RegistryAuth, out *api.RegistryAuth, s conversion.Scope) error {
+	return autoConvert_v1alpha1_RegistryAuth_To_api_RegistryAuth(in, out, s)
+}
+
+func autoConvert_api_RegistryAuth_To_v1alpha1_RegistryAuth(in *api.RegistryAuth, out *v1alpha1.RegistryAuth, s conversion.Scope) error {
+	out.Username = in.Username
+	out.Password = in.Password
+	return nil
+}
+
+// Convert_api_RegistryAuth_To_v1alpha1_RegistryAuth is an autogenerated conversion function.
+func Convert_api_RegistryAuth_To_v1alpha1_RegistryAuth(in *api.RegistryAuth, out *v1alpha1.RegistryAuth, s conversion.Scope) error {
+	return autoConvert_api_RegistryAuth_To_v1alpha1_RegistryAuth(in, out, s)
+}
+
+func autoConvert_v1alpha1_RegistryMirror_To_api_RegistryMirror(in *v1alpha1.RegistryMirror, out *api.RegistryMirror, s conversion.Scope) error {
+	out.Endpoint = in.Endpoint
+	out.Capabilities = *(*[]api.RegistryCapability)(unsafe.Pointer(&in.Capabilities))
+	out.SkipVerify = in.SkipVerify
+	out.CAFiles = *(*[]string)(unsafe.Pointer(&in.CAFiles))
+	out.Auth = (*api.RegistryAuth)(unsafe.Pointer(in.Auth))
+	return nil
+}
+
+// Convert_v1alpha1_RegistryMirror_To_api_RegistryMirror is an autogenerated conversion function.
+func Convert_v1alpha1_RegistryMirror_To_api_RegistryMirror(in *v1alpha1.RegistryMirror, out *api.RegistryMirror, s conversion.Scope) error {
+	return autoConvert_v1alpha1_RegistryMirror_To_api_RegistryMirror(in, out, s)
+}
+
+func autoConvert_api_RegistryMirror_To_v1alpha1_RegistryMirror(in *api.RegistryMirror, out *v1alpha1.RegistryMirror, s conversion.Scope) error {
+	out.Endpoint = in.Endpoint
+	out.Capabilities = *(*[]v1alpha1.RegistryCapability)(unsafe.Pointer(&in.Capabilities))
+	out.SkipVerify = in.SkipVerify
+	out.CAFiles = *(*[]string)(unsafe.Pointer(&in.CAFiles))
+	out.Auth = (*v1alpha1.RegistryAuth)(unsafe.Pointer(in.Auth))
+	return nil
+}
+
+// Convert_api_RegistryMirror_To_v1alpha1_RegistryMirror is an autogenerated conversion function.
+func Convert_api_RegistryMirror_To_v1alpha1_RegistryMirror(in *api.RegistryMirror, out *v1alpha1.RegistryMirror, s conversion.Scope) error {
+	return autoConvert_api_RegistryMirror_To_v1alpha1_RegistryMirror(in, out, s)
+}
+
+func autoConvert_v1alpha1_RegistryOptions_To_api_RegistryOptions(in *v1alpha1.RegistryOptions, out *api.RegistryOptions, s conversion.Scope) error {
+	out.Host = in.Host
+	out.Server = in.Server
+	out.Mirrors = *(*[]api.RegistryMirror)(unsafe.Pointer(&in.Mirrors))
+	return nil
+}
+
+// Convert_v1alpha1_RegistryOptions_To_api_RegistryOptions is an autogenerated conversion function.
+func Convert_v1alpha1_RegistryOptions_To_api_RegistryOptions(in *v1alpha1.RegistryOptions, out *api.RegistryOptions, s conversion.Scope) error {
+	return autoConvert_v1alpha1_RegistryOptions_To_api_RegistryOptions(in, out, s)
+}
+
+func autoConvert_api_RegistryOptions_To_v1alpha1_RegistryOptions(in *api.RegistryOptions, out *v1alpha1.RegistryOptions, s conversion.Scope) error {
+	out.Host = in.Host
+	out.Server = in.Server
+	out.Mirrors = *(*[]v1alpha1.RegistryMirror)(unsafe.Pointer(&in.Mirrors))
+	return nil
+}
+
+// Convert_api_RegistryOptions_To_v1alpha1_RegistryOptions is an autogenerated conversion function.
+func Convert_api_RegistryOptions_To_v1alpha1_RegistryOptions(in *api.RegistryOptions, out *v1alpha1.RegistryOptions, s conversion.Scope) error {
+	return autoConvert_api_RegistryOptions_To_v1alpha1_RegistryOptions(in, out, s)
+}
+
 func autoConvert_v1alpha1_SSM_To_api_SSM(in *v1alpha1.SSM, out *api.SSM, s conversion.Scope) error {
 	out.ActivationCode = in.ActivationCode
 	out.ActivationID = in.ActivationID
@@ -442,3 +786,43 @@ func autoConvert_api_SSM_To_v1alpha1_SSM(in *api.SSM, out *v1alpha1.SSM, s conve
 func Convert_api_SSM_To_v1alpha1_SSM(in *api.SSM, out *v1alpha1.SSM, s conversion.Scope) error {
 	return autoConvert_api_SSM_To_v1alpha1_SSM(in, out, s)
 }
+
+func autoConvert_v1alpha1_SwapOptions_To_api_SwapOptions(in *v1alpha1.SwapOptions, out *api.SwapOptions, s conversion.Scope) error {
+	out.Mode = api.SwapMode(in.Mode)
+	return nil
+}
+
+// Convert_v1alpha1_SwapOptions_To_api_SwapOptions is an autogenerated conversion function.
+func Convert_v1alpha1_SwapOptions_To_api_SwapOptions(in *v1alpha1.SwapOptions, out *api.SwapOptions, s conversion.Scope) error {
+	return autoConvert_v1alpha1_SwapOptions_To_api_SwapOptions(in, out, s)
+}
+
+func autoConvert_api_SwapOptions_To_v1alpha1_SwapOptions(in *api.SwapOptions, out *v1alpha1.SwapOptions, s conversion.Scope) error {
+	out.Mode = v1alpha1.SwapMode(in.Mode)
+	return nil
+}
+
+// Convert_api_SwapOptions_To_v1alpha1_SwapOptions is an autogenerated conversion function.
+func Convert_api_SwapOptions_To_v1alpha1_SwapOptions(in *api.SwapOptions, out *v1alpha1.SwapOptions, s conversion.Scope) error {
+	return autoConvert_api_SwapOptions_To_v1alpha1_SwapOptions(in, out, s)
+}
+
+func autoConvert_v1alpha1_TrustOptions_To_api_TrustOptions(in *v1alpha1.TrustOptions, out *api.TrustOptions, s conversion.Scope) error {
+	out.AdditionalCABundles = *(*[]string)(unsafe.Pointer(&in.AdditionalCABundles))
+	return nil
+}
+
+// Convert_v1alpha1_TrustOptions_To_api_TrustOptions is an autogenerated conversion function.
+func Convert_v1alpha1_TrustOptions_To_api_TrustOptions(in *v1alpha1.TrustOptions, out *api.TrustOptions, s conversion.Scope) error {
+	return autoConvert_v1alpha1_TrustOptions_To_api_TrustOptions(in, out, s)
+}
+
+func autoConvert_api_TrustOptions_To_v1alpha1_TrustOptions(in *api.TrustOptions, out *v1alpha1.TrustOptions, s conversion.Scope) error {
+	out.AdditionalCABundles = *(*[]string)(unsafe.Pointer(&in.AdditionalCABundles))
+	return nil
+}
+
+// Convert_api_TrustOptions_To_v1alpha1_TrustOptions is an autogenerated conversion function.
+func Convert_api_TrustOptions_To_v1alpha1_TrustOptions(in *api.TrustOptions, out *v1alpha1.TrustOptions, s conversion.Scope) error {
+	return autoConvert_api_TrustOptions_To_v1alpha1_TrustOptions(in, out, s)
+}
@@ -35,3 +35,25 @@ func GetCIDRIpFamily(cidr string) (IPFamily, error) {
 		return IPFamilyIPv6, nil
 	}
 }
+
+// HasIPv6 returns true when the node uses IPv6, in an IPv6 cluster or with an
+// IPv6 node IP of a dual-stack node.
+func (nc NodeConfig) HasIPv6() bool {
+	if family, err := GetCIDRIpFamily(nc.Spec.Cluster.CIDR); err == nil && family == IPFamilyIPv6 {
+		return true
+	}
+	nodeIPs := []string{nc.Status.Hybrid.NodeIP}
+	for _, flag := range nc.Spec.Kubelet.Flags {
+		if value, ok := strings.CutPrefix(flag, "--node-ip="); ok {
+			nodeIPs = append(nodeIPs, value)
+		}
+	}
+	for _, value := range nodeIPs {
+		for _, nodeIP := range strings.Split(value, ",") {
+			if ip := net.ParseIP(strings.TrimSpace(nodeIP)); ip != nil && ip.To4() == nil {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -30,3 +30,43 @@ func TestGetClusterDNS(t *testing.T) {
 		assert.Equal(t, test.expectedClusterDns, clusterDns)
 	}
 }
+
+func TestHasIPv6(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  NodeConfig
+		want bool
+	}{
+		{
+			name: "ipv4 cluster",
+			cfg:  NodeConfig{Spec: NodeConfigSpec{Cluster: ClusterDetails{CIDR: "10.100.0.0/16"}}},
+		},
+		{
+			name: "ipv6 cluster",
+			cfg:  NodeConfig{Spec: NodeConfigSpec{Cluster: ClusterDetails{CIDR: "fd00::/108"}}},
+			want: true,
+		},
+		{
+			name: "dual-stack node-ip flag",
+			cfg: NodeConfig{Spec: NodeConfigSpec{
+				Cluster: ClusterDetails{CIDR: "10.100.0.0/16"},
+				Kubelet: KubeletOptions{Flags: []string{"--node-ip=10.0.0.5,2001:db8::5"}},
+			}},
+			want: true,
+		},
+		{
+			name: "dual-stack selected node IP",
+			cfg: NodeConfig{
+				Spec:   NodeConfigSpec{Cluster: ClusterDetails{CIDR: "10.100.0.0/16"}},
+				Status: NodeConfigStatus{Hybrid: HybridDetails{NodeIP: "10.0.0.5,2001:db8::5"}},
+			},
+			want: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.want, test.cfg.HasIPv6())
+		})
+	}
+}
@@ -33,6 +33,9 @@ type NodeConfigSpec struct {
 	Instance   InstanceOptions   `json:"instance,omitempty"`
 	Kubelet    KubeletOptions    `json:"kubelet,omitempty"`
 	Hybrid     *HybridOptions    `json:"hybrid,omitempty"`
+	Proxy      ProxyOptions      `json:"proxy,omitempty"`
+	Trust      TrustOptions      `json:"trust,omitempty"`
+	CNI        CNIOptions        `json:"cni,omitempty"`
 }
 
 type NodeConfigStatus struct {
@@ -51,7 +54,10 @@ type InstanceDetails struct {
 }
 
 type HybridDetails struct {
-	NodeName string `json:"nodeName,omitempty"`
+	NodeName          string   `json:"nodeName,omitempty"`
+	RemotePodNetworks []string `json:"remotePodNetworks,omitempty"`
+	// NodeIP is the node IP nodeadm selected for the kubelet
+	NodeIP string `json:"nodeIP,omitempty"`
 }
 
 type DefaultOptions struct {
@@ -77,6 +83,8 @@ type KubeletOptions struct {
 	// amended to the generated defaults, and therefore will act as overrides
 	// https://kubernetes.io/docs/reference/command-line-tools-reference/kubelet/
 	Flags []string `json:"flags,omitempty"`
+	// NodeIPInterface is the name of the interface the hybrid node IP is taken from
+	NodeIPInterface string `json:"nodeIPInterface,omitempty"`
 }
 
 // InlineDocument is an alias to a dynamically typed map. This allows using
@@ -88,6 +96,45 @@ type ContainerdOptions struct {
 	// by the user to override default generated configurations
 	// https://github.com/containerd/containerd/blob/main/docs/man/containerd-config.toml.5.md
 	Config string `json:"config,omitempty"`
+	// Registries are rendered as containerd hosts.toml files
+	// https://github.com/containerd/containerd/blob/main/docs/hosts.md
+	Registries []RegistryOptions `json:"registries,omitempty"`
+	// Runtimes are additional runtime handlers of the containerd CRI plugin
+	Runtimes []ContainerdRuntime `json:"runtimes,omitempty"`
+}
+
+type ContainerdRuntime struct {
+	Name        string         `json:"name"`
+	RuntimeType string         `json:"runtimeType"`
+	BinaryPath  string         `json:"binaryPath,omitempty"`
+	Options     InlineDocument `json:"options,omitempty"`
+}
+
+type RegistryOptions struct {
+	Host    string           `json:"host"`
+	Server  string           `json:"server,omitempty"`
+	Mirrors []RegistryMirror `json:"mirrors,omitempty"`
+}
+
+type RegistryMirror struct {
+	Endpoint     string               `json:"endpoint"`
+	Capabilities []RegistryCapability `json:"capabilities,omitempty"`
+	SkipVerify   bool                 `json:"skipVerify,omitempty"`
+	CAFiles      []string             `json:"caFiles,omitempty"`
+	Auth         *RegistryAuth        `json:"auth,omitempty"`
+}
+
+type RegistryCapability string
+
+const (
+	RegistryCapabilityPull    RegistryCapability = "pull"
+	RegistryCapabilityResolve RegistryCapability = "resolve"
+	RegistryCapabilityPush    RegistryCapability = "push"
+)
+
+type RegistryAuth struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
 }
 
 type IPFamily string
@@ -98,11 +145,72 @@ const (
 )
 
 type InstanceOptions struct {
-	LocalStorage LocalStorageOptions `json:"localStorage,omitempty"`
+	LocalStorage  LocalStorageOptions `json:"localStorage,omitempty"`
+	DataDirs      DataDirOptions      `json:"dataDirs,omitempty"`
+	Swap          SwapOptions         `json:"swap,omitempty"`
+	Sysctls       map[string]string   `json:"sysctls,omitempty"`
+	KernelModules []string            `json:"kernelModules,omitempty"`
+	Firewall      FirewallOptions     `json:"firewall,omitempty"`
+}
+
+type CNIOptions struct {
+	Plugin        CNIPlugin        `json:"plugin,omitempty"`
+	Encapsulation CNIEncapsulation `json:"encapsulation,omitempty"`
+	BGP           bool             `json:"bgp,omitempty"`
+}
+
+type CNIPlugin string
+
+const (
+	CNIPluginCilium CNIPlugin = "cilium"
+	CNIPluginCalico CNIPlugin = "calico"
+	CNIPluginOther  CNIPlugin = "other"
+)
+
+type CNIEncapsulation string
+
+const (
+	CNIEncapsulationVXLAN  CNIEncapsulation = "vxlan"
+	CNIEncapsulationGeneve CNIEncapsulation = "geneve"
+	CNIEncapsulationNone   CNIEncapsulation = "none"
+)
+
+type FirewallOptions struct {
+	Backend FirewallBackend `json:"backend,omitempty"`
+}
+
+type FirewallBackend string
+
+const (
+	FirewallBackendFirewalld FirewallBackend = "firewalld"
+	FirewallBackendUFW       FirewallBackend = "ufw"
+	FirewallBackendNftables  FirewallBackend = "nftables"
+	FirewallBackendIptables  FirewallBackend = "iptables"
+)
+
+type SwapOptions struct {
+	Mode SwapMode `json:"mode,omitempty"`
+}
+
+type SwapMode string
+
+const (
+	SwapModeDisable           SwapMode = "disable"
+	SwapModeLimited           SwapMode = "limited"
+	SwapModeDisablePartitions SwapMode = "disable-partitions"
+)
+
+type DataDirOptions struct {
+	ContainerdRoot  string `json:"containerdRoot,omitempty"`
+	ContainerdState string `json:"containerdState,omitempty"`
+	KubeletRoot     string `json:"kubeletRoot,omitempty"`
+	PodLogs         string `json:"podLogs,omitempty"`
 }
 
 type LocalStorageOptions struct {
-	Strategy LocalStorageStrategy `json:"strategy,omitempty"`
+	Strategy         LocalStorageStrategy `json:"strategy,omitempty"`
+	Devices          []string             `json:"devices,omitempty"`
+	OverwriteDevices bool                 `json:"overwriteDevices,omitempty"`
 }
 
 type LocalStorageStrategy string
@@ -112,6 +220,16 @@ const (
 	LocalStorageMount LocalStorageStrategy = "Mount"
 )
 
+type ProxyOptions struct {
+	HTTPProxy  string   `json:"httpProxy,omitempty"`
+	HTTPSProxy string   `json:"httpsProxy,omitempty"`
+	NoProxy    []string `json:"noProxy,omitempty"`
+}
+
+type TrustOptions struct {
+	AdditionalCABundles []string `json:"additionalCABundles,omitempty"`
+}
+
 type NodeType string
 
 const (
@@ -144,6 +262,11 @@ func (nc NodeConfig) IsSSM() bool {
 	return nc.Spec.Hybrid != nil && nc.Spec.Hybrid.SSM != nil
 }
 
+// IsProxyEnabled returns true when an HTTP or HTTPS proxy is configured.
+func (nc NodeConfig) IsProxyEnabled() bool {
+	return nc.Spec.Proxy.HTTPProxy != "" || nc.Spec.Proxy.HTTPSProxy != ""
+}
+
 func (nc NodeConfig) GetNodeType() NodeType {
 	if nc.IsSSM() {
 		return Ssm
@@ -10,4 +10,8 @@ const (
 	Ssm                     = "ssm"
 	Containerd              = "containerd"
 	Iptables                = "iptables"
+	Crictl                  = "crictl"
+	Nerdctl                 = "nerdctl"
+	Crio                    = "crio"
+	Gvisor                  = "gvisor"
 )
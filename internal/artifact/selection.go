@@ -0,0 +1,82 @@
+package artifact
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Optional are the artifacts that are only installed when explicitly requested.
+var Optional = []string{
+	Crictl,
+	Nerdctl,
+	Gvisor,
+}
+
+// Selectable are the artifacts that are installed by default and can be
+// picked for install and upgrade.
+var Selectable = []string{
+	Containerd,
+	Crio,
+	Iptables,
+	Kubelet,
+	Kubectl,
+	CniPlugins,
+	ImageCredentialProvider,
+	IamAuthenticator,
+	Ssm,
+	IamRolesAnywhere,
+}
+
+// All returns every artifact that can be picked, including the optional ones.
+func All() []string {
+	return slices.Concat(Selectable, Optional)
+}
+
+// Selection is the set of artifacts selected to be installed or upgraded.
+// A nil Selection includes every artifact in Selectable, like an empty allow
+// list.
+type Selection map[string]bool
+
+// NewSelection builds a Selection from an allow list and a deny list of artifact
+// names. An empty allow list selects every artifact in Selectable. Optional
+// artifacts are only selected when they are part of the allow list.
+func NewSelection(components, exclude []string) (Selection, error) {
+	if len(components) == 0 {
+		components = Selectable
+	}
+	allowed := All()
+	for _, name := range slices.Concat(components, exclude) {
+		if !slices.Contains(allowed, name) {
+			return nil, fmt.Errorf("invalid component %s. Allowed values: [%s]", name, strings.Join(allowed, ", "))
+		}
+	}
+
+	selection := Selection{}
+	for _, name := range components {
+		selection[name] = true
+	}
+	for _, name := range exclude {
+		delete(selection, name)
+	}
+	return selection, nil
+}
+
+// AddOptional adds optional artifacts to the selection.
+func (s Selection) AddOptional(names []string) error {
+	for _, name := range names {
+		if !slices.Contains(Optional, name) {
+			return fmt.Errorf("invalid optional component %s. Allowed values: [%s]", name, strings.Join(Optional, ", "))
+		}
+		s[name] = true
+	}
+	return nil
+}
+
+// Includes returns true if the artifact is part of the selection.
+func (s Selection) Includes(name string) bool {
+	if s == nil {
+		return slices.Contains(Selectable, name)
+	}
+	return s[name]
+}
@@ -0,0 +1,102 @@
+package artifact_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/aws/eks-hybrid/internal/artifact"
+)
+
+func TestNewSelection(t *testing.T) {
+	testCases := []struct {
+		name         string
+		components   []string
+		exclude      []string
+		wantIncluded []string
+		wantExcluded []string
+		wantErr      string
+	}{
+		{
+			name:         "all by default",
+			wantIncluded: artifact.Selectable,
+			wantExcluded: artifact.Optional,
+		},
+		{
+			name:         "optional components",
+			components:   []string{artifact.Kubelet, artifact.Crictl},
+			wantIncluded: []string{artifact.Kubelet, artifact.Crictl},
+			wantExcluded: []string{artifact.Nerdctl},
+		},
+		{
+			name:         "exclude from all",
+			exclude:      []string{artifact.Kubectl, artifact.CniPlugins},
+			wantIncluded: []string{artifact.Kubelet, artifact.Containerd, artifact.IamAuthenticator},
+			wantExcluded: []string{artifact.Kubectl, artifact.CniPlugins},
+		},
+		{
+			name:         "only components",
+			components:   []string{artifact.Kubelet, artifact.Ssm},
+			wantIncluded: []string{artifact.Kubelet, artifact.Ssm},
+			wantExcluded: []string{artifact.Kubectl, artifact.Containerd},
+		},
+		{
+			name:         "components and exclude",
+			components:   []string{artifact.Kubelet, artifact.Kubectl},
+			exclude:      []string{artifact.Kubectl},
+			wantIncluded: []string{artifact.Kubelet},
+			wantExcluded: []string{artifact.Kubectl},
+		},
+		{
+			name:       "invalid component",
+			components: []string{"runc"},
+			wantErr:    "invalid component runc",
+		},
+		{
+			name:    "invalid exclude",
+			exclude: []string{"kubeadm"},
+			wantErr: "invalid component kubeadm",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			selection, err := artifact.NewSelection(tc.components, tc.exclude)
+			if tc.wantErr != "" {
+				g.Expect(err).To(MatchError(ContainSubstring(tc.wantErr)))
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+			for _, name := range tc.wantIncluded {
+				g.Expect(selection.Includes(name)).To(BeTrue(), name)
+			}
+			for _, name := range tc.wantExcluded {
+				g.Expect(selection.Includes(name)).To(BeFalse(), name)
+			}
+		})
+	}
+}
+
+func TestNilSelectionIncludesSelectable(t *testing.T) {
+	g := NewWithT(t)
+	var selection artifact.Selection
+	for _, name := range artifact.Selectable {
+		g.Expect(selection.Includes(name)).To(BeTrue())
+	}
+	for _, name := range artifact.Optional {
+		g.Expect(selection.Includes(name)).To(BeFalse())
+	}
+}
+
+func TestSelectionAddOptional(t *testing.T) {
+	g := NewWithT(t)
+	selection, err := artifact.NewSelection(nil, nil)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	g.Expect(selection.AddOptional([]string{artifact.Crictl})).To(Succeed())
+	g.Expect(selection.Includes(artifact.Crictl)).To(BeTrue())
+	g.Expect(selection.Includes(artifact.Nerdctl)).To(BeFalse())
+
+	g.Expect(selection.AddOptional([]string{artifact.Kubectl})).To(MatchError(ContainSubstring("invalid optional component kubectl")))
+}
@@ -0,0 +1,33 @@
+package artifact
+
+import (
+	"io"
+
+	"github.com/ProtonMail/gopenpgp/v3/crypto"
+)
+
+// VerifySignature validates the detached PGP signature of data with the armored publicKey.
+func VerifySignature(data, signature io.Reader, publicKey string) error {
+	verificationKey, err := crypto.NewKeyFromArmored(publicKey)
+	if err != nil {
+		return err
+	}
+
+	pgp := crypto.PGP()
+	verifier, _ := pgp.Verify().
+		VerificationKey(verificationKey).
+		New()
+
+	verifyDataReader, err := verifier.VerifyingReader(data, signature, crypto.Bytes)
+	if err != nil {
+		return err
+	}
+	verifyResult, err := verifyDataReader.ReadAllAndVerifySignature()
+	if err != nil {
+		return err
+	}
+	if err := verifyResult.SignatureError(); err != nil {
+		return err
+	}
+	return nil
+}
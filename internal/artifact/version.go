@@ -0,0 +1,12 @@
+package artifact
+
+import "strings"
+
+// NormalizeVersion adds the v prefix semver expects to release versions like
+// 1.0.3, which the manifests list without it.
+func NormalizeVersion(version string) string {
+	if !strings.HasPrefix(version, "v") {
+		return "v" + version
+	}
+	return version
+}
@@ -0,0 +1,15 @@
+package artifact_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/aws/eks-hybrid/internal/artifact"
+)
+
+func TestNormalizeVersion(t *testing.T) {
+	g := NewWithT(t)
+	g.Expect(artifact.NormalizeVersion("1.0.3")).To(Equal("v1.0.3"))
+	g.Expect(artifact.NormalizeVersion("v1.0.3")).To(Equal("v1.0.3"))
+}
@@ -0,0 +1,36 @@
+package ec2
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+)
+
+// VPCCIDRs returns the IPv4 and IPv6 CIDR blocks associated with the VPC.
+func VPCCIDRs(ctx context.Context, config aws.Config, vpcID string) ([]string, error) {
+	client := ec2.NewFromConfig(config)
+	out, err := client.DescribeVpcs(ctx, &ec2.DescribeVpcsInput{
+		VpcIds: []string{vpcID},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(out.Vpcs) == 0 {
+		return nil, fmt.Errorf("vpc %s not found", vpcID)
+	}
+
+	var cidrs []string
+	for _, association := range out.Vpcs[0].CidrBlockAssociationSet {
+		if association.CidrBlock != nil {
+			cidrs = append(cidrs, *association.CidrBlock)
+		}
+	}
+	for _, association := range out.Vpcs[0].Ipv6CidrBlockAssociationSet {
+		if association.Ipv6CidrBlock != nil {
+			cidrs = append(cidrs, *association.Ipv6CidrBlock)
+		}
+	}
+	return cidrs, nil
+}
@@ -48,8 +48,33 @@ func ReadClusterDetails(ctx context.Context, config aws.Config, node *api.NodeCo
 	}
 
 	if clusterDetails.CIDR == "" {
-		clusterDetails.CIDR = *cluster.Cluster.KubernetesNetworkConfig.ServiceIpv4Cidr
+		clusterDetails.CIDR = ServiceCIDR(cluster.Cluster)
 	}
 
 	return clusterDetails, nil
 }
+
+// ServiceCIDR returns the service CIDR block of the cluster, the IPv6 one for
+// IPv6 clusters.
+func ServiceCIDR(cluster *types.Cluster) string {
+	config := cluster.KubernetesNetworkConfig
+	if config == nil {
+		return ""
+	}
+	if config.IpFamily == types.IpFamilyIpv6 && config.ServiceIpv6Cidr != nil {
+		return *config.ServiceIpv6Cidr
+	}
+	return aws.ToString(config.ServiceIpv4Cidr)
+}
+
+// DescribeCluster returns the EKS cluster.
+func DescribeCluster(ctx context.Context, config aws.Config, clusterName string) (*types.Cluster, error) {
+	client := eks.NewFromConfig(config)
+	out, err := client.DescribeCluster(ctx, &eks.DescribeClusterInput{
+		Name: &clusterName,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return out.Cluster, nil
+}
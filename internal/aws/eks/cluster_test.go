@@ -111,3 +111,39 @@ func TestReadClusterDetailsErrorClusterNotActive(t *testing.T) {
 	_, err := eks.ReadClusterDetails(ctx, config, node)
 	g.Expect(err).To(MatchError(ContainSubstring("eks cluster my-cluster is not active")))
 }
+
+func TestServiceCIDR(t *testing.T) {
+	testCases := []struct {
+		name    string
+		cluster *types.Cluster
+		want    string
+	}{
+		{
+			name: "ipv4",
+			cluster: &types.Cluster{KubernetesNetworkConfig: &types.KubernetesNetworkConfigResponse{
+				IpFamily:        types.IpFamilyIpv4,
+				ServiceIpv4Cidr: aws.String("172.16.0.0/16"),
+			}},
+			want: "172.16.0.0/16",
+		},
+		{
+			name: "ipv6",
+			cluster: &types.Cluster{KubernetesNetworkConfig: &types.KubernetesNetworkConfigResponse{
+				IpFamily:        types.IpFamilyIpv6,
+				ServiceIpv4Cidr: aws.String("172.16.0.0/16"),
+				ServiceIpv6Cidr: aws.String("fd00:1234::/108"),
+			}},
+			want: "fd00:1234::/108",
+		},
+		{
+			name:    "no network config",
+			cluster: &types.Cluster{},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			g.Expect(eks.ServiceCIDR(tc.cluster)).To(Equal(tc.want))
+		})
+	}
+}
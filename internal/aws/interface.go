@@ -8,5 +8,8 @@ import (
 
 type Config interface {
 	ConfigureAws(ctx context.Context) error
+	// LoadAws loads the AWS config set up by a previous ConfigureAws without
+	// changing the node.
+	LoadAws(ctx context.Context) error
 	GetConfig() *aws.Config
 }
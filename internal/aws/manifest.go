@@ -16,6 +16,7 @@ type Manifest struct {
 	SupportedEksReleases     []SupportedEksRelease     `json:"supported_eks_releases"`
 	IamRolesAnywhereReleases []IamRolesAnywhereRelease `json:"iam_roles_anywhere_releases"`
 	SsmReleases              []SsmRelease              `json:"ssm_releases"`
+	NodeadmReleases          []NodeadmRelease          `json:"nodeadm_releases"`
 }
 
 type SupportedEksRelease struct {
@@ -41,17 +42,27 @@ type SsmRelease struct {
 	Artifacts []Artifact `json:"artifacts"`
 }
 
+type NodeadmRelease struct {
+	Version   string     `json:"version"`
+	Artifacts []Artifact `json:"artifacts"`
+}
+
 type Artifact struct {
-	Name        string `json:"name"`
-	Arch        string `json:"arch"`
-	OS          string `json:"os"`
-	URI         string `json:"uri"`
-	ChecksumURI string `json:"checksum_uri"`
+	Name         string `json:"name"`
+	Arch         string `json:"arch"`
+	OS           string `json:"os"`
+	URI          string `json:"uri"`
+	ChecksumURI  string `json:"checksum_uri"`
+	SignatureURI string `json:"signature_uri,omitempty"`
 }
 
 // Read from the manifest file on s3 and parse into Manifest struct
 func getReleaseManifest(ctx context.Context) (*Manifest, error) {
-	yamlFileData, err := util.GetHttpFile(ctx, manifestUrl)
+	return getReleaseManifestFrom(ctx, manifestUrl)
+}
+
+func getReleaseManifestFrom(ctx context.Context, url string) (*Manifest, error) {
+	yamlFileData, err := util.GetHttpFile(ctx, url)
 	if err != nil {
 		return nil, err
 	}
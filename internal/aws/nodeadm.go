@@ -0,0 +1,153 @@
+package aws
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"net/url"
+	"runtime"
+	"strings"
+
+	"github.com/pkg/errors"
+	"golang.org/x/mod/semver"
+
+	"github.com/aws/eks-hybrid/internal/artifact"
+	"github.com/aws/eks-hybrid/internal/util"
+)
+
+const nodeadmArtifactName = "nodeadm"
+
+// NodeadmSource serves a nodeadm release. If Mirror is set, the scheme and host
+// of the manifest and artifact URLs are replaced with the mirror's.
+type NodeadmSource struct {
+	Release NodeadmRelease
+	Mirror  string
+}
+
+// GetNodeadmSource gets the source for the nodeadm release matching version, or
+// for the latest release if version is empty.
+func GetNodeadmSource(ctx context.Context, version, mirror string) (NodeadmSource, error) {
+	manifestURL, err := withMirror(manifestUrl, mirror)
+	if err != nil {
+		return NodeadmSource{}, err
+	}
+	manifest, err := getReleaseManifestFrom(ctx, manifestURL)
+	if err != nil {
+		return NodeadmSource{}, err
+	}
+
+	release, err := getNodeadmRelease(version, manifest)
+	if err != nil {
+		return NodeadmSource{}, errors.Wrap(err, "getting nodeadm release")
+	}
+
+	return NodeadmSource{
+		Release: release,
+		Mirror:  mirror,
+	}, nil
+}
+
+func getNodeadmRelease(version string, manifest *Manifest) (NodeadmRelease, error) {
+	if len(manifest.NodeadmReleases) < 1 {
+		return NodeadmRelease{}, fmt.Errorf("no nodeadm releases found")
+	}
+
+	if version != "" {
+		want := artifact.NormalizeVersion(version)
+		for _, release := range manifest.NodeadmReleases {
+			if artifact.NormalizeVersion(release.Version) == want {
+				return release, nil
+			}
+		}
+		return NodeadmRelease{}, fmt.Errorf("nodeadm version %s not found in release manifest", version)
+	}
+
+	latestRelease := manifest.NodeadmReleases[0]
+	for _, release := range manifest.NodeadmReleases {
+		if semver.Compare(artifact.NormalizeVersion(latestRelease.Version), artifact.NormalizeVersion(release.Version)) < 0 {
+			latestRelease = release
+		}
+	}
+	return latestRelease, nil
+}
+
+// GetNodeadm returns the nodeadm binary for the running platform.
+func (ns NodeadmSource) GetNodeadm(ctx context.Context) (artifact.Source, error) {
+	releaseArtifact, err := ns.artifact()
+	if err != nil {
+		return nil, err
+	}
+	uri, err := withMirror(releaseArtifact.URI, ns.Mirror)
+	if err != nil {
+		return nil, err
+	}
+	checksumURI, err := withMirror(releaseArtifact.ChecksumURI, ns.Mirror)
+	if err != nil {
+		return nil, err
+	}
+
+	obj, err := util.GetHttpFileReader(ctx, uri)
+	if err != nil {
+		return nil, fmt.Errorf("getting nodeadm file reader: %w", err)
+	}
+
+	checksum, err := util.GetHttpFile(ctx, checksumURI)
+	if err != nil {
+		obj.Close()
+		return nil, fmt.Errorf("getting nodeadm checksum file: %w", err)
+	}
+
+	source, err := artifact.WithChecksum(obj, sha256.New(), checksum)
+	if err != nil {
+		obj.Close()
+		return nil, fmt.Errorf("getting nodeadm with checksum: %w", err)
+	}
+	return source, nil
+}
+
+// GetNodeadmSignature returns the detached PGP signature of the nodeadm binary
+// for the running platform.
+func (ns NodeadmSource) GetNodeadmSignature(ctx context.Context) (io.ReadCloser, error) {
+	releaseArtifact, err := ns.artifact()
+	if err != nil {
+		return nil, err
+	}
+	if releaseArtifact.SignatureURI == "" {
+		return nil, fmt.Errorf("nodeadm release %s has no signature", ns.Release.Version)
+	}
+	uri, err := withMirror(releaseArtifact.SignatureURI, ns.Mirror)
+	if err != nil {
+		return nil, err
+	}
+	return util.GetHttpFileReader(ctx, uri)
+}
+
+func (ns NodeadmSource) artifact() (Artifact, error) {
+	for _, releaseArtifact := range ns.Release.Artifacts {
+		if releaseArtifact.Name == nodeadmArtifactName && releaseArtifact.Arch == runtime.GOARCH && releaseArtifact.OS == runtime.GOOS {
+			return releaseArtifact, nil
+		}
+	}
+	return Artifact{}, fmt.Errorf("could not find nodeadm artifact for %s arch and %s os", runtime.GOARCH, runtime.GOOS)
+}
+
+// withMirror replaces the scheme and host of uri with the mirror's, keeping the
+// path of the mirror as a prefix. If mirror is empty, uri is returned unchanged.
+func withMirror(uri, mirror string) (string, error) {
+	if mirror == "" {
+		return uri, nil
+	}
+	mirrorURL, err := url.Parse(mirror)
+	if err != nil {
+		return "", fmt.Errorf("parsing mirror url: %w", err)
+	}
+	original, err := url.Parse(uri)
+	if err != nil {
+		return "", fmt.Errorf("parsing artifact url: %w", err)
+	}
+	original.Scheme = mirrorURL.Scheme
+	original.Host = mirrorURL.Host
+	original.Path = strings.TrimSuffix(mirrorURL.Path, "/") + original.Path
+	return original.String(), nil
+}
@@ -156,6 +156,16 @@ func (as Source) GetCniPlugins(ctx context.Context) (artifact.Source, error) {
 	return as.getEksSource(ctx, "cni-plugins")
 }
 
+// GetCrictl satisfies crictl.Source.
+func (as Source) GetCrictl(ctx context.Context) (artifact.Source, error) {
+	return as.getEksSource(ctx, "crictl")
+}
+
+// GetNerdctl satisfies nerdctl.Source.
+func (as Source) GetNerdctl(ctx context.Context) (artifact.Source, error) {
+	return as.getEksSource(ctx, "nerdctl")
+}
+
 func (as Source) getEksSource(ctx context.Context, artifactName string) (artifact.Source, error) {
 	return getSource(ctx, artifactName, as.Eks.Artifacts)
 }
@@ -18,8 +18,9 @@ var cleanupDirs = []string{
 
 // Force handles the cleanup of leftover directories.
 type Force struct {
-	logger  *zap.Logger
-	rootDir string
+	logger   *zap.Logger
+	rootDir  string
+	dataDirs []string
 }
 
 // Option is a function that configures a Force instance.
@@ -32,6 +33,14 @@ func WithRootDir(rootDir string) Option {
 	}
 }
 
+// WithDataDirs sets the relocated data directories to empty. These are usually
+// mount points, so only their content is removed.
+func WithDataDirs(dirs ...string) Option {
+	return func(f *Force) {
+		f.dataDirs = dirs
+	}
+}
+
 // New creates a new Force.
 func New(logger *zap.Logger, opts ...Option) *Force {
 	f := &Force{
@@ -52,6 +61,12 @@ func (c *Force) Cleanup() error {
 			return fmt.Errorf("removing directory %s: %w", dir, err)
 		}
 	}
+	for _, dir := range c.dataDirs {
+		fullPath := filepath.Join(c.rootDir, strings.TrimPrefix(dir, "/"))
+		if err := c.emptyDir(fullPath); err != nil {
+			return fmt.Errorf("emptying directory %s: %w", dir, err)
+		}
+	}
 	return nil
 }
 
@@ -59,3 +74,19 @@ func (c *Force) removeDir(dir string) error {
 	c.logger.Info("Removing directory", zap.String("path", dir))
 	return os.RemoveAll(dir)
 }
+
+func (c *Force) emptyDir(dir string) error {
+	c.logger.Info("Removing directory content", zap.String("path", dir))
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if err := os.RemoveAll(filepath.Join(dir, entry.Name())); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -62,3 +62,18 @@ func TestCleanup(t *testing.T) {
 		})
 	}
 }
+
+func TestCleanupDataDirs(t *testing.T) {
+	g := NewWithT(t)
+	tmpRoot := t.TempDir()
+	dataDir := filepath.Join(tmpRoot, "/mnt/data/kubelet")
+	g.Expect(os.MkdirAll(filepath.Join(dataDir, "pods"), 0o755)).To(Succeed())
+	g.Expect(os.WriteFile(filepath.Join(dataDir, "test.txt"), []byte("test"), 0o644)).To(Succeed())
+
+	force := cleanup.New(zaptest.NewLogger(t), cleanup.WithRootDir(tmpRoot), cleanup.WithDataDirs("/mnt/data/kubelet", "/mnt/data/missing"))
+	g.Expect(force.Cleanup()).To(Succeed())
+
+	entries, err := os.ReadDir(dataDir)
+	g.Expect(err).NotTo(HaveOccurred(), "data directory should not be removed")
+	g.Expect(entries).To(BeEmpty())
+}
@@ -3,67 +3,130 @@ package containerd
 import (
 	"bytes"
 	_ "embed"
-	"path/filepath"
+	"os"
 	"text/template"
 
-	"go.uber.org/zap"
-
 	"github.com/aws/eks-hybrid/internal/api"
+	"github.com/aws/eks-hybrid/internal/cri"
+	"github.com/aws/eks-hybrid/internal/system"
 	"github.com/aws/eks-hybrid/internal/util"
 )
 
-const ContainerRuntimeEndpoint = "unix:///run/containerd/containerd.sock"
+const ContainerRuntimeEndpoint = cri.ContainerdEndpoint
 
 const (
 	containerdConfigDir               = "/etc/containerd"
 	containerdConfigFile              = "/etc/containerd/config.toml"
 	containerdConfigImportDir         = "/etc/containerd/config.d"
+	containerdConfigImportFile        = containerdConfigImportDir + "/00-nodeadm.toml"
 	containerdKernelModulesConfigFile = "/etc/modules-load.d/containerd.conf"
 	containerdConfigPerm              = 0o644
+
+	defaultRootDir  = "/var/lib/containerd"
+	defaultStateDir = "/run/containerd"
 )
 
+var configTemplateFuncs = template.FuncMap{
+	"key":   tomlKey,
+	"quote": tomlQuote,
+	"value": tomlValue,
+}
+
 var (
 	//go:embed config.template.toml
 	containerdConfigTemplateData string
-	containerdConfigTemplate     = template.Must(template.New(containerdConfigFile).Parse(containerdConfigTemplateData))
+	containerdConfigTemplate     = template.Must(template.New(containerdConfigFile).Funcs(configTemplateFuncs).Parse(containerdConfigTemplateData))
+
+	// containerd 2.x uses config version 3, where the CRI plugin is split into
+	// the io.containerd.cri.v1.images and io.containerd.cri.v1.runtime plugins
+	//go:embed config-v3.template.toml
+	containerdConfigV3TemplateData string
+	containerdConfigV3Template     = template.Must(template.New(containerdConfigFile).Funcs(configTemplateFuncs).Parse(containerdConfigV3TemplateData))
 
 	//go:embed kernel-modules.conf
 	containerdKernelModulesFileData string
 )
 
 type containerdTemplateVars struct {
+	Root         string
+	State        string
 	SandboxImage string
+	Runtimes     []api.ContainerdRuntime
 }
 
 func writeContainerdConfig(cfg *api.NodeConfig) error {
-	// write nodeadm's generated containerd config to the default path
-	containerdConfig, err := generateContainerdConfig(cfg)
+	files, err := renderContainerdConfig(cfg)
 	if err != nil {
 		return err
 	}
-	zap.L().Info("Writing containerd config to file..", zap.String("path", containerdConfigFile))
-	if err := util.WriteFileWithDir(containerdConfigFile, containerdConfig, containerdConfigPerm); err != nil {
-		return err
+	return stageConfigFiles(files, dumpConfig)
+}
+
+func renderContainerdConfig(cfg *api.NodeConfig) ([]configFile, error) {
+	majorVersion, err := installedMajorVersion()
+	if err != nil {
+		return nil, err
+	}
+	// write nodeadm's generated containerd config to the default path
+	containerdConfig, err := generateContainerdConfig(cfg, majorVersion)
+	if err != nil {
+		return nil, err
 	}
+	files := []configFile{{path: containerdConfigFile, content: containerdConfig}}
 	if len(cfg.Spec.Containerd.Config) > 0 {
-		containerConfigImportPath := filepath.Join(containerdConfigImportDir, "00-nodeadm.toml")
-		zap.L().Info("Writing user containerd config to drop-in file..", zap.String("path", containerConfigImportPath))
-		return util.WriteFileWithDir(containerConfigImportPath, []byte(cfg.Spec.Containerd.Config), containerdConfigPerm)
+		// the user config is written to a drop-in file imported by the default config
+		files = append(files, configFile{path: containerdConfigImportFile, content: []byte(cfg.Spec.Containerd.Config)})
 	}
-	return nil
+	return files, nil
 }
 
-func generateContainerdConfig(cfg *api.NodeConfig) ([]byte, error) {
+func generateContainerdConfig(cfg *api.NodeConfig, majorVersion int) ([]byte, error) {
 	configVars := containerdTemplateVars{
+		Root:         RootDir(cfg),
+		State:        StateDir(cfg),
 		SandboxImage: cfg.Status.Defaults.SandboxImage,
+		Runtimes:     cfg.Spec.Containerd.Runtimes,
+	}
+	configTemplate := containerdConfigTemplate
+	if majorVersion >= 2 {
+		configTemplate = containerdConfigV3Template
 	}
 	var buf bytes.Buffer
-	if err := containerdConfigTemplate.Execute(&buf, configVars); err != nil {
+	if err := configTemplate.Execute(&buf, configVars); err != nil {
 		return nil, err
 	}
 	return buf.Bytes(), nil
 }
 
-func writeContainerdKernelModulesConfig() error {
-	return util.WriteFileWithDir(containerdKernelModulesConfigFile, []byte(containerdKernelModulesFileData), containerdConfigPerm)
+func writeContainerdKernelModulesConfig(cfg *api.NodeConfig) error {
+	return util.WriteFileWithDir(containerdKernelModulesConfigFile, renderContainerdKernelModulesConfig(cfg), containerdConfigPerm)
+}
+
+func renderContainerdKernelModulesConfig(cfg *api.NodeConfig) []byte {
+	return system.KernelModulesConfig(containerdKernelModulesFileData, cfg)
+}
+
+// RemoveKernelModulesConfig removes the modules-load.d config written by
+// nodeadm.
+func RemoveKernelModulesConfig() error {
+	if err := os.Remove(containerdKernelModulesConfigFile); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// RootDir returns the directory where containerd stores its persistent data.
+func RootDir(cfg *api.NodeConfig) string {
+	if cfg.Spec.Instance.DataDirs.ContainerdRoot != "" {
+		return cfg.Spec.Instance.DataDirs.ContainerdRoot
+	}
+	return defaultRootDir
+}
+
+// StateDir returns the directory where containerd stores its transient state.
+func StateDir(cfg *api.NodeConfig) string {
+	if cfg.Spec.Instance.DataDirs.ContainerdState != "" {
+		return cfg.Spec.Instance.DataDirs.ContainerdState
+	}
+	return defaultStateDir
 }
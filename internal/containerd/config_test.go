@@ -0,0 +1,164 @@
+package containerd
+
+import (
+	"fmt"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/aws/eks-hybrid/internal/api"
+)
+
+func TestGenerateContainerdConfig(t *testing.T) {
+	testCases := []struct {
+		name         string
+		majorVersion int
+		wantContains []string
+		wantExcludes []string
+	}{
+		{
+			name:         "containerd 1.x",
+			majorVersion: 1,
+			wantContains: []string{
+				"version = 2\n",
+				`root = "/var/lib/containerd"`,
+				`state = "/run/containerd"`,
+				`[plugins."io.containerd.grpc.v1.cri"]`,
+				`sandbox_image = "registry.k8s.io/pause:3.10"`,
+			},
+			wantExcludes: []string{"io.containerd.cri.v1"},
+		},
+		{
+			name:         "containerd 2.x",
+			majorVersion: 2,
+			wantContains: []string{
+				"version = 3\n",
+				`root = "/var/lib/containerd"`,
+				`state = "/run/containerd"`,
+				`[plugins."io.containerd.cri.v1.images".pinned_images]`,
+				`sandbox = "registry.k8s.io/pause:3.10"`,
+				`[plugins."io.containerd.cri.v1.runtime".containerd.runtimes.runc.options]`,
+			},
+			wantExcludes: []string{"io.containerd.grpc.v1.cri", "sandbox_image"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			config, err := generateContainerdConfig(&api.NodeConfig{
+				Status: api.NodeConfigStatus{
+					Defaults: api.DefaultOptions{SandboxImage: "registry.k8s.io/pause:3.10"},
+				},
+			}, tc.majorVersion)
+			g.Expect(err).To(Succeed())
+			for _, want := range tc.wantContains {
+				g.Expect(string(config)).To(ContainSubstring(want))
+			}
+			for _, exclude := range tc.wantExcludes {
+				g.Expect(string(config)).NotTo(ContainSubstring(exclude))
+			}
+		})
+	}
+}
+
+func TestGenerateContainerdConfigRuntimes(t *testing.T) {
+	runtimes := []api.ContainerdRuntime{
+		{
+			Name:        "runsc",
+			RuntimeType: "io.containerd.runsc.v1",
+			Options: api.InlineDocument{
+				"TypeUrl":    {Raw: []byte(`"io.containerd.runsc.v1.options"`)},
+				"ConfigPath": {Raw: []byte(`"/etc/containerd/runsc.toml"`)},
+				"Debug":      {Raw: []byte(`true`)},
+				"Timeout":    {Raw: []byte(`30`)},
+				"Ratio":      {Raw: []byte(`0.5`)},
+				"Args":       {Raw: []byte(`["--platform", "kvm"]`)},
+			},
+		},
+		{
+			Name:        "kata",
+			RuntimeType: "io.containerd.kata.v2",
+			BinaryPath:  "/opt/kata/bin/containerd-shim-kata-v2",
+		},
+	}
+	testCases := []struct {
+		name         string
+		majorVersion int
+		want         string
+	}{
+		{
+			name:         "containerd 1.x",
+			majorVersion: 1,
+			want: `    SystemdCgroup = true
+  [plugins."io.containerd.grpc.v1.cri".containerd.runtimes.runsc]
+    runtime_type = "io.containerd.runsc.v1"
+  [plugins."io.containerd.grpc.v1.cri".containerd.runtimes.runsc.options]
+    Args = ["--platform", "kvm"]
+    ConfigPath = "/etc/containerd/runsc.toml"
+    Debug = true
+    Ratio = 0.5
+    Timeout = 30
+    TypeUrl = "io.containerd.runsc.v1.options"
+  [plugins."io.containerd.grpc.v1.cri".containerd.runtimes.kata]
+    runtime_type = "io.containerd.kata.v2"
+    runtime_path = "/opt/kata/bin/containerd-shim-kata-v2"
+  [plugins."io.containerd.grpc.v1.cri".cni]
+`,
+		},
+		{
+			name:         "containerd 2.x",
+			majorVersion: 2,
+			want: `    SystemdCgroup = true
+  [plugins."io.containerd.cri.v1.runtime".containerd.runtimes.runsc]
+    runtime_type = "io.containerd.runsc.v1"
+  [plugins."io.containerd.cri.v1.runtime".containerd.runtimes.runsc.options]
+    Args = ["--platform", "kvm"]
+    ConfigPath = "/etc/containerd/runsc.toml"
+    Debug = true
+    Ratio = 0.5
+    Timeout = 30
+    TypeUrl = "io.containerd.runsc.v1.options"
+  [plugins."io.containerd.cri.v1.runtime".containerd.runtimes.kata]
+    runtime_type = "io.containerd.kata.v2"
+    runtime_path = "/opt/kata/bin/containerd-shim-kata-v2"
+  [plugins."io.containerd.cri.v1.runtime".cni]
+`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			config, err := generateContainerdConfig(&api.NodeConfig{
+				Spec: api.NodeConfigSpec{
+					Containerd: api.ContainerdOptions{Runtimes: runtimes},
+				},
+			}, tc.majorVersion)
+			g.Expect(err).To(Succeed())
+			g.Expect(string(config)).To(ContainSubstring(tc.want))
+		})
+	}
+}
+
+func TestGenerateContainerdConfigDataDirs(t *testing.T) {
+	for _, majorVersion := range []int{1, 2} {
+		t.Run(fmt.Sprintf("containerd %d.x", majorVersion), func(t *testing.T) {
+			g := NewWithT(t)
+			config, err := generateContainerdConfig(&api.NodeConfig{
+				Spec: api.NodeConfigSpec{
+					Instance: api.InstanceOptions{
+						DataDirs: api.DataDirOptions{
+							ContainerdRoot:  "/mnt/data/containerd",
+							ContainerdState: "/mnt/state/containerd",
+						},
+					},
+				},
+			}, majorVersion)
+			g.Expect(err).To(Succeed())
+			g.Expect(string(config)).To(ContainSubstring("root = \"/mnt/data/containerd\"\nstate = \"/mnt/state/containerd\"\n"))
+			// the socket stays in the default location for kubelet and crictl
+			g.Expect(string(config)).To(ContainSubstring(`address = "/run/containerd/containerd.sock"`))
+		})
+	}
+}
@@ -10,6 +10,7 @@ import (
 
 	"github.com/aws/eks-hybrid/internal/api"
 	"github.com/aws/eks-hybrid/internal/daemon"
+	"github.com/aws/eks-hybrid/internal/proxy"
 )
 
 const (
@@ -17,7 +18,11 @@ const (
 	kernelModulesSystemdUnit = "systemd-modules-load"
 )
 
-var _ daemon.Daemon = &containerd{}
+var (
+	_ daemon.Daemon            = &containerd{}
+	_ daemon.ConfigFilesLister = &containerd{}
+	_ daemon.ConfigRenderer    = &containerd{}
+)
 
 type containerd struct {
 	daemonManager daemon.DaemonManager
@@ -36,16 +41,62 @@ func NewContainerdDaemon(daemonManager daemon.DaemonManager, cfg *api.NodeConfig
 }
 
 func (cd *containerd) Configure() error {
+	if err := checkRuntimeBinaries(cd.nodeConfig); err != nil {
+		return err
+	}
 	if err := writeContainerdConfig(cd.nodeConfig); err != nil {
 		return err
 	}
-	return writeContainerdKernelModulesConfig()
+	if err := writeContainerdHostsConfig(cd.nodeConfig); err != nil {
+		return err
+	}
+	if err := proxy.WriteDropIn(ContainerdDaemonName, cd.nodeConfig); err != nil {
+		return err
+	}
+	return writeContainerdKernelModulesConfig(cd.nodeConfig)
+}
+
+func (cd *containerd) RenderConfig() (map[string][]byte, error) {
+	if err := checkRuntimeBinaries(cd.nodeConfig); err != nil {
+		return nil, err
+	}
+	files, err := renderContainerdConfig(cd.nodeConfig)
+	if err != nil {
+		return nil, err
+	}
+	rendered, err := renderContainerdHostsConfig(cd.nodeConfig)
+	if err != nil {
+		return nil, err
+	}
+	for _, file := range files {
+		rendered[file.path] = file.content
+	}
+	dropIn, err := proxy.RenderDropIn(cd.nodeConfig)
+	if err != nil {
+		return nil, err
+	}
+	rendered[proxy.DropInPath(ContainerdDaemonName)] = dropIn
+	rendered[containerdKernelModulesConfigFile] = renderContainerdKernelModulesConfig(cd.nodeConfig)
+	return rendered, nil
+}
+
+func (cd *containerd) ConfigFiles() []string {
+	files := []string{
+		containerdConfigFile,
+		containerdConfigImportFile,
+		containerdKernelModulesConfigFile,
+		proxy.DropInPath(ContainerdDaemonName),
+	}
+	return append(files, hostsConfigFiles(cd.nodeConfig)...)
 }
 
 // EnsureRunning ensures containerd is running with the written configuration
 // With some installations, containerd daemon is already in an running state
 // This enables the daemon and restarts or starts depending on the state of daemon
 func (cd *containerd) EnsureRunning(ctx context.Context) error {
+	if err := cd.daemonManager.DaemonReload(); err != nil {
+		return err
+	}
 	if err := cd.daemonManager.RestartDaemon(ctx, kernelModulesSystemdUnit); err != nil {
 		return err
 	}
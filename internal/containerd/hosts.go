@@ -0,0 +1,417 @@
+package containerd
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"text/template"
+
+	"go.uber.org/zap"
+
+	"github.com/aws/eks-hybrid/internal/api"
+	"github.com/aws/eks-hybrid/internal/trust"
+)
+
+const (
+	containerdCertsDir  = "/etc/containerd/certs.d"
+	hostsFileName       = "hosts.toml"
+	defaultRegistryHost = "_default"
+	// hostsAuthFilePerm keeps hosts.toml files with registry credentials
+	// readable only by root.
+	hostsAuthFilePerm = 0o600
+	// managedHostsHeader marks the hosts.toml files written by nodeadm, so
+	// that files provided by users are never overwritten or removed.
+	managedHostsHeader = "# Managed by nodeadm"
+)
+
+var (
+	//go:embed hosts.template.toml
+	containerdHostsTemplateData string
+	containerdHostsTemplate     = template.Must(template.New(hostsFileName).Funcs(template.FuncMap{
+		"quote":     tomlQuote,
+		"quoteList": tomlQuoteList,
+	}).Parse(containerdHostsTemplateData))
+
+	bareKeyRegex = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+	// defaultMirrorCapabilities only allows pulls, since mirrors are usually
+	// read-only pull-through caches.
+	defaultMirrorCapabilities = []api.RegistryCapability{api.RegistryCapabilityPull, api.RegistryCapabilityResolve}
+	validRegistryCapabilities = []api.RegistryCapability{api.RegistryCapabilityPull, api.RegistryCapabilityResolve, api.RegistryCapabilityPush}
+)
+
+type hostsTemplateVars struct {
+	Comment string
+	Server  string
+	CA      []string
+	Hosts   []hostTemplateVars
+}
+
+type hostTemplateVars struct {
+	URL           string
+	Capabilities  []string
+	CA            []string
+	SkipVerify    bool
+	Authorization string
+}
+
+// validateRegistries checks that the registries in the containerd options can be
+// rendered as valid hosts.toml files.
+func validateRegistries(cfg *api.NodeConfig) error {
+	seen := map[string]bool{}
+	for _, registry := range cfg.Spec.Containerd.Registries {
+		if err := validateRegistryHost(registry.Host); err != nil {
+			return err
+		}
+		if seen[registry.Host] {
+			return fmt.Errorf("containerd registry %q is configured more than once", registry.Host)
+		}
+		seen[registry.Host] = true
+		if registry.Server != "" {
+			if err := validateRegistryURL(registry.Server); err != nil {
+				return fmt.Errorf("invalid server for containerd registry %q: %w", registry.Host, err)
+			}
+		}
+		for _, mirror := range registry.Mirrors {
+			if err := validateRegistryMirror(mirror); err != nil {
+				return fmt.Errorf("invalid mirror for containerd registry %q: %w", registry.Host, err)
+			}
+		}
+	}
+	return nil
+}
+
+func validateRegistryHost(host string) error {
+	if host == "" {
+		return fmt.Errorf("containerd registry host can't be empty")
+	}
+	if host == defaultRegistryHost {
+		return nil
+	}
+	if host == "." || host == ".." || strings.ContainsAny(host, "/\\ \t\n") {
+		return fmt.Errorf("invalid containerd registry host %q: must be a host name with an optional port, such as registry.example.com:5000", host)
+	}
+	return nil
+}
+
+func validateRegistryURL(rawURL string) error {
+	registryURL, err := url.Parse(rawURL)
+	if err != nil {
+		return err
+	}
+	if (registryURL.Scheme != "http" && registryURL.Scheme != "https") || registryURL.Host == "" {
+		return fmt.Errorf("%q must be an http or https URL", rawURL)
+	}
+	return nil
+}
+
+func validateRegistryMirror(mirror api.RegistryMirror) error {
+	if mirror.Endpoint == "" {
+		return fmt.Errorf("endpoint can't be empty")
+	}
+	if err := validateRegistryURL(mirror.Endpoint); err != nil {
+		return err
+	}
+	for _, capability := range mirror.Capabilities {
+		if !slices.Contains(validRegistryCapabilities, capability) {
+			return fmt.Errorf("mirror %s has unknown capability %q, must be one of %v", mirror.Endpoint, capability, validRegistryCapabilities)
+		}
+	}
+	for _, caFile := range mirror.CAFiles {
+		if caFile == "" {
+			return fmt.Errorf("mirror %s has an empty CA file path", mirror.Endpoint)
+		}
+	}
+	if mirror.Auth != nil && (mirror.Auth.Username == "" || mirror.Auth.Password == "") {
+		return fmt.Errorf("mirror %s auth requires both username and password", mirror.Endpoint)
+	}
+	return nil
+}
+
+// generateHostsConfigs renders the hosts.toml files for the node, keyed by
+// registry host. When additional CA bundles are configured, they are trusted by
+// every registry and a default host configuration is added for registries that
+// have none of their own.
+func generateHostsConfigs(cfg *api.NodeConfig) (map[string][]byte, error) {
+	var trustedCAs []string
+	if len(cfg.Spec.Trust.AdditionalCABundles) > 0 {
+		trustedCAs = []string{trust.BundlePath}
+	}
+
+	hostsConfigs := map[string][]byte{}
+	for _, registry := range cfg.Spec.Containerd.Registries {
+		vars := hostsTemplateVars{
+			Comment: managedHostsHeader + " from spec.containerd.registries.",
+			Server:  registry.Server,
+			CA:      trustedCAs,
+		}
+		for _, mirror := range registry.Mirrors {
+			host := hostTemplateVars{
+				URL:        mirror.Endpoint,
+				CA:         append(slices.Clone(mirror.CAFiles), trustedCAs...),
+				SkipVerify: mirror.SkipVerify,
+			}
+			capabilities := mirror.Capabilities
+			if len(capabilities) == 0 {
+				capabilities = defaultMirrorCapabilities
+			}
+			for _, capability := range capabilities {
+				host.Capabilities = append(host.Capabilities, string(capability))
+			}
+			if mirror.Auth != nil {
+				credentials := mirror.Auth.Username + ":" + mirror.Auth.Password
+				host.Authorization = "Basic " + base64.StdEncoding.EncodeToString([]byte(credentials))
+			}
+			vars.Hosts = append(vars.Hosts, host)
+		}
+		hostsConfig, err := executeHostsTemplate(vars)
+		if err != nil {
+			return nil, err
+		}
+		hostsConfigs[registry.Host] = hostsConfig
+	}
+
+	if _, ok := hostsConfigs[defaultRegistryHost]; !ok && len(trustedCAs) > 0 {
+		hostsConfig, err := executeHostsTemplate(hostsTemplateVars{
+			Comment: managedHostsHeader + ". Registries without their own hosts.toml trust the additional CA bundles from spec.trust.",
+			CA:      trustedCAs,
+		})
+		if err != nil {
+			return nil, err
+		}
+		hostsConfigs[defaultRegistryHost] = hostsConfig
+	}
+	return hostsConfigs, nil
+}
+
+func executeHostsTemplate(vars hostsTemplateVars) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := containerdHostsTemplate.Execute(&buf, vars); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// writeContainerdHostsConfig writes the hosts.toml files for the node's
+// registries and removes the ones written by a previous run that are no longer
+// configured. Files provided by the user are never modified.
+func writeContainerdHostsConfig(cfg *api.NodeConfig) error {
+	hostsConfigs, err := generateHostsConfigs(cfg)
+	if err != nil {
+		return err
+	}
+	managed, err := managedHostsFiles()
+	if err != nil {
+		return err
+	}
+	for _, hostsFile := range managed {
+		if _, ok := hostsConfigs[filepath.Base(filepath.Dir(hostsFile))]; ok {
+			continue
+		}
+		zap.L().Info("Removing containerd registry host config..", zap.String("path", hostsFile))
+		if err := removeHostsFile(hostsFile); err != nil {
+			return err
+		}
+	}
+	for _, host := range sortedHosts(hostsConfigs) {
+		hostsFile := hostsFilePath(host)
+		if managed, err := isManagedHostsFile(hostsFile); err != nil {
+			return err
+		} else if !managed {
+			zap.L().Warn("Not writing containerd registry host config, the file is provided by the user", zap.String("path", hostsFile))
+			continue
+		}
+		zap.L().Info("Writing containerd registry host config to file..", zap.String("path", hostsFile))
+		if err := os.MkdirAll(path.Dir(hostsFile), 0o755); err != nil {
+			return err
+		}
+		perm := os.FileMode(containerdConfigPerm)
+		if registryHasAuth(cfg, host) {
+			perm = hostsAuthFilePerm
+		}
+		if err := os.WriteFile(hostsFile, hostsConfigs[host], perm); err != nil {
+			return err
+		}
+		// WriteFile keeps the mode of existing files
+		if err := os.Chmod(hostsFile, perm); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// renderContainerdHostsConfig returns the hosts.toml files
+// writeContainerdHostsConfig writes, with a nil content for the ones it removes.
+func renderContainerdHostsConfig(cfg *api.NodeConfig) (map[string][]byte, error) {
+	hostsConfigs, err := generateHostsConfigs(cfg)
+	if err != nil {
+		return nil, err
+	}
+	managed, err := managedHostsFiles()
+	if err != nil {
+		return nil, err
+	}
+	rendered := map[string][]byte{}
+	for _, hostsFile := range managed {
+		if _, ok := hostsConfigs[filepath.Base(filepath.Dir(hostsFile))]; !ok {
+			rendered[hostsFile] = nil
+		}
+	}
+	for host, hostsConfig := range hostsConfigs {
+		hostsFile := hostsFilePath(host)
+		if managed, err := isManagedHostsFile(hostsFile); err != nil {
+			return nil, err
+		} else if managed {
+			rendered[hostsFile] = hostsConfig
+		}
+	}
+	return rendered, nil
+}
+
+// registryHasAuth returns true when a mirror of the registry has credentials,
+// which are written to its hosts.toml.
+func registryHasAuth(cfg *api.NodeConfig, host string) bool {
+	for _, registry := range cfg.Spec.Containerd.Registries {
+		if registry.Host != host {
+			continue
+		}
+		for _, mirror := range registry.Mirrors {
+			if mirror.Auth != nil {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// hostsConfigFiles returns the hosts.toml files nodeadm writes for the node or
+// wrote during a previous run. Files provided by the user are left out.
+func hostsConfigFiles(cfg *api.NodeConfig) []string {
+	var hostsFiles []string
+	for _, registry := range cfg.Spec.Containerd.Registries {
+		hostsFiles = append(hostsFiles, hostsFilePath(registry.Host))
+	}
+	hostsFiles = append(hostsFiles, hostsFilePath(defaultRegistryHost))
+	hostsFiles = slices.DeleteFunc(hostsFiles, func(hostsFile string) bool {
+		managed, err := isManagedHostsFile(hostsFile)
+		return err != nil || !managed
+	})
+	if managed, err := managedHostsFiles(); err == nil {
+		hostsFiles = append(hostsFiles, managed...)
+	}
+	slices.Sort(hostsFiles)
+	return slices.Compact(hostsFiles)
+}
+
+// RemoveHostsConfig removes the registry host configuration written by nodeadm.
+// Configuration provided by the user is left in place.
+func RemoveHostsConfig() error {
+	managed, err := managedHostsFiles()
+	if err != nil {
+		return err
+	}
+	for _, hostsFile := range managed {
+		if err := removeHostsFile(hostsFile); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func managedHostsFiles() ([]string, error) {
+	hostsFiles, err := filepath.Glob(filepath.Join(containerdCertsDir, "*", hostsFileName))
+	if err != nil {
+		return nil, err
+	}
+	var managed []string
+	for _, hostsFile := range hostsFiles {
+		if ok, err := isManagedHostsFile(hostsFile); err != nil {
+			return nil, err
+		} else if ok {
+			managed = append(managed, hostsFile)
+		}
+	}
+	return managed, nil
+}
+
+// removeHostsFile removes a hosts.toml file and its registry directory, if
+// nothing else is left in it.
+func removeHostsFile(hostsFile string) error {
+	if err := os.Remove(hostsFile); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if entries, err := os.ReadDir(path.Dir(hostsFile)); err == nil && len(entries) == 0 {
+		return os.Remove(path.Dir(hostsFile))
+	}
+	return nil
+}
+
+// isManagedHostsFile returns true if the hosts file was written by nodeadm or
+// doesn't exist.
+func isManagedHostsFile(hostsFile string) (bool, error) {
+	content, err := os.ReadFile(hostsFile)
+	if os.IsNotExist(err) {
+		return true, nil
+	} else if err != nil {
+		return false, err
+	}
+	return bytes.HasPrefix(content, []byte(managedHostsHeader)), nil
+}
+
+func hostsFilePath(host string) string {
+	return path.Join(containerdCertsDir, host, hostsFileName)
+}
+
+func sortedHosts(hostsConfigs map[string][]byte) []string {
+	hosts := make([]string, 0, len(hostsConfigs))
+	for host := range hostsConfigs {
+		hosts = append(hosts, host)
+	}
+	sort.Strings(hosts)
+	return hosts
+}
+
+// tomlQuote returns s as a TOML basic string.
+func tomlQuote(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for _, r := range s {
+		switch {
+		case r == '"' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r < 0x20 || r == 0x7f:
+			fmt.Fprintf(&b, `\u%04X`, r)
+		default:
+			b.WriteRune(r)
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+// tomlKey returns s as a TOML key, quoted only if it isn't a valid bare key.
+func tomlKey(s string) string {
+	if bareKeyRegex.MatchString(s) {
+		return s
+	}
+	return tomlQuote(s)
+}
+
+// tomlQuoteList returns values as a TOML array of basic strings.
+func tomlQuoteList(values []string) string {
+	quoted := make([]string, 0, len(values))
+	for _, value := range values {
+		quoted = append(quoted, tomlQuote(value))
+	}
+	return "[" + strings.Join(quoted, ", ") + "]"
+}
@@ -0,0 +1,229 @@
+package containerd
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/aws/eks-hybrid/internal/api"
+)
+
+func TestValidateRegistries(t *testing.T) {
+	testCases := []struct {
+		name       string
+		registries []api.RegistryOptions
+		wantErr    string
+	}{
+		{
+			name: "no registries",
+		},
+		{
+			name: "valid registries",
+			registries: []api.RegistryOptions{
+				{
+					Host:   "docker.io",
+					Server: "https://registry-1.docker.io",
+					Mirrors: []api.RegistryMirror{
+						{
+							Endpoint:     "https://mirror.example.com:5000",
+							Capabilities: []api.RegistryCapability{api.RegistryCapabilityPull},
+							CAFiles:      []string{"/etc/pki/mirror.crt"},
+							Auth:         &api.RegistryAuth{Username: "user", Password: "pass"},
+						},
+					},
+				},
+				{
+					Host: "_default",
+					Mirrors: []api.RegistryMirror{
+						{Endpoint: "http://mirror.example.com"},
+					},
+				},
+			},
+		},
+		{
+			name:       "empty host",
+			registries: []api.RegistryOptions{{}},
+			wantErr:    "containerd registry host can't be empty",
+		},
+		{
+			name:       "host with path",
+			registries: []api.RegistryOptions{{Host: "https://docker.io"}},
+			wantErr:    `invalid containerd registry host "https://docker.io": must be a host name with an optional port, such as registry.example.com:5000`,
+		},
+		{
+			name:       "duplicate host",
+			registries: []api.RegistryOptions{{Host: "docker.io"}, {Host: "docker.io"}},
+			wantErr:    `containerd registry "docker.io" is configured more than once`,
+		},
+		{
+			name:       "invalid server",
+			registries: []api.RegistryOptions{{Host: "docker.io", Server: "registry-1.docker.io"}},
+			wantErr:    `invalid server for containerd registry "docker.io": "registry-1.docker.io" must be an http or https URL`,
+		},
+		{
+			name: "missing mirror endpoint",
+			registries: []api.RegistryOptions{
+				{Host: "docker.io", Mirrors: []api.RegistryMirror{{}}},
+			},
+			wantErr: `invalid mirror for containerd registry "docker.io": endpoint can't be empty`,
+		},
+		{
+			name: "unknown capability",
+			registries: []api.RegistryOptions{
+				{Host: "docker.io", Mirrors: []api.RegistryMirror{{Endpoint: "https://mirror.example.com", Capabilities: []api.RegistryCapability{"delete"}}}},
+			},
+			wantErr: `invalid mirror for containerd registry "docker.io": mirror https://mirror.example.com has unknown capability "delete", must be one of [pull resolve push]`,
+		},
+		{
+			name: "incomplete auth",
+			registries: []api.RegistryOptions{
+				{Host: "docker.io", Mirrors: []api.RegistryMirror{{Endpoint: "https://mirror.example.com", Auth: &api.RegistryAuth{Username: "user"}}}},
+			},
+			wantErr: `invalid mirror for containerd registry "docker.io": mirror https://mirror.example.com auth requires both username and password`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			err := validateRegistries(&api.NodeConfig{
+				Spec: api.NodeConfigSpec{
+					Containerd: api.ContainerdOptions{Registries: tc.registries},
+				},
+			})
+			if tc.wantErr == "" {
+				g.Expect(err).To(Succeed())
+			} else {
+				g.Expect(err).To(MatchError(tc.wantErr))
+			}
+		})
+	}
+}
+
+func TestGenerateHostsConfigs(t *testing.T) {
+	testCases := []struct {
+		name string
+		spec api.NodeConfigSpec
+		want map[string]string
+	}{
+		{
+			name: "nothing configured",
+			want: map[string]string{},
+		},
+		{
+			name: "additional CA bundles only",
+			spec: api.NodeConfigSpec{
+				Trust: api.TrustOptions{AdditionalCABundles: []string{"bundle"}},
+			},
+			want: map[string]string{
+				"_default": `# Managed by nodeadm. Registries without their own hosts.toml trust the additional CA bundles from spec.trust.
+ca = ["/etc/eks/trust/ca-bundle.crt"]
+`,
+			},
+		},
+		{
+			name: "registry mirrors",
+			spec: api.NodeConfigSpec{
+				Containerd: api.ContainerdOptions{
+					Registries: []api.RegistryOptions{
+						{
+							Host:   "docker.io",
+							Server: "https://registry-1.docker.io",
+							Mirrors: []api.RegistryMirror{
+								{
+									Endpoint: "https://mirror.example.com:5000",
+									CAFiles:  []string{"/etc/pki/mirror.crt"},
+									Auth:     &api.RegistryAuth{Username: "user", Password: `p"ss`},
+								},
+								{
+									Endpoint:     "http://cache.example.com",
+									Capabilities: []api.RegistryCapability{api.RegistryCapabilityPull},
+									SkipVerify:   true,
+								},
+							},
+						},
+						{
+							Host: "public.ecr.aws",
+						},
+					},
+				},
+			},
+			want: map[string]string{
+				"docker.io": `# Managed by nodeadm from spec.containerd.registries.
+server = "https://registry-1.docker.io"
+
+[host."https://mirror.example.com:5000"]
+  capabilities = ["pull", "resolve"]
+  ca = ["/etc/pki/mirror.crt"]
+  [host."https://mirror.example.com:5000".header]
+    Authorization = "Basic dXNlcjpwInNz"
+
+[host."http://cache.example.com"]
+  capabilities = ["pull"]
+  skip_verify = true
+`,
+				"public.ecr.aws": `# Managed by nodeadm from spec.containerd.registries.
+`,
+			},
+		},
+		{
+			name: "registry mirrors with additional CA bundles",
+			spec: api.NodeConfigSpec{
+				Containerd: api.ContainerdOptions{
+					Registries: []api.RegistryOptions{
+						{
+							Host: "_default",
+							Mirrors: []api.RegistryMirror{
+								{Endpoint: "https://mirror.example.com"},
+							},
+						},
+					},
+				},
+				Trust: api.TrustOptions{AdditionalCABundles: []string{"bundle"}},
+			},
+			want: map[string]string{
+				"_default": `# Managed by nodeadm from spec.containerd.registries.
+ca = ["/etc/eks/trust/ca-bundle.crt"]
+
+[host."https://mirror.example.com"]
+  capabilities = ["pull", "resolve"]
+  ca = ["/etc/eks/trust/ca-bundle.crt"]
+`,
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			hostsConfigs, err := generateHostsConfigs(&api.NodeConfig{Spec: tc.spec})
+			g.Expect(err).To(Succeed())
+			got := map[string]string{}
+			for host, hostsConfig := range hostsConfigs {
+				got[host] = string(hostsConfig)
+			}
+			g.Expect(got).To(Equal(tc.want))
+		})
+	}
+}
+
+func TestRegistryHasAuth(t *testing.T) {
+	g := NewWithT(t)
+	cfg := &api.NodeConfig{Spec: api.NodeConfigSpec{
+		Containerd: api.ContainerdOptions{
+			Registries: []api.RegistryOptions{
+				{
+					Host:    "docker.io",
+					Mirrors: []api.RegistryMirror{{Endpoint: "https://mirror.example.com", Auth: &api.RegistryAuth{Username: "user", Password: "pass"}}},
+				},
+				{
+					Host:    "quay.io",
+					Mirrors: []api.RegistryMirror{{Endpoint: "https://quay-mirror.example.com"}},
+				},
+			},
+		},
+	}}
+	g.Expect(registryHasAuth(cfg, "docker.io")).To(BeTrue())
+	g.Expect(registryHasAuth(cfg, "quay.io")).To(BeFalse())
+	g.Expect(registryHasAuth(cfg, "_default")).To(BeFalse())
+}
@@ -1,10 +1,12 @@
 package containerd
 
 import (
+	"cmp"
 	"context"
 	"fmt"
 	"os"
 	"os/exec"
+	"strconv"
 	"time"
 
 	"github.com/pkg/errors"
@@ -22,8 +24,6 @@ const (
 	ContainerdSourceNone   SourceName = "none"
 	ContainerdSourceDistro SourceName = "distro"
 	ContainerdSourceDocker SourceName = "docker"
-	// pin containerd to major version 1.x
-	ContainerdVersion = "1.*"
 
 	containerdPackageName = "containerd"
 	runcPackageName       = "runc"
@@ -34,12 +34,21 @@ type Source interface {
 	GetContainerd(version string) artifact.Package
 }
 
-func Install(ctx context.Context, tracker *tracker.Tracker, source Source, containerdSource SourceName) error {
+// Install installs the requested containerd major version from source, or
+// DefaultMajorVersion when none is requested, unless containerd is already
+// installed. It fails when the installed containerd has a different major
+// version than the requested one.
+func Install(ctx context.Context, tracker *tracker.Tracker, source Source, containerdSource SourceName, majorVersion string) error {
 	if containerdSource == ContainerdSourceNone {
+		// record that containerd is not managed by nodeadm, so it's not
+		// upgraded or uninstalled later
+		if tracker.Artifacts.Containerd == "" {
+			tracker.MarkContainerd(string(ContainerdSourceNone))
+		}
 		return nil
 	}
 	if isContainerdNotInstalled() {
-		containerd := source.GetContainerd(ContainerdVersion)
+		containerd := source.GetContainerd(packageVersion(cmp.Or(majorVersion, DefaultMajorVersion)))
 		// Sometimes install fails due to conflicts with other processes
 		// updating packages, specially when automating at machine startup.
 		// We assume errors are transient and just retry for a bit.
@@ -47,13 +56,25 @@ func Install(ctx context.Context, tracker *tracker.Tracker, source Source, conta
 			return errors.Wrap(err, "failed to install containerd")
 		}
 		tracker.MarkContainerd(string(containerdSource))
+		return nil
 	}
-	return nil
+	if majorVersion == "" {
+		return nil
+	}
+	installed, err := installedMajorVersion()
+	if err != nil {
+		return err
+	}
+	return validateInstalledMajorVersion(installed, majorVersion)
 }
 
 func Uninstall(ctx context.Context, source Source) error {
 	if isContainerdInstalled() {
-		containerd := source.GetContainerd(ContainerdVersion)
+		version, err := installedPackageVersion()
+		if err != nil {
+			return err
+		}
+		containerd := source.GetContainerd(version)
 		if err := cmd.Retry(ctx, containerd.UninstallCmd, 5*time.Second); err != nil {
 			return errors.Wrap(err, "failed to uninstall containerd")
 		}
@@ -65,8 +86,13 @@ func Uninstall(ctx context.Context, source Source) error {
 	return nil
 }
 
+// Upgrade upgrades containerd within its installed major version.
 func Upgrade(ctx context.Context, source Source) error {
-	containerd := source.GetContainerd(ContainerdVersion)
+	version, err := installedPackageVersion()
+	if err != nil {
+		return err
+	}
+	containerd := source.GetContainerd(version)
 	if err := cmd.Retry(ctx, containerd.UpgradeCmd, 5*time.Second); err != nil {
 		return errors.Wrap(err, "upgrading containerd")
 	}
@@ -127,3 +153,13 @@ func isContainerdNotInstalled() bool {
 	_, runcNotFoundErr := exec.LookPath(runcPackageName)
 	return containerdNotFoundErr != nil || runcNotFoundErr != nil
 }
+
+// installedPackageVersion returns the package version pattern for the installed
+// containerd major version, so upgrades never cross a major version.
+func installedPackageVersion() (string, error) {
+	majorVersion, err := installedMajorVersion()
+	if err != nil {
+		return "", err
+	}
+	return packageVersion(strconv.Itoa(majorVersion)), nil
+}
@@ -0,0 +1,157 @@
+package containerd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	k8sruntime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	"github.com/aws/eks-hybrid/internal/api"
+	"github.com/aws/eks-hybrid/internal/cri"
+	internalvalidation "github.com/aws/eks-hybrid/internal/validation"
+)
+
+// defaultRuntimeName is the runtime handler nodeadm always configures.
+const defaultRuntimeName = "runc"
+
+// validateRuntimes checks that the runtimes in the containerd options can be
+// rendered as runtime handlers of the CRI plugin.
+func validateRuntimes(cfg *api.NodeConfig) error {
+	seen := map[string]bool{defaultRuntimeName: true}
+	for _, runtime := range cfg.Spec.Containerd.Runtimes {
+		if errs := validation.IsDNS1123Label(runtime.Name); len(errs) > 0 {
+			return fmt.Errorf("invalid containerd runtime name %q: %s", runtime.Name, strings.Join(errs, ", "))
+		}
+		if seen[runtime.Name] {
+			return fmt.Errorf("containerd runtime %q is configured more than once", runtime.Name)
+		}
+		seen[runtime.Name] = true
+		if shimBinaryName(runtime.RuntimeType) == "" {
+			return fmt.Errorf("invalid runtime type %q for containerd runtime %q: must be a shim name such as io.containerd.runsc.v1", runtime.RuntimeType, runtime.Name)
+		}
+		if runtime.BinaryPath != "" && !filepath.IsAbs(runtime.BinaryPath) {
+			return fmt.Errorf("binary path %q for containerd runtime %q must be absolute", runtime.BinaryPath, runtime.Name)
+		}
+		for name, value := range runtime.Options {
+			if _, err := decodeOptionValue(value); err != nil {
+				return fmt.Errorf("invalid option %q for containerd runtime %q: %w", name, runtime.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// tomlValue encodes a runtime option of the node config as a TOML value of
+// the same type, so shims get booleans and numbers instead of strings.
+func tomlValue(raw k8sruntime.RawExtension) (string, error) {
+	value, err := decodeOptionValue(raw)
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	if err := toml.NewEncoder(&buf).Encode(map[string]any{"value": value}); err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(strings.TrimPrefix(buf.String(), "value = ")), nil
+}
+
+// decodeOptionValue decodes a runtime option, a JSON value, to a string,
+// integer, float, boolean or array. Objects aren't supported since they would
+// need their own table in the containerd config.
+func decodeOptionValue(raw k8sruntime.RawExtension) (any, error) {
+	decoder := json.NewDecoder(bytes.NewReader(raw.Raw))
+	decoder.UseNumber()
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+	return tomlTyped(value)
+}
+
+func tomlTyped(value any) (any, error) {
+	switch v := value.(type) {
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return i, nil
+		}
+		return v.Float64()
+	case []any:
+		for i := range v {
+			typed, err := tomlTyped(v[i])
+			if err != nil {
+				return nil, err
+			}
+			v[i] = typed
+		}
+		return v, nil
+	case string, bool:
+		return v, nil
+	default:
+		return nil, errors.New("must be a string, number, boolean or array")
+	}
+}
+
+// checkRuntimeBinaries checks that the shim of every configured runtime is
+// installed, since containerd only fails when the first pod is created.
+func checkRuntimeBinaries(cfg *api.NodeConfig) error {
+	for _, runtime := range cfg.Spec.Containerd.Runtimes {
+		binary := runtime.BinaryPath
+		if binary == "" {
+			binary = shimBinaryName(runtime.RuntimeType)
+		}
+		if _, err := exec.LookPath(binary); err != nil {
+			return fmt.Errorf("shim for containerd runtime %q not found: %w", runtime.Name, err)
+		}
+	}
+	return nil
+}
+
+// shimBinaryName returns the binary containerd runs for a runtime type, which
+// is named after its last two components: io.containerd.runsc.v1 runs
+// containerd-shim-runsc-v1.
+func shimBinaryName(runtimeType string) string {
+	parts := strings.Split(runtimeType, ".")
+	if len(parts) < 2 || slices.Contains(parts, "") {
+		return ""
+	}
+	return fmt.Sprintf("containerd-shim-%s-%s", parts[len(parts)-2], parts[len(parts)-1])
+}
+
+// ValidateRuntimeHandlers checks that the runtimes from the node config are
+// registered in the containerd CRI plugin.
+func ValidateRuntimeHandlers(ctx context.Context, informer internalvalidation.Informer, cfg *api.NodeConfig) error {
+	if len(cfg.Spec.Containerd.Runtimes) == 0 {
+		return nil
+	}
+	name := "containerd-runtime-handlers"
+	var err error
+	informer.Starting(ctx, name, "Validating containerd runtime handlers are registered")
+	defer func() {
+		informer.Done(ctx, name, err)
+	}()
+
+	handlers, err := cri.RuntimeHandlers(ctx, ContainerRuntimeEndpoint)
+	if err != nil {
+		err = internalvalidation.WithRemediation(err, "Ensure containerd is running on the node.")
+		return err
+	}
+	for _, runtime := range cfg.Spec.Containerd.Runtimes {
+		if !slices.Contains(handlers, runtime.Name) {
+			err = internalvalidation.WithRemediation(
+				fmt.Errorf("runtime handler %s is not registered in containerd", runtime.Name),
+				"Run nodeadm init or nodeadm reconfigure to configure containerd with the runtimes from the node configuration, and check the containerd logs for errors loading the runtime.",
+			)
+			return err
+		}
+	}
+	return nil
+}
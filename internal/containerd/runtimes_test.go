@@ -0,0 +1,89 @@
+package containerd
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/aws/eks-hybrid/internal/api"
+)
+
+func TestValidateRuntimes(t *testing.T) {
+	testCases := []struct {
+		name     string
+		runtimes []api.ContainerdRuntime
+		wantErr  string
+	}{
+		{
+			name: "no runtimes",
+		},
+		{
+			name: "valid runtimes",
+			runtimes: []api.ContainerdRuntime{
+				{Name: "runsc", RuntimeType: "io.containerd.runsc.v1"},
+				{Name: "kata", RuntimeType: "io.containerd.kata.v2", BinaryPath: "/opt/kata/bin/containerd-shim-kata-v2"},
+			},
+		},
+		{
+			name:     "invalid name",
+			runtimes: []api.ContainerdRuntime{{Name: "gVisor", RuntimeType: "io.containerd.runsc.v1"}},
+			wantErr:  `invalid containerd runtime name "gVisor": a lowercase RFC 1123 label must consist of lower case alphanumeric characters or '-', and must start and end with an alphanumeric character (e.g. 'my-name',  or '123-abc', regex used for validation is '[a-z0-9]([-a-z0-9]*[a-z0-9])?')`,
+		},
+		{
+			name:     "default runtime",
+			runtimes: []api.ContainerdRuntime{{Name: "runc", RuntimeType: "io.containerd.runc.v2"}},
+			wantErr:  `containerd runtime "runc" is configured more than once`,
+		},
+		{
+			name: "duplicate name",
+			runtimes: []api.ContainerdRuntime{
+				{Name: "runsc", RuntimeType: "io.containerd.runsc.v1"},
+				{Name: "runsc", RuntimeType: "io.containerd.runsc.v1"},
+			},
+			wantErr: `containerd runtime "runsc" is configured more than once`,
+		},
+		{
+			name:     "invalid runtime type",
+			runtimes: []api.ContainerdRuntime{{Name: "runsc", RuntimeType: "runsc"}},
+			wantErr:  `invalid runtime type "runsc" for containerd runtime "runsc": must be a shim name such as io.containerd.runsc.v1`,
+		},
+		{
+			name:     "relative binary path",
+			runtimes: []api.ContainerdRuntime{{Name: "kata", RuntimeType: "io.containerd.kata.v2", BinaryPath: "bin/containerd-shim-kata-v2"}},
+			wantErr:  `binary path "bin/containerd-shim-kata-v2" for containerd runtime "kata" must be absolute`,
+		},
+		{
+			name: "table option",
+			runtimes: []api.ContainerdRuntime{{
+				Name:        "runsc",
+				RuntimeType: "io.containerd.runsc.v1",
+				Options:     api.InlineDocument{"Config": {Raw: []byte(`{"debug": true}`)}},
+			}},
+			wantErr: `invalid option "Config" for containerd runtime "runsc": must be a string, number, boolean or array`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			err := validateRuntimes(&api.NodeConfig{
+				Spec: api.NodeConfigSpec{
+					Containerd: api.ContainerdOptions{Runtimes: tc.runtimes},
+				},
+			})
+			if tc.wantErr == "" {
+				g.Expect(err).To(Succeed())
+			} else {
+				g.Expect(err).To(MatchError(tc.wantErr))
+			}
+		})
+	}
+}
+
+func TestShimBinaryName(t *testing.T) {
+	g := NewWithT(t)
+	g.Expect(shimBinaryName("io.containerd.runsc.v1")).To(Equal("containerd-shim-runsc-v1"))
+	g.Expect(shimBinaryName("io.containerd.kata.v2")).To(Equal("containerd-shim-kata-v2"))
+	g.Expect(shimBinaryName("runsc")).To(BeEmpty())
+	g.Expect(shimBinaryName("io.containerd..v1")).To(BeEmpty())
+}
@@ -4,18 +4,17 @@ import (
 	"fmt"
 	"os/exec"
 	"regexp"
-	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
-	"github.com/containerd/containerd/integration/remote"
 	"go.uber.org/zap"
-	v1 "k8s.io/cri-api/pkg/apis/runtime/v1"
 
-	"github.com/aws/eks-hybrid/internal/aws/ecr"
-	"github.com/aws/eks-hybrid/internal/util"
+	"github.com/aws/eks-hybrid/internal/cri"
 )
 
-var containerdSandboxImageRegex = regexp.MustCompile(`sandbox_image = "(.*)"`)
+// containerdSandboxImageRegex matches the sandbox image in the output of `containerd config dump`,
+// which is `sandbox_image` of the CRI plugin in containerd 1.x and `sandbox` of the CRI images
+// plugin's pinned images in containerd 2.x, quoted with single quotes.
+var containerdSandboxImageRegex = regexp.MustCompile(`(?m)^\s*(?:sandbox_image|sandbox) = ["'](.*)["']`)
 
 func cacheSandboxImage(awsConfig *aws.Config) error {
 	zap.L().Info("Looking up current sandbox image in containerd config..")
@@ -32,26 +31,5 @@ func cacheSandboxImage(awsConfig *aws.Config) error {
 	sandboxImage := string(matches[1])
 	zap.L().Info("Found sandbox image", zap.String("image", sandboxImage))
 
-	zap.L().Info("Fetching ECR authorization token..")
-	ecrUserToken, err := ecr.GetAuthorizationToken(awsConfig)
-	if err != nil {
-		return err
-	}
-
-	client, err := remote.NewImageService(ContainerRuntimeEndpoint, 5*time.Second)
-	if err != nil {
-		return err
-	}
-	imageSpec := &v1.ImageSpec{Image: sandboxImage}
-	authConfig := &v1.AuthConfig{Auth: ecrUserToken}
-
-	return util.RetryExponentialBackoff(3, 2*time.Second, func() error {
-		zap.L().Info("Pulling sandbox image..", zap.String("image", sandboxImage))
-		imageRef, err := client.PullImage(imageSpec, authConfig, nil)
-		if err != nil {
-			return err
-		}
-		zap.L().Info("Finished pulling sandbox image", zap.String("image-ref", imageRef))
-		return nil
-	})
+	return cri.PullSandboxImage(awsConfig, ContainerRuntimeEndpoint, sandboxImage)
 }
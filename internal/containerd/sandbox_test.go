@@ -55,3 +55,32 @@ func TestSandboxImageRegex(t *testing.T) {
 	sandboxImage := matches[1]
 	assert.Equal(t, sandboxImage, "registry.k8s.io/pause:3.8")
 }
+
+const containerdV2ConfigDumpFragment = `
+[plugins]
+  [plugins.'io.containerd.cri.v1.images']
+    disable_snapshot_annotations = true
+    discard_unpacked_layers = true
+    image_pull_progress_timeout = '5m0s'
+    max_concurrent_downloads = 3
+    snapshotter = 'overlayfs'
+
+    [plugins.'io.containerd.cri.v1.images'.pinned_images]
+      sandbox = 'registry.k8s.io/pause:3.10'
+
+    [plugins.'io.containerd.cri.v1.images'.registry]
+      config_path = '/etc/containerd/certs.d:/etc/docker/certs.d'
+
+  [plugins.'io.containerd.cri.v1.runtime']
+    [plugins.'io.containerd.cri.v1.runtime'.containerd.runtimes.runc]
+      runtime_type = 'io.containerd.runc.v2'
+      sandboxer = 'podsandbox'
+`
+
+func TestSandboxImageRegexV2(t *testing.T) {
+	matches := containerdSandboxImageRegex.FindStringSubmatch(containerdV2ConfigDumpFragment)
+	if matches == nil {
+		t.Fatalf("sandbox image could not be found in containerd config")
+	}
+	assert.Equal(t, "registry.k8s.io/pause:3.10", matches[1])
+}
@@ -0,0 +1,132 @@
+package containerd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"go.uber.org/zap"
+
+	"github.com/aws/eks-hybrid/internal/api"
+	"github.com/aws/eks-hybrid/internal/util"
+)
+
+// managedConfigKeys are the keys of the generated containerd config that the
+// node depends on. Overriding them in the user config is allowed, but likely
+// breaks the node. Both the version 2 and version 3 paths are listed since the
+// user config can use either.
+var managedConfigKeys = [][]string{
+	{"root"},
+	{"state"},
+	{"grpc", "address"},
+	{"plugins", "io.containerd.grpc.v1.cri", "sandbox_image"},
+	{"plugins", "io.containerd.grpc.v1.cri", "registry", "config_path"},
+	{"plugins", "io.containerd.grpc.v1.cri", "containerd", "runtimes", "runc", "options", "SystemdCgroup"},
+	{"plugins", "io.containerd.grpc.v1.cri", "cni", "bin_dir"},
+	{"plugins", "io.containerd.grpc.v1.cri", "cni", "conf_dir"},
+	{"plugins", "io.containerd.cri.v1.images", "pinned_images", "sandbox"},
+	{"plugins", "io.containerd.cri.v1.images", "registry", "config_path"},
+	{"plugins", "io.containerd.cri.v1.runtime", "containerd", "runtimes", "runc", "options", "SystemdCgroup"},
+	{"plugins", "io.containerd.cri.v1.runtime", "cni", "bin_dir"},
+	{"plugins", "io.containerd.cri.v1.runtime", "cni", "conf_dir"},
+}
+
+// Validate checks the containerd options of the node config. Keys of the user
+// config that override the ones managed by nodeadm are logged as warnings.
+func Validate(cfg *api.NodeConfig) error {
+	if err := validateRegistries(cfg); err != nil {
+		return err
+	}
+	if err := validateRuntimes(cfg); err != nil {
+		return err
+	}
+	overrides, err := userConfigOverrides(cfg.Spec.Containerd.Config)
+	if err != nil {
+		return err
+	}
+	for _, key := range overrides {
+		zap.L().Warn("containerd config overrides a key managed by nodeadm, the node might not work as expected", zap.String("key", key))
+	}
+	return nil
+}
+
+// userConfigOverrides parses the user containerd config and returns the
+// managed keys it sets.
+func userConfigOverrides(userConfig string) ([]string, error) {
+	if userConfig == "" {
+		return nil, nil
+	}
+	var config map[string]any
+	md, err := toml.Decode(userConfig, &config)
+	if err != nil {
+		return nil, fmt.Errorf("invalid containerd config: %w", err)
+	}
+	var overrides []string
+	for _, key := range managedConfigKeys {
+		if md.IsDefined(key...) {
+			overrides = append(overrides, toml.Key(key).String())
+		}
+	}
+	return overrides, nil
+}
+
+type configFile struct {
+	path    string
+	content []byte
+}
+
+type fileState struct {
+	exists  bool
+	content []byte
+}
+
+// stageConfigFiles writes the config files and runs validate. If validate
+// fails, the files are restored to their previous state so containerd is never
+// restarted with a config it can't load.
+func stageConfigFiles(files []configFile, validate func() error) error {
+	previous := make([]fileState, len(files))
+	for i, file := range files {
+		content, err := os.ReadFile(file.path)
+		if err == nil {
+			previous[i] = fileState{exists: true, content: content}
+		} else if !os.IsNotExist(err) {
+			return err
+		}
+	}
+	restore := func() error {
+		var errs []error
+		for i, file := range files {
+			if previous[i].exists {
+				errs = append(errs, util.WriteFileWithDir(file.path, previous[i].content, containerdConfigPerm))
+			} else if err := os.Remove(file.path); err != nil && !os.IsNotExist(err) {
+				errs = append(errs, err)
+			}
+		}
+		return errors.Join(errs...)
+	}
+
+	for _, file := range files {
+		zap.L().Info("Writing containerd config to file..", zap.String("path", file.path))
+		if err := util.WriteFileWithDir(file.path, file.content, containerdConfigPerm); err != nil {
+			return errors.Join(err, restore())
+		}
+	}
+	if err := validate(); err != nil {
+		zap.L().Error("containerd can't load the new config, restoring the previous config files", zap.Error(err))
+		return errors.Join(err, restore())
+	}
+	return nil
+}
+
+// dumpConfig loads the staged containerd config, including its imports, the
+// same way containerd does on start.
+func dumpConfig() error {
+	out, err := exec.Command(containerdPackageName, "--config", containerdConfigFile, "config", "dump").CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("validating containerd config: %w, output: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
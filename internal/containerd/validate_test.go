@@ -0,0 +1,99 @@
+package containerd
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestUserConfigOverrides(t *testing.T) {
+	testCases := []struct {
+		name       string
+		userConfig string
+		want       []string
+		wantErr    string
+	}{
+		{
+			name: "no config",
+		},
+		{
+			name: "no managed keys",
+			userConfig: `
+[plugins."io.containerd.grpc.v1.cri".containerd]
+discard_unpacked_layers = false
+`,
+		},
+		{
+			name: "version 2 managed keys",
+			userConfig: `
+[plugins."io.containerd.grpc.v1.cri"]
+  sandbox_image = "registry.example.com/pause:3.9"
+[plugins."io.containerd.grpc.v1.cri".containerd.runtimes.runc.options]
+  SystemdCgroup = false
+[plugins."io.containerd.grpc.v1.cri".cni]
+  bin_dir = "/usr/libexec/cni"
+`,
+			want: []string{
+				`plugins."io.containerd.grpc.v1.cri".sandbox_image`,
+				`plugins."io.containerd.grpc.v1.cri".containerd.runtimes.runc.options.SystemdCgroup`,
+				`plugins."io.containerd.grpc.v1.cri".cni.bin_dir`,
+			},
+		},
+		{
+			name: "version 3 managed keys",
+			userConfig: `
+version = 3
+[plugins.'io.containerd.cri.v1.images'.pinned_images]
+  sandbox = 'registry.example.com/pause:3.10'
+`,
+			want: []string{`plugins."io.containerd.cri.v1.images".pinned_images.sandbox`},
+		},
+		{
+			name:       "invalid syntax",
+			userConfig: "[plugins.\"io.containerd.grpc.v1.cri\"\nsandbox_image = 1",
+			wantErr:    "invalid containerd config",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			got, err := userConfigOverrides(tc.userConfig)
+			if tc.wantErr != "" {
+				g.Expect(err).To(MatchError(ContainSubstring(tc.wantErr)))
+				return
+			}
+			g.Expect(err).To(Succeed())
+			g.Expect(got).To(Equal(tc.want))
+		})
+	}
+}
+
+func TestStageConfigFiles(t *testing.T) {
+	g := NewWithT(t)
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "config.toml")
+	importPath := filepath.Join(dir, "config.d", "00-nodeadm.toml")
+	g.Expect(os.WriteFile(configPath, []byte("old"), 0o644)).To(Succeed())
+	files := []configFile{
+		{path: configPath, content: []byte("new")},
+		{path: importPath, content: []byte("user")},
+	}
+
+	err := stageConfigFiles(files, func() error {
+		// the staged files are in place while validating
+		g.Expect(os.ReadFile(configPath)).To(BeEquivalentTo("new"))
+		g.Expect(os.ReadFile(importPath)).To(BeEquivalentTo("user"))
+		return errors.New("invalid config")
+	})
+	g.Expect(err).To(MatchError("invalid config"))
+	g.Expect(os.ReadFile(configPath)).To(BeEquivalentTo("old"))
+	g.Expect(importPath).NotTo(BeAnExistingFile())
+
+	g.Expect(stageConfigFiles(files, func() error { return nil })).To(Succeed())
+	g.Expect(os.ReadFile(configPath)).To(BeEquivalentTo("new"))
+	g.Expect(os.ReadFile(importPath)).To(BeEquivalentTo("user"))
+}
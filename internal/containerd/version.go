@@ -0,0 +1,60 @@
+package containerd
+
+import (
+	"fmt"
+	"os/exec"
+	"regexp"
+	"slices"
+	"strconv"
+)
+
+const (
+	// DefaultMajorVersion is the containerd major version installed when none is requested.
+	DefaultMajorVersion = "1"
+)
+
+// SupportedMajorVersions are the containerd major versions nodeadm can install and configure.
+var SupportedMajorVersions = []string{"1", "2"}
+
+// containerdVersionRegex matches the version in the output of `containerd --version`, like
+// `containerd containerd.io 1.7.24 88bf19b` or `containerd github.com/containerd/containerd/v2 v2.0.0 207ad71`.
+var containerdVersionRegex = regexp.MustCompile(`\sv?(\d+)\.\d+\.\d+`)
+
+// ValidateMajorVersion checks that nodeadm supports the containerd major version.
+func ValidateMajorVersion(version string) error {
+	if !slices.Contains(SupportedMajorVersions, version) {
+		return fmt.Errorf("containerd version %q is not supported. Allowed values: %v", version, SupportedMajorVersions)
+	}
+	return nil
+}
+
+// packageVersion returns the package version pattern that pins containerd to a major version.
+func packageVersion(majorVersion string) string {
+	return majorVersion + ".*"
+}
+
+// installedMajorVersion returns the major version of the containerd binary on the host.
+func installedMajorVersion() (int, error) {
+	out, err := exec.Command(containerdPackageName, "--version").Output()
+	if err != nil {
+		return 0, fmt.Errorf("getting containerd version: %w", err)
+	}
+	return parseMajorVersion(string(out))
+}
+
+// validateInstalledMajorVersion fails when the requested major version isn't
+// the one of the containerd already on the host, which nodeadm doesn't replace.
+func validateInstalledMajorVersion(installed int, requested string) error {
+	if strconv.Itoa(installed) != requested {
+		return fmt.Errorf("containerd %d is already installed, it can't be replaced with containerd %s. Uninstall containerd first or use --containerd-version %d", installed, requested, installed)
+	}
+	return nil
+}
+
+func parseMajorVersion(versionOutput string) (int, error) {
+	matches := containerdVersionRegex.FindStringSubmatch(versionOutput)
+	if matches == nil {
+		return 0, fmt.Errorf("containerd version could not be found in %q", versionOutput)
+	}
+	return strconv.Atoi(matches[1])
+}
@@ -0,0 +1,63 @@
+package containerd
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestParseMajorVersion(t *testing.T) {
+	testCases := []struct {
+		name    string
+		output  string
+		want    int
+		wantErr string
+	}{
+		{
+			name:   "docker package",
+			output: "containerd containerd.io 1.7.24 88bf19b2105c8b17560993bee28a01ddc2f97182\n",
+			want:   1,
+		},
+		{
+			name:   "distro package",
+			output: "containerd github.com/containerd/containerd 1.7.12 \n",
+			want:   1,
+		},
+		{
+			name:   "containerd 2",
+			output: "containerd github.com/containerd/containerd/v2 v2.0.0 207ad711eabd375a01713109a8a197d197ff6542\n",
+			want:   2,
+		},
+		{
+			name:    "no version",
+			output:  "containerd\n",
+			wantErr: "containerd version could not be found",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			got, err := parseMajorVersion(tc.output)
+			if tc.wantErr != "" {
+				g.Expect(err).To(MatchError(ContainSubstring(tc.wantErr)))
+				return
+			}
+			g.Expect(err).To(Succeed())
+			g.Expect(got).To(Equal(tc.want))
+		})
+	}
+}
+
+func TestValidateMajorVersion(t *testing.T) {
+	g := NewWithT(t)
+	g.Expect(ValidateMajorVersion("1")).To(Succeed())
+	g.Expect(ValidateMajorVersion("2")).To(Succeed())
+	g.Expect(ValidateMajorVersion("1.7")).To(MatchError(`containerd version "1.7" is not supported. Allowed values: [1 2]`))
+}
+
+func TestValidateInstalledMajorVersion(t *testing.T) {
+	g := NewWithT(t)
+	g.Expect(validateInstalledMajorVersion(2, "2")).To(Succeed())
+	g.Expect(validateInstalledMajorVersion(1, "2")).To(MatchError("containerd 1 is already installed, it can't be replaced with containerd 2. Uninstall containerd first or use --containerd-version 1"))
+}
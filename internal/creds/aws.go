@@ -9,11 +9,13 @@ import (
 
 	"github.com/aws/eks-hybrid/internal/api"
 	"github.com/aws/eks-hybrid/internal/iamrolesanywhere"
+	"github.com/aws/eks-hybrid/internal/trust"
 )
 
 const iamRoleAnywhereProfileName = "hybrid"
 
 func ReadConfig(ctx context.Context, node *api.NodeConfig, opts ...func(*config.LoadOptions) error) (aws.Config, error) {
+	opts = append([]func(*config.LoadOptions) error{trust.WithRootCAs}, opts...)
 	if !node.IsHybridNode() {
 		if node.Spec.Cluster.Region != "" {
 			opts = append(opts, config.WithRegion(node.Spec.Cluster.Region))
@@ -4,6 +4,7 @@ import (
 	"fmt"
 
 	"github.com/aws/eks-hybrid/internal/api"
+	"github.com/aws/eks-hybrid/internal/artifact"
 	"github.com/aws/eks-hybrid/internal/tracker"
 )
 
@@ -42,3 +43,27 @@ func GetCredentialProviderFromInstalledArtifacts(artifacts *tracker.InstalledArt
 	}
 	return "", fmt.Errorf("no credential process found in installed artifacts")
 }
+
+// RequiredArtifacts returns the artifacts the credential provider needs for the node
+// to authenticate with the cluster.
+func (c CredentialProvider) RequiredArtifacts() []string {
+	switch c {
+	case SsmCredentialProvider:
+		return []string{artifact.Ssm, artifact.IamAuthenticator}
+	case IamRolesAnywhereCredentialProvider:
+		return []string{artifact.IamRolesAnywhere, artifact.IamAuthenticator}
+	default:
+		return nil
+	}
+}
+
+// ValidateSelection validates that all the artifacts required by the credential
+// provider are part of the selection.
+func (c CredentialProvider) ValidateSelection(selection artifact.Selection) error {
+	for _, name := range c.RequiredArtifacts() {
+		if !selection.Includes(name) {
+			return fmt.Errorf("component %s is required by credential provider %s and can't be excluded", name, c)
+		}
+	}
+	return nil
+}
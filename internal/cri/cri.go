@@ -0,0 +1,96 @@
+package cri
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/containerd/containerd/integration/remote"
+	"go.uber.org/zap"
+	v1 "k8s.io/cri-api/pkg/apis/runtime/v1"
+
+	"github.com/aws/eks-hybrid/internal/aws/ecr"
+	"github.com/aws/eks-hybrid/internal/tracker"
+	"github.com/aws/eks-hybrid/internal/util"
+)
+
+// Runtime is a container runtime implementing the kubelet's CRI.
+type Runtime string
+
+const (
+	Containerd Runtime = "containerd"
+	Crio       Runtime = "crio"
+
+	// DefaultRuntime is the container runtime installed when none is requested.
+	DefaultRuntime = Containerd
+
+	ContainerdEndpoint = "unix:///run/containerd/containerd.sock"
+	CrioEndpoint       = "unix:///var/run/crio/crio.sock"
+)
+
+// SupportedRuntimes are the container runtimes nodeadm can install and configure.
+var SupportedRuntimes = []string{string(Containerd), string(Crio)}
+
+// ValidateRuntime checks that nodeadm supports the container runtime.
+func ValidateRuntime(runtime string) error {
+	if !slices.Contains(SupportedRuntimes, runtime) {
+		return fmt.Errorf("container runtime %q is not supported. Allowed values: %v", runtime, SupportedRuntimes)
+	}
+	return nil
+}
+
+// Endpoint returns the CRI socket of the container runtime.
+func Endpoint(runtime Runtime) string {
+	if runtime == Crio {
+		return CrioEndpoint
+	}
+	return ContainerdEndpoint
+}
+
+// FromTracker returns the container runtime recorded in the tracker. Nodes
+// installed before the runtime was tracked use containerd.
+func FromTracker(artifacts *tracker.InstalledArtifacts) Runtime {
+	if artifacts == nil || artifacts.Runtime == "" {
+		return DefaultRuntime
+	}
+	return Runtime(artifacts.Runtime)
+}
+
+// InstalledRuntime returns the container runtime selected during install. If
+// nodeadm didn't install the node, containerd is assumed.
+func InstalledRuntime() Runtime {
+	installed, err := tracker.GetInstalledArtifacts()
+	if err != nil {
+		return DefaultRuntime
+	}
+	return FromTracker(installed.Artifacts)
+}
+
+// PullSandboxImage pulls the sandbox image through the CRI image service at
+// endpoint, authenticating with an ECR token so the image is cached before
+// the kubelet creates any pod.
+func PullSandboxImage(awsConfig *aws.Config, endpoint, sandboxImage string) error {
+	zap.L().Info("Fetching ECR authorization token..")
+	ecrUserToken, err := ecr.GetAuthorizationToken(awsConfig)
+	if err != nil {
+		return err
+	}
+
+	client, err := remote.NewImageService(endpoint, 5*time.Second)
+	if err != nil {
+		return err
+	}
+	imageSpec := &v1.ImageSpec{Image: sandboxImage}
+	authConfig := &v1.AuthConfig{Auth: ecrUserToken}
+
+	return util.RetryExponentialBackoff(3, 2*time.Second, func() error {
+		zap.L().Info("Pulling sandbox image..", zap.String("image", sandboxImage))
+		imageRef, err := client.PullImage(imageSpec, authConfig, nil)
+		if err != nil {
+			return err
+		}
+		zap.L().Info("Finished pulling sandbox image", zap.String("image-ref", imageRef))
+		return nil
+	})
+}
@@ -0,0 +1,53 @@
+package cri_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/aws/eks-hybrid/internal/cri"
+	"github.com/aws/eks-hybrid/internal/tracker"
+)
+
+func TestValidateRuntime(t *testing.T) {
+	g := NewWithT(t)
+	g.Expect(cri.ValidateRuntime("containerd")).To(Succeed())
+	g.Expect(cri.ValidateRuntime("crio")).To(Succeed())
+	g.Expect(cri.ValidateRuntime("docker")).To(MatchError(`container runtime "docker" is not supported. Allowed values: [containerd crio]`))
+}
+
+func TestFromTracker(t *testing.T) {
+	testCases := []struct {
+		name        string
+		artifacts   *tracker.InstalledArtifacts
+		wantRuntime cri.Runtime
+		wantSocket  string
+	}{
+		{
+			name:        "no tracker",
+			wantRuntime: cri.Containerd,
+			wantSocket:  "unix:///run/containerd/containerd.sock",
+		},
+		{
+			name:        "runtime not tracked",
+			artifacts:   &tracker.InstalledArtifacts{Containerd: "distro"},
+			wantRuntime: cri.Containerd,
+			wantSocket:  "unix:///run/containerd/containerd.sock",
+		},
+		{
+			name:        "cri-o",
+			artifacts:   &tracker.InstalledArtifacts{Containerd: "none", Runtime: "crio"},
+			wantRuntime: cri.Crio,
+			wantSocket:  "unix:///var/run/crio/crio.sock",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			runtime := cri.FromTracker(tc.artifacts)
+			g.Expect(runtime).To(Equal(tc.wantRuntime))
+			g.Expect(cri.Endpoint(runtime)).To(Equal(tc.wantSocket))
+		})
+	}
+}
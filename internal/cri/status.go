@@ -0,0 +1,62 @@
+package cri
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	v1 "k8s.io/cri-api/pkg/apis/runtime/v1"
+)
+
+// RuntimeHandlers returns the names of the runtime handlers registered in the
+// container runtime at endpoint, as reported by the CRI Status call.
+func RuntimeHandlers(ctx context.Context, endpoint string) ([]string, error) {
+	conn, err := grpc.NewClient(endpoint, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return nil, fmt.Errorf("connecting to container runtime at %s: %w", endpoint, err)
+	}
+	defer conn.Close()
+
+	status, err := v1.NewRuntimeServiceClient(conn).Status(ctx, &v1.StatusRequest{Verbose: true})
+	if err != nil {
+		return nil, fmt.Errorf("getting container runtime status: %w", err)
+	}
+	return runtimeHandlers(status)
+}
+
+// runtimeHandlers reads the handlers from the status response. Runtimes that
+// predate the runtime handlers field, such as containerd 1.x, only list them in
+// the verbose config info.
+func runtimeHandlers(status *v1.StatusResponse) ([]string, error) {
+	var handlers []string
+	for _, handler := range status.GetRuntimeHandlers() {
+		// the default handler has an empty name
+		if handler.GetName() != "" {
+			handlers = append(handlers, handler.GetName())
+		}
+	}
+	if len(handlers) > 0 {
+		return handlers, nil
+	}
+
+	rawConfig, ok := status.GetInfo()["config"]
+	if !ok {
+		return nil, fmt.Errorf("container runtime status doesn't include the runtime handlers")
+	}
+	var config struct {
+		Containerd struct {
+			Runtimes map[string]json.RawMessage `json:"runtimes"`
+		} `json:"containerd"`
+	}
+	if err := json.Unmarshal([]byte(rawConfig), &config); err != nil {
+		return nil, fmt.Errorf("parsing container runtime config: %w", err)
+	}
+	for name := range config.Containerd.Runtimes {
+		handlers = append(handlers, name)
+	}
+	slices.Sort(handlers)
+	return handlers, nil
+}
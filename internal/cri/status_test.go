@@ -0,0 +1,52 @@
+package cri
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	v1 "k8s.io/cri-api/pkg/apis/runtime/v1"
+)
+
+func TestRuntimeHandlers(t *testing.T) {
+	testCases := []struct {
+		name    string
+		status  *v1.StatusResponse
+		want    []string
+		wantErr string
+	}{
+		{
+			name: "runtime handlers",
+			status: &v1.StatusResponse{
+				RuntimeHandlers: []*v1.RuntimeHandler{{Name: ""}, {Name: "runc"}, {Name: "runsc"}},
+			},
+			want: []string{"runc", "runsc"},
+		},
+		{
+			name: "containerd 1.x config info",
+			status: &v1.StatusResponse{
+				Info: map[string]string{
+					"config": `{"containerd":{"defaultRuntimeName":"runc","runtimes":{"runsc":{"runtimeType":"io.containerd.runsc.v1"},"runc":{"runtimeType":"io.containerd.runc.v2"}}}}`,
+				},
+			},
+			want: []string{"runc", "runsc"},
+		},
+		{
+			name:    "no handlers",
+			status:  &v1.StatusResponse{},
+			wantErr: "container runtime status doesn't include the runtime handlers",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			handlers, err := runtimeHandlers(tc.status)
+			if tc.wantErr != "" {
+				g.Expect(err).To(MatchError(tc.wantErr))
+				return
+			}
+			g.Expect(err).To(Succeed())
+			g.Expect(handlers).To(Equal(tc.want))
+		})
+	}
+}
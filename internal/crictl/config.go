@@ -0,0 +1,42 @@
+package crictl
+
+import (
+	"bytes"
+	_ "embed"
+	"text/template"
+
+	"github.com/aws/eks-hybrid/internal/util"
+)
+
+// ConfigPath is the path to the crictl config file.
+const ConfigPath = "/etc/crictl.yaml"
+
+const configPerms = 0o644
+
+var (
+	//go:embed crictl.template.yaml
+	configTemplateData string
+	configTemplate     = template.Must(template.New(ConfigPath).Parse(configTemplateData))
+)
+
+type configTemplateVars struct {
+	RuntimeEndpoint string
+}
+
+func writeConfig(path, runtimeEndpoint string) error {
+	config, err := generateConfig(runtimeEndpoint)
+	if err != nil {
+		return err
+	}
+	return util.WriteFileWithDir(path, config, configPerms)
+}
+
+func generateConfig(runtimeEndpoint string) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := configTemplate.Execute(&buf, configTemplateVars{
+		RuntimeEndpoint: runtimeEndpoint,
+	}); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,107 @@
+package crictl
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	"github.com/aws/eks-hybrid/internal/artifact"
+	"github.com/aws/eks-hybrid/internal/cri"
+	"github.com/aws/eks-hybrid/internal/tracker"
+)
+
+const (
+	// BinPath is the path to the crictl binary.
+	BinPath = "/usr/local/bin/crictl"
+
+	artifactName      = "crictl"
+	artifactFilePerms = 0o755
+)
+
+// Source represents a source that serves a crictl binary.
+type Source interface {
+	GetCrictl(context.Context) (artifact.Source, error)
+}
+
+// InstallOptions contains options for installing crictl
+type InstallOptions struct {
+	InstallRoot string
+	Tracker     *tracker.Tracker
+	Source      Source
+	Logger      *zap.Logger
+}
+
+// Install installs crictl at BinPath and writes its config at ConfigPath.
+func Install(ctx context.Context, opts InstallOptions) error {
+	if err := downloadFileWithRetries(ctx, opts); err != nil {
+		return errors.Wrap(err, "installing crictl")
+	}
+
+	runtimeEndpoint := cri.Endpoint(cri.FromTracker(opts.Tracker.Artifacts))
+	if err := writeConfig(filepath.Join(opts.InstallRoot, ConfigPath), runtimeEndpoint); err != nil {
+		return errors.Wrap(err, "writing crictl config")
+	}
+
+	if err := opts.Tracker.Add(artifact.Crictl); err != nil {
+		return errors.Wrap(err, "adding crictl to tracker")
+	}
+
+	return nil
+}
+
+func downloadFileWithRetries(ctx context.Context, opts InstallOptions) error {
+	// Retry up to 3 times to download and validate the checksum
+	var err error
+	for range 3 {
+		err = downloadFileTo(ctx, opts)
+		if err == nil {
+			break
+		}
+		opts.Logger.Error("Downloading crictl failed. Retrying...", zap.Error(err))
+	}
+	return err
+}
+
+func downloadFileTo(ctx context.Context, opts InstallOptions) error {
+	crictl, err := opts.Source.GetCrictl(ctx)
+	if err != nil {
+		return errors.Wrap(err, "getting crictl source")
+	}
+	defer crictl.Close()
+
+	if err := artifact.InstallFile(filepath.Join(opts.InstallRoot, BinPath), crictl, artifactFilePerms); err != nil {
+		return errors.Wrap(err, "installing crictl")
+	}
+
+	if !crictl.VerifyChecksum() {
+		return errors.Errorf("crictl checksum mismatch: %v", artifact.NewChecksumError(crictl))
+	}
+
+	return nil
+}
+
+// Uninstall removes the crictl binary and its config.
+func Uninstall() error {
+	if err := os.RemoveAll(BinPath); err != nil {
+		return err
+	}
+	return os.RemoveAll(ConfigPath)
+}
+
+func Upgrade(ctx context.Context, src Source, log *zap.Logger) error {
+	crictl, err := src.GetCrictl(ctx)
+	if err != nil {
+		return errors.Wrap(err, "getting crictl source")
+	}
+	defer crictl.Close()
+
+	if err := artifact.Upgrade(artifactName, BinPath, crictl, artifactFilePerms, log); err != nil {
+		return err
+	}
+
+	// the config is owned by nodeadm, re-write it in case the endpoint changed
+	return writeConfig(ConfigPath, cri.Endpoint(cri.InstalledRuntime()))
+}
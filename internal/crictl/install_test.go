@@ -0,0 +1,45 @@
+package crictl_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"go.uber.org/zap"
+
+	"github.com/aws/eks-hybrid/internal/aws"
+	"github.com/aws/eks-hybrid/internal/crictl"
+	"github.com/aws/eks-hybrid/internal/test"
+	"github.com/aws/eks-hybrid/internal/tracker"
+)
+
+func TestInstall(t *testing.T) {
+	crictlData := []byte("test crictl binary")
+
+	test.RunInstallTest(t, test.TestData{
+		ArtifactName: "crictl",
+		BinaryName:   "crictl",
+		Data:         crictlData,
+		Install: func(ctx context.Context, tempDir string, source aws.Source, tr *tracker.Tracker) error {
+			return crictl.Install(ctx, crictl.InstallOptions{
+				InstallRoot: tempDir,
+				Tracker:     tr,
+				Source:      source,
+				Logger:      zap.NewNop(),
+			})
+		},
+		Verify: func(g *GomegaWithT, tempDir string, tr *tracker.Tracker) {
+			g.Expect(tr.Artifacts.Crictl).To(BeTrue())
+
+			config, err := os.ReadFile(filepath.Join(tempDir, crictl.ConfigPath))
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(string(config)).To(Equal(`runtime-endpoint: unix:///run/containerd/containerd.sock
+image-endpoint: unix:///run/containerd/containerd.sock
+timeout: 10
+`))
+		},
+		VerifyFilePaths: []string{crictl.BinPath, crictl.ConfigPath},
+	})
+}
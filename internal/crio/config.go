@@ -0,0 +1,55 @@
+package crio
+
+import (
+	"bytes"
+	_ "embed"
+	"text/template"
+
+	"github.com/aws/eks-hybrid/internal/api"
+	"github.com/aws/eks-hybrid/internal/system"
+	"github.com/aws/eks-hybrid/internal/util"
+)
+
+const (
+	crioConfigDir               = "/etc/crio/crio.conf.d"
+	crioConfigFile              = crioConfigDir + "/10-nodeadm.conf"
+	crioKernelModulesConfigFile = "/etc/modules-load.d/crio.conf"
+	crioConfigPerm              = 0o644
+)
+
+var (
+	// the drop-in only sets what the node depends on, the rest of the CRI-O
+	// config is left to the distro and to drop-ins sorted after this one
+	//go:embed config.template.conf
+	crioConfigTemplateData string
+	crioConfigTemplate     = template.Must(template.New(crioConfigFile).Parse(crioConfigTemplateData))
+
+	//go:embed kernel-modules.conf
+	crioKernelModulesFileData string
+)
+
+type crioTemplateVars struct {
+	SandboxImage string
+}
+
+func writeCrioConfig(cfg *api.NodeConfig) error {
+	crioConfig, err := generateCrioConfig(cfg)
+	if err != nil {
+		return err
+	}
+	return util.WriteFileWithDir(crioConfigFile, crioConfig, crioConfigPerm)
+}
+
+func generateCrioConfig(cfg *api.NodeConfig) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := crioConfigTemplate.Execute(&buf, crioTemplateVars{
+		SandboxImage: cfg.Status.Defaults.SandboxImage,
+	}); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func writeCrioKernelModulesConfig(cfg *api.NodeConfig) error {
+	return util.WriteFileWithDir(crioKernelModulesConfigFile, system.KernelModulesConfig(crioKernelModulesFileData, cfg), crioConfigPerm)
+}
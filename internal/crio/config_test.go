@@ -0,0 +1,35 @@
+package crio
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/aws/eks-hybrid/internal/api"
+)
+
+func TestGenerateCrioConfig(t *testing.T) {
+	g := NewWithT(t)
+	cfg := &api.NodeConfig{
+		Status: api.NodeConfigStatus{
+			Defaults: api.DefaultOptions{
+				SandboxImage: "602401143452.dkr.ecr.us-west-2.amazonaws.com/eks/pause:3.5",
+			},
+		},
+	}
+
+	config, err := generateCrioConfig(cfg)
+	g.Expect(err).To(Succeed())
+	g.Expect(string(config)).To(Equal(`# Managed by nodeadm. Add drop-ins sorted after this file to change the CRI-O config.
+[crio.image]
+pause_image = "602401143452.dkr.ecr.us-west-2.amazonaws.com/eks/pause:3.5"
+
+[crio.runtime]
+cgroup_manager = "systemd"
+conmon_cgroup = "pod"
+
+[crio.network]
+network_dir = "/etc/cni/net.d"
+plugin_dirs = ["/opt/cni/bin"]
+`))
+}
@@ -0,0 +1,118 @@
+package crio
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"go.uber.org/zap"
+
+	"github.com/aws/eks-hybrid/internal/api"
+	"github.com/aws/eks-hybrid/internal/cri"
+	"github.com/aws/eks-hybrid/internal/daemon"
+	"github.com/aws/eks-hybrid/internal/proxy"
+	"github.com/aws/eks-hybrid/internal/system"
+)
+
+const (
+	CrioDaemonName           = "crio"
+	kernelModulesSystemdUnit = "systemd-modules-load"
+)
+
+var (
+	_ daemon.Daemon            = &crio{}
+	_ daemon.ConfigFilesLister = &crio{}
+	_ daemon.ConfigRenderer    = &crio{}
+)
+
+type crio struct {
+	daemonManager daemon.DaemonManager
+	nodeConfig    *api.NodeConfig
+	awsConfig     *aws.Config
+	logger        *zap.Logger
+}
+
+func NewCrioDaemon(daemonManager daemon.DaemonManager, cfg *api.NodeConfig, awsConfig *aws.Config, logger *zap.Logger) daemon.Daemon {
+	return &crio{
+		daemonManager: daemonManager,
+		nodeConfig:    cfg,
+		awsConfig:     awsConfig,
+		logger:        logger,
+	}
+}
+
+func (c *crio) Configure() error {
+	if err := writeCrioConfig(c.nodeConfig); err != nil {
+		return err
+	}
+	if err := proxy.WriteDropIn(CrioDaemonName, c.nodeConfig); err != nil {
+		return err
+	}
+	return writeCrioKernelModulesConfig(c.nodeConfig)
+}
+
+func (c *crio) RenderConfig() (map[string][]byte, error) {
+	crioConfig, err := generateCrioConfig(c.nodeConfig)
+	if err != nil {
+		return nil, err
+	}
+	dropIn, err := proxy.RenderDropIn(c.nodeConfig)
+	if err != nil {
+		return nil, err
+	}
+	return map[string][]byte{
+		crioConfigFile:                   crioConfig,
+		crioKernelModulesConfigFile:      system.KernelModulesConfig(crioKernelModulesFileData, c.nodeConfig),
+		proxy.DropInPath(CrioDaemonName): dropIn,
+	}, nil
+}
+
+func (c *crio) ConfigFiles() []string {
+	return []string{
+		crioConfigFile,
+		crioKernelModulesConfigFile,
+		proxy.DropInPath(CrioDaemonName),
+	}
+}
+
+// EnsureRunning enables CRI-O and restarts it with the written configuration.
+func (c *crio) EnsureRunning(ctx context.Context) error {
+	if err := c.daemonManager.DaemonReload(); err != nil {
+		return err
+	}
+	if err := c.daemonManager.RestartDaemon(ctx, kernelModulesSystemdUnit); err != nil {
+		return err
+	}
+	if err := c.daemonManager.EnableDaemon(CrioDaemonName); err != nil {
+		return err
+	}
+	if err := c.daemonManager.RestartDaemon(ctx, CrioDaemonName); err != nil {
+		return err
+	}
+
+	runningCtx, cancel := context.WithTimeout(ctx, 5*time.Minute)
+	defer cancel()
+
+	c.logger.Info("Waiting for cri-o to be running...")
+	if err := daemon.WaitForStatus(runningCtx, c.logger, c.daemonManager, CrioDaemonName, daemon.DaemonStatusRunning, 5*time.Second); err != nil {
+		return fmt.Errorf("waiting for cri-o to be running: %w", err)
+	}
+	c.logger.Info("cri-o is running")
+
+	return nil
+}
+
+// PostLaunch pre-pulls the pause image, which CRI-O would otherwise pull
+// without ECR credentials when the first pod is created.
+func (c *crio) PostLaunch() error {
+	return cri.PullSandboxImage(c.awsConfig, cri.CrioEndpoint, c.nodeConfig.Status.Defaults.SandboxImage)
+}
+
+func (c *crio) Stop() error {
+	return c.daemonManager.StopDaemon(CrioDaemonName)
+}
+
+func (c *crio) Name() string {
+	return CrioDaemonName
+}
@@ -0,0 +1,87 @@
+package crio
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/aws/eks-hybrid/internal/artifact"
+	"github.com/aws/eks-hybrid/internal/daemon"
+	"github.com/aws/eks-hybrid/internal/tracker"
+	"github.com/aws/eks-hybrid/internal/util/cmd"
+)
+
+const crioBinName = "crio"
+
+// Source represents a source that serves a CRI-O package.
+type Source interface {
+	GetCrio() artifact.Package
+}
+
+// Install installs CRI-O from source, unless it's already installed. CRI-O is
+// only tracked, and later upgraded or uninstalled, if nodeadm installed it.
+func Install(ctx context.Context, tracker *tracker.Tracker, source Source) error {
+	if isCrioInstalled() {
+		return nil
+	}
+	crio := source.GetCrio()
+	// Sometimes install fails due to conflicts with other processes
+	// updating packages, specially when automating at machine startup.
+	// We assume errors are transient and just retry for a bit.
+	if err := cmd.Retry(ctx, crio.InstallCmd, 5*time.Second); err != nil {
+		return errors.Wrap(err, "failed to install cri-o")
+	}
+	return tracker.Add(artifact.Crio)
+}
+
+func Uninstall(ctx context.Context, source Source) error {
+	if isCrioInstalled() {
+		crio := source.GetCrio()
+		if err := cmd.Retry(ctx, crio.UninstallCmd, 5*time.Second); err != nil {
+			return errors.Wrap(err, "failed to uninstall cri-o")
+		}
+	}
+	return RemoveConfig()
+}
+
+func Upgrade(ctx context.Context, source Source) error {
+	crio := source.GetCrio()
+	if err := cmd.Retry(ctx, crio.UpgradeCmd, 5*time.Second); err != nil {
+		return errors.Wrap(err, "upgrading cri-o")
+	}
+	return nil
+}
+
+// RemoveConfig removes the CRI-O configuration written by nodeadm.
+func RemoveConfig() error {
+	for _, path := range []string{crioConfigFile, crioKernelModulesConfigFile} {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+func ValidateSystemdUnitFile() error {
+	daemonManager, err := daemon.NewDaemonManager()
+	if err != nil {
+		return err
+	}
+	if err := daemonManager.DaemonReload(); err != nil {
+		return err
+	}
+	daemonStatus, err := daemonManager.GetDaemonStatus(CrioDaemonName)
+	if daemonStatus == daemon.DaemonStatusUnknown || err != nil {
+		return fmt.Errorf("cri-o daemon not found")
+	}
+	return nil
+}
+
+func isCrioInstalled() bool {
+	_, err := exec.LookPath(crioBinName)
+	return err == nil
+}
@@ -22,3 +22,23 @@ type Daemon interface {
 	// Name returns the name of the daemon.
 	Name() string
 }
+
+// ConfigFilesLister is implemented by daemons that can report the files
+// written by Configure, so callers can detect whether re-configuring the
+// daemon changed its inputs.
+type ConfigFilesLister interface {
+	// ConfigFiles returns the paths of all files the daemon may write
+	// during Configure, including the ones written for a previous
+	// configuration.
+	ConfigFiles() []string
+}
+
+// ConfigRenderer is implemented by daemons that can render the files written
+// by Configure without writing them, so callers can preview configuration
+// changes without touching the node.
+type ConfigRenderer interface {
+	// RenderConfig returns the content Configure writes to each file, keyed by
+	// path. A nil content means the file is removed, as are files listed by
+	// ConfigFiles that are missing from the result.
+	RenderConfig() (map[string][]byte, error)
+}
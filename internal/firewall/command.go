@@ -0,0 +1,77 @@
+package firewall
+
+import (
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/aws/eks-hybrid/internal/util"
+)
+
+// runner runs a command and returns its standard output.
+type runner func(name string, args ...string) (string, error)
+
+func runCommand(name string, args ...string) (string, error) {
+	// #nosec G204 Subprocess launched with variable
+	cmd := exec.Command(name, args...)
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return string(out), fmt.Errorf("running %s: %s: %w", cmd.Args, strings.TrimSpace(stderr.String()), err)
+	}
+	return string(out), nil
+}
+
+// portRange is an inclusive range of ports, a single port has the same start
+// and end.
+type portRange struct {
+	start int
+	end   int
+}
+
+// parsePortRange parses a port or a range of ports with sep between start and
+// end.
+func parsePortRange(port, sep string) (portRange, error) {
+	startPort, endPort, isRange := strings.Cut(port, sep)
+	start, err := strconv.Atoi(startPort)
+	if err != nil {
+		return portRange{}, fmt.Errorf("invalid port %q", port)
+	}
+	if !isRange {
+		return portRange{start: start, end: start}, nil
+	}
+	end, err := strconv.Atoi(endPort)
+	if err != nil || end < start {
+		return portRange{}, fmt.Errorf("invalid port range %q", port)
+	}
+	return portRange{start: start, end: end}, nil
+}
+
+func (r portRange) contains(other portRange) bool {
+	return r.start <= other.start && other.end <= r.end
+}
+
+// nftString returns the range as nft writes it.
+func (r portRange) nftString() string {
+	if r.start == r.end {
+		return strconv.Itoa(r.start)
+	}
+	return fmt.Sprintf("%d-%d", r.start, r.end)
+}
+
+// firstExistingFile returns the first of the paths that exists, or an empty
+// string if none does.
+func firstExistingFile(paths []string) (string, error) {
+	for _, path := range paths {
+		exists, err := util.IsFilePathExists(path)
+		if err != nil {
+			return "", err
+		}
+		if exists {
+			return path, nil
+		}
+	}
+	return "", nil
+}
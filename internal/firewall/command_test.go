@@ -0,0 +1,29 @@
+package firewall
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// fakeRunner records the commands and answers them from outputs, keyed by the
+// command line.
+type fakeRunner struct {
+	commands []string
+	outputs  map[string]string
+}
+
+func (f *fakeRunner) run(name string, args ...string) (string, error) {
+	command := strings.Join(append([]string{name}, args...), " ")
+	f.commands = append(f.commands, command)
+	return f.outputs[command], nil
+}
+
+func readTestdata(t *testing.T, name string) string {
+	data, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
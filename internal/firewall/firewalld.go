@@ -71,6 +71,46 @@ func (fd *firewalld) AllowTcpPortRange(startPort, endPort string) error {
 	return nil
 }
 
+// AllowUdpPort adds a rule to the firewall to open input UDP port
+func (fd *firewalld) AllowUdpPort(port string) error {
+	portAddCmd := exec.Command(fd.binPath, "--permanent", fmt.Sprintf("--add-port=%s/udp", port))
+	out, err := portAddCmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("failed to allow port %s/udp in firewall: %s, error: %v", port, out, err)
+	}
+	return nil
+}
+
+// RemoveTcpPort removes the rule opening input port from the firewall
+func (fd *firewalld) RemoveTcpPort(port string) error {
+	portRemoveCmd := exec.Command(fd.binPath, "--permanent", fmt.Sprintf("--remove-port=%s/tcp", port))
+	out, err := portRemoveCmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("failed to remove port %s from firewall: %s, error: %v", port, out, err)
+	}
+	return nil
+}
+
+// RemoveTcpPortRange removes the rule opening the range of input port from the firewall
+func (fd *firewalld) RemoveTcpPortRange(startPort, endPort string) error {
+	portRemoveCmd := exec.Command(fd.binPath, "--permanent", fmt.Sprintf("--remove-port=%s-%s/tcp", startPort, endPort))
+	out, err := portRemoveCmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("failed to remove ports from firewall: %s, error: %v", out, err)
+	}
+	return nil
+}
+
+// RemoveUdpPort removes the rule opening input UDP port from the firewall
+func (fd *firewalld) RemoveUdpPort(port string) error {
+	portRemoveCmd := exec.Command(fd.binPath, "--permanent", fmt.Sprintf("--remove-port=%s/udp", port))
+	out, err := portRemoveCmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("failed to remove port %s/udp from firewall: %s, error: %v", port, out, err)
+	}
+	return nil
+}
+
 // FlushRules flushes the rules and reloads the firewall to enforce the rules
 func (fd *firewalld) FlushRules() error {
 	reloadCmd := exec.Command(fd.binPath, "--reload")
@@ -11,9 +11,22 @@ type Manager interface {
 	// AllowTcpPortRange adds a rule to open a range of port on the host
 	AllowTcpPortRange(string, string) error
 
+	// AllowUdpPort adds a rule to open a UDP port on the host
+	AllowUdpPort(string) error
+
+	// RemoveTcpPort removes the rule opening a port on the host
+	RemoveTcpPort(string) error
+
+	// RemoveTcpPortRange removes the rule opening a range of port on the host
+	RemoveTcpPortRange(string, string) error
+
+	// RemoveUdpPort removes the rule opening a UDP port on the host
+	RemoveUdpPort(string) error
+
 	// FlushRules writes newly added rules to disk and reloads the firewall
 	FlushRules() error
 
-	// IsPortOpen return true if firewall allows traffic on input port
+	// IsPortOpen return true if firewall allows traffic on input port. The
+	// port can be a range in the start-end form.
 	IsPortOpen(string, string) (bool, error)
 }
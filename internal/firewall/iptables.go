@@ -0,0 +1,383 @@
+package firewall
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+const (
+	iptablesBinary  = "iptables"
+	ip6tablesBinary = "ip6tables"
+
+	// iptablesChain holds the rules added by nodeadm, it's jumped to from the
+	// top of the INPUT chain.
+	iptablesChain      = "NODEADM-INPUT"
+	iptablesInputChain = "INPUT"
+)
+
+// iptablesSaveFiles are the rules restored on boot by the iptables-services
+// package of RHEL based distros and the iptables-persistent package of Debian
+// based distros, by binary.
+var iptablesSaveFiles = map[string][]string{
+	iptablesBinary:  {"/etc/sysconfig/iptables", "/etc/iptables/rules.v4"},
+	ip6tablesBinary: {"/etc/sysconfig/ip6tables", "/etc/iptables/rules.v6"},
+}
+
+// iptables manages the rules of hosts filtering traffic with plain iptables
+// rules, without a firewall daemon. The rules are added with both iptables
+// and ip6tables, for dual-stack and IPv6 nodes.
+type iptables struct {
+	binPaths  []string
+	run       runner
+	saveFiles map[string][]string
+}
+
+func NewIptables() Manager {
+	var paths []string
+	for _, binary := range []string{iptablesBinary, ip6tablesBinary} {
+		if path, err := exec.LookPath(binary); err == nil {
+			paths = append(paths, path)
+		}
+	}
+	return &iptables{
+		binPaths:  paths,
+		run:       runCommand,
+		saveFiles: iptablesSaveFiles,
+	}
+}
+
+// IsEnabled returns true if the INPUT chain of either family drops traffic,
+// either with its policy or a rule
+func (ipt *iptables) IsEnabled() (bool, error) {
+	for _, binPath := range ipt.binPaths {
+		rules, err := ipt.rules(binPath)
+		if err != nil {
+			return false, err
+		}
+		if iptablesDropsInput(rules) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func iptablesDropsInput(rules []string) bool {
+	for _, rule := range rules {
+		fields := strings.Fields(rule)
+		if len(fields) < 3 || fields[1] != iptablesInputChain {
+			continue
+		}
+		switch {
+		case fields[0] == "-P" && fields[2] != "ACCEPT":
+			return true
+		case fields[0] == "-A" && (iptablesTarget(fields) == "DROP" || iptablesTarget(fields) == "REJECT"):
+			return true
+		}
+	}
+	return false
+}
+
+// AllowTcpPort adds a rule to the nodeadm chain to open input port
+func (ipt *iptables) AllowTcpPort(port string) error {
+	return ipt.allow(port, "tcp")
+}
+
+// AllowTcpPortRange adds a rule to the nodeadm chain to open the range of input port
+func (ipt *iptables) AllowTcpPortRange(startPort, endPort string) error {
+	return ipt.allow(fmt.Sprintf("%s:%s", startPort, endPort), "tcp")
+}
+
+// AllowUdpPort adds a rule to the nodeadm chain to open input UDP port
+func (ipt *iptables) AllowUdpPort(port string) error {
+	return ipt.allow(port, "udp")
+}
+
+// RemoveTcpPort removes the rule opening input port from the nodeadm chain
+func (ipt *iptables) RemoveTcpPort(port string) error {
+	return ipt.remove(port, "tcp")
+}
+
+// RemoveTcpPortRange removes the rule opening the range of input port from the nodeadm chain
+func (ipt *iptables) RemoveTcpPortRange(startPort, endPort string) error {
+	return ipt.remove(fmt.Sprintf("%s:%s", startPort, endPort), "tcp")
+}
+
+// RemoveUdpPort removes the rule opening input UDP port from the nodeadm chain
+func (ipt *iptables) RemoveUdpPort(port string) error {
+	return ipt.remove(port, "udp")
+}
+
+// FlushRules writes the rules of nodeadm to the saved rules of the host, so
+// they are restored on boot, iptables enforces them as soon as they are added.
+// Only the nodeadm rules are written, the saved rules keep the ones of the
+// host and none of the Kubernetes ones. Hosts without a saved rules file
+// restore their rules some other way and must add the ports to them.
+func (ipt *iptables) FlushRules() error {
+	for _, binPath := range ipt.binPaths {
+		saveFile, err := firstExistingFile(ipt.saveFiles[filepath.Base(binPath)])
+		if err != nil {
+			return err
+		}
+		if saveFile == "" {
+			continue
+		}
+		rules, err := ipt.rules(binPath)
+		if err != nil {
+			return err
+		}
+		info, err := os.Stat(saveFile)
+		if err != nil {
+			return err
+		}
+		saved, err := os.ReadFile(saveFile)
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(saveFile, []byte(saveNodeadmRules(string(saved), rules)), info.Mode().Perm()); err != nil {
+			return fmt.Errorf("failed to save %s rules: %w", filepath.Base(binPath), err)
+		}
+	}
+	return nil
+}
+
+// saveNodeadmRules replaces the nodeadm chain of the filter table of saved,
+// in the iptables-save format, with the one of rules. The chain is declared
+// and filled before the other rules, so the jump is the first INPUT rule.
+func saveNodeadmRules(saved string, rules []string) string {
+	var nodeadm []string
+	if slices.Contains(rules, "-N "+iptablesChain) {
+		nodeadm = append(nodeadm, fmt.Sprintf(":%s - [0:0]", iptablesChain))
+	}
+	for _, rule := range rules {
+		if rule == iptablesJumpRule() || strings.HasPrefix(rule, "-A "+iptablesChain+" ") {
+			nodeadm = append(nodeadm, rule)
+		}
+	}
+
+	var lines []string
+	var table string
+	var added bool
+	for _, line := range strings.Split(strings.TrimSuffix(saved, "\n"), "\n") {
+		if strings.HasPrefix(line, "*") {
+			table = strings.TrimPrefix(line, "*")
+		} else if table == "filter" {
+			if line == iptablesJumpRule() || strings.HasPrefix(line, ":"+iptablesChain+" ") || strings.HasPrefix(line, "-A "+iptablesChain+" ") {
+				continue
+			}
+			if !added && !strings.HasPrefix(line, ":") {
+				lines = append(lines, nodeadm...)
+				added = true
+			}
+		}
+		lines = append(lines, line)
+	}
+	if !added && len(nodeadm) > 0 {
+		lines = append(lines, "*filter")
+		lines = append(lines, nodeadm...)
+		lines = append(lines, "COMMIT")
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// IsPortOpen returns true if the filter table of every family that drops
+// input traffic has a rule accepting traffic on port/protocol. When no family
+// drops traffic, a rule of either family is enough.
+func (ipt *iptables) IsPortOpen(port, protocol string) (bool, error) {
+	wanted, err := parsePortRange(strings.Replace(port, "-", ":", 1), ":")
+	if err != nil {
+		return false, err
+	}
+	var filtering, accepting, accepted int
+	for _, binPath := range ipt.binPaths {
+		rules, err := ipt.rules(binPath)
+		if err != nil {
+			return false, err
+		}
+		open := iptablesAcceptsPort(rules, wanted, protocol)
+		if open {
+			accepted++
+		}
+		if iptablesDropsInput(rules) {
+			filtering++
+			if open {
+				accepting++
+			}
+		}
+	}
+	if filtering == 0 {
+		return accepted > 0, nil
+	}
+	return accepting == filtering, nil
+}
+
+// iptablesAcceptsPort returns true if a rule of INPUT, or of a chain it jumps
+// to, accepts the ports from any address and interface.
+func iptablesAcceptsPort(rules []string, wanted portRange, protocol string) bool {
+	chains := iptablesInputChains(rules)
+	for _, rule := range rules {
+		fields := strings.Fields(rule)
+		if len(fields) < 2 || fields[0] != "-A" || !chains[fields[1]] || iptablesRestricted(fields) {
+			continue
+		}
+		if iptablesTarget(fields) != "ACCEPT" || iptablesOption(fields, "-p") != protocol {
+			continue
+		}
+		ports := iptablesOption(fields, "--dport")
+		if ports == "" {
+			ports = iptablesOption(fields, "--dports")
+		}
+		for _, port := range strings.Split(ports, ",") {
+			if accepted, err := parsePortRange(port, ":"); err == nil && accepted.contains(wanted) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// iptablesInputChains returns INPUT and the chains it jumps or goes to,
+// directly or through other chains.
+func iptablesInputChains(rules []string) map[string]bool {
+	chains := map[string]bool{iptablesInputChain: true}
+	for added := true; added; {
+		added = false
+		for _, rule := range rules {
+			fields := strings.Fields(rule)
+			if len(fields) < 2 || fields[0] != "-A" || !chains[fields[1]] || iptablesRestricted(fields) {
+				continue
+			}
+			for _, target := range []string{iptablesTarget(fields), iptablesOption(fields, "-g")} {
+				if target != "" && !chains[target] && slices.Contains(rules, "-N "+target) {
+					chains[target] = true
+					added = true
+				}
+			}
+		}
+	}
+	return chains
+}
+
+// iptablesRestricted returns true if the rule only matches some input
+// interfaces or source addresses.
+func iptablesRestricted(fields []string) bool {
+	return iptablesOption(fields, "-i") != "" || iptablesOption(fields, "-s") != "" || iptablesOption(fields, "--src-range") != ""
+}
+
+func (ipt *iptables) allow(port, protocol string) error {
+	for _, binPath := range ipt.binPaths {
+		if err := ipt.allowFamily(binPath, port, protocol); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (ipt *iptables) allowFamily(binPath, port, protocol string) error {
+	rules, err := ipt.rules(binPath)
+	if err != nil {
+		return err
+	}
+	if !slices.Contains(rules, "-N "+iptablesChain) {
+		if _, err := ipt.run(binPath, "-N", iptablesChain); err != nil {
+			return fmt.Errorf("failed to create chain %s: %w", iptablesChain, err)
+		}
+	}
+	if !slices.Contains(rules, iptablesJumpRule()) {
+		if _, err := ipt.run(binPath, "-I", iptablesInputChain, "1", "-j", iptablesChain); err != nil {
+			return fmt.Errorf("failed to jump to chain %s: %w", iptablesChain, err)
+		}
+	}
+	if slices.Contains(rules, iptablesPortRule(port, protocol)) {
+		return nil
+	}
+	if _, err := ipt.run(binPath, append([]string{"-A", iptablesChain}, iptablesPortRuleSpec(port, protocol)...)...); err != nil {
+		return fmt.Errorf("failed to allow port %s/%s in firewall: %w", port, protocol, err)
+	}
+	return nil
+}
+
+func (ipt *iptables) remove(port, protocol string) error {
+	for _, binPath := range ipt.binPaths {
+		if err := ipt.removeFamily(binPath, port, protocol); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// removeFamily deletes the rule of the port, then the chain once it has no
+// rules left.
+func (ipt *iptables) removeFamily(binPath, port, protocol string) error {
+	rules, err := ipt.rules(binPath)
+	if err != nil {
+		return err
+	}
+	if slices.Contains(rules, iptablesPortRule(port, protocol)) {
+		if _, err := ipt.run(binPath, append([]string{"-D", iptablesChain}, iptablesPortRuleSpec(port, protocol)...)...); err != nil {
+			return fmt.Errorf("failed to remove port %s/%s from firewall: %w", port, protocol, err)
+		}
+	}
+	for _, rule := range rules {
+		if strings.HasPrefix(rule, "-A "+iptablesChain+" ") && rule != iptablesPortRule(port, protocol) {
+			return nil
+		}
+	}
+	if slices.Contains(rules, iptablesJumpRule()) {
+		if _, err := ipt.run(binPath, "-D", iptablesInputChain, "-j", iptablesChain); err != nil {
+			return fmt.Errorf("failed to remove jump to chain %s: %w", iptablesChain, err)
+		}
+	}
+	if slices.Contains(rules, "-N "+iptablesChain) {
+		if _, err := ipt.run(binPath, "-X", iptablesChain); err != nil {
+			return fmt.Errorf("failed to delete chain %s: %w", iptablesChain, err)
+		}
+	}
+	return nil
+}
+
+// rules returns the rules of the filter table, as printed by iptables -S.
+func (ipt *iptables) rules(binPath string) ([]string, error) {
+	out, err := ipt.run(binPath, "-S")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list %s rules: %w", filepath.Base(binPath), err)
+	}
+	var rules []string
+	for _, line := range strings.Split(out, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			rules = append(rules, line)
+		}
+	}
+	return rules, nil
+}
+
+func iptablesJumpRule() string {
+	return fmt.Sprintf("-A %s -j %s", iptablesInputChain, iptablesChain)
+}
+
+// iptablesPortRuleSpec is the rule accepting the port, as iptables -S prints
+// it.
+func iptablesPortRuleSpec(port, protocol string) []string {
+	return []string{"-p", protocol, "-m", protocol, "--dport", port, "-j", "ACCEPT"}
+}
+
+func iptablesPortRule(port, protocol string) string {
+	return strings.Join(append([]string{"-A", iptablesChain}, iptablesPortRuleSpec(port, protocol)...), " ")
+}
+
+func iptablesTarget(fields []string) string {
+	return iptablesOption(fields, "-j")
+}
+
+// iptablesOption returns the value of the option in the fields of a rule.
+func iptablesOption(fields []string, option string) string {
+	for i := 0; i < len(fields)-1; i++ {
+		if fields[i] == option {
+			return fields[i+1]
+		}
+	}
+	return ""
+}
@@ -0,0 +1,284 @@
+package firewall
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+const (
+	iptablesDrop = `-P INPUT DROP
+-P FORWARD DROP
+-P OUTPUT ACCEPT
+-A INPUT -m conntrack --ctstate RELATED,ESTABLISHED -j ACCEPT
+-A INPUT -p tcp -m tcp --dport 22 -j ACCEPT
+-A INPUT -p udp -m multiport --dports 4789,8472 -j ACCEPT
+`
+	iptablesNodeadm = `-P INPUT DROP
+-P FORWARD DROP
+-P OUTPUT ACCEPT
+-N NODEADM-INPUT
+-A INPUT -j NODEADM-INPUT
+-A INPUT -p tcp -m tcp --dport 22 -j ACCEPT
+-A NODEADM-INPUT -p tcp -m tcp --dport 10250 -j ACCEPT
+-A NODEADM-INPUT -p tcp -m tcp --dport 30000:32767 -j ACCEPT
+`
+	iptablesRestrictedRules = `-N LOGGING
+-A INPUT -i lo -p tcp -m tcp --dport 10249 -j ACCEPT
+-A INPUT -s 10.0.0.0/8 -p tcp -m tcp --dport 10257 -j ACCEPT
+-A LOGGING -p tcp -m tcp --dport 10255 -j ACCEPT
+`
+	iptablesAccept = `-P INPUT ACCEPT
+-P FORWARD ACCEPT
+-P OUTPUT ACCEPT
+`
+	iptablesReject = `-P INPUT ACCEPT
+-P FORWARD ACCEPT
+-P OUTPUT ACCEPT
+-A INPUT -p tcp -m tcp --dport 22 -j ACCEPT
+-A INPUT -j REJECT --reject-with icmp-host-prohibited
+`
+)
+
+func newTestIptables(rules string) (*iptables, *fakeRunner) {
+	runner := &fakeRunner{outputs: map[string]string{"iptables -S": rules}}
+	return &iptables{binPaths: []string{"iptables"}, run: runner.run}, runner
+}
+
+func TestIptablesIsEnabled(t *testing.T) {
+	testCases := []struct {
+		name  string
+		rules string
+		want  bool
+	}{
+		{name: "drop policy", rules: iptablesDrop, want: true},
+		{name: "reject rule", rules: iptablesReject, want: true},
+		{name: "accept all", rules: iptablesAccept, want: false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			ipt, _ := newTestIptables(tc.rules)
+			g.Expect(ipt.IsEnabled()).To(Equal(tc.want))
+		})
+	}
+
+	g := NewWithT(t)
+	g.Expect((&iptables{}).IsEnabled()).To(BeFalse())
+}
+
+func TestIptablesIsPortOpen(t *testing.T) {
+	testCases := []struct {
+		port     string
+		protocol string
+		want     bool
+	}{
+		{port: "22", protocol: "tcp", want: true},
+		{port: "22", protocol: "udp", want: false},
+		{port: "8472", protocol: "udp", want: true},
+		{port: "10250", protocol: "tcp", want: true},
+		{port: "31000", protocol: "tcp", want: true},
+		{port: "30000-32767", protocol: "tcp", want: true},
+		{port: "29000-31000", protocol: "tcp", want: false},
+		{port: "10256", protocol: "tcp", want: false},
+		// only from loopback, only from some addresses or in a chain INPUT
+		// doesn't jump to
+		{port: "10249", protocol: "tcp", want: false},
+		{port: "10257", protocol: "tcp", want: false},
+		{port: "10255", protocol: "tcp", want: false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.port+"/"+tc.protocol, func(t *testing.T) {
+			g := NewWithT(t)
+			ipt, _ := newTestIptables(iptablesDrop + iptablesNodeadm + iptablesRestrictedRules)
+			g.Expect(ipt.IsPortOpen(tc.port, tc.protocol)).To(Equal(tc.want))
+		})
+	}
+}
+
+func TestIptablesAllow(t *testing.T) {
+	g := NewWithT(t)
+	ipt, runner := newTestIptables(iptablesDrop)
+	g.Expect(ipt.AllowTcpPort("10250")).To(Succeed())
+	g.Expect(runner.commands).To(Equal([]string{
+		"iptables -S",
+		"iptables -N NODEADM-INPUT",
+		"iptables -I INPUT 1 -j NODEADM-INPUT",
+		"iptables -A NODEADM-INPUT -p tcp -m tcp --dport 10250 -j ACCEPT",
+	}))
+
+	ipt, runner = newTestIptables(iptablesNodeadm)
+	g.Expect(ipt.AllowTcpPort("10250")).To(Succeed())
+	g.Expect(ipt.AllowTcpPortRange("30000", "32767")).To(Succeed())
+	g.Expect(ipt.AllowTcpPort("10256")).To(Succeed())
+	g.Expect(ipt.AllowUdpPort("8472")).To(Succeed())
+	g.Expect(runner.commands).To(Equal([]string{
+		"iptables -S",
+		"iptables -S",
+		"iptables -S",
+		"iptables -A NODEADM-INPUT -p tcp -m tcp --dport 10256 -j ACCEPT",
+		"iptables -S",
+		"iptables -A NODEADM-INPUT -p udp -m udp --dport 8472 -j ACCEPT",
+	}))
+}
+
+func TestIptablesDualStack(t *testing.T) {
+	g := NewWithT(t)
+	runner := &fakeRunner{outputs: map[string]string{"iptables -S": iptablesNodeadm, "ip6tables -S": iptablesDrop}}
+	ipt := &iptables{binPaths: []string{"iptables", "ip6tables"}, run: runner.run}
+
+	// only open for IPv4
+	g.Expect(ipt.IsPortOpen("10250", "tcp")).To(BeFalse())
+	g.Expect(ipt.IsPortOpen("22", "tcp")).To(BeTrue())
+
+	runner.commands = nil
+	g.Expect(ipt.AllowTcpPort("10250")).To(Succeed())
+	g.Expect(runner.commands).To(Equal([]string{
+		"iptables -S",
+		"ip6tables -S",
+		"ip6tables -N NODEADM-INPUT",
+		"ip6tables -I INPUT 1 -j NODEADM-INPUT",
+		"ip6tables -A NODEADM-INPUT -p tcp -m tcp --dport 10250 -j ACCEPT",
+	}))
+
+	// IPv6 doesn't filter traffic
+	runner.outputs["ip6tables -S"] = iptablesAccept
+	g.Expect(ipt.IsPortOpen("10250", "tcp")).To(BeTrue())
+}
+
+func TestIptablesRemove(t *testing.T) {
+	g := NewWithT(t)
+	ipt, runner := newTestIptables(iptablesNodeadm)
+	g.Expect(ipt.RemoveTcpPort("10250")).To(Succeed())
+	g.Expect(runner.commands).To(Equal([]string{
+		"iptables -S",
+		"iptables -D NODEADM-INPUT -p tcp -m tcp --dport 10250 -j ACCEPT",
+	}))
+
+	// the last rule removes the chain
+	ipt, runner = newTestIptables(`-P INPUT DROP
+-N NODEADM-INPUT
+-A INPUT -j NODEADM-INPUT
+-A NODEADM-INPUT -p tcp -m tcp --dport 30000:32767 -j ACCEPT
+`)
+	g.Expect(ipt.RemoveTcpPortRange("30000", "32767")).To(Succeed())
+	g.Expect(runner.commands).To(Equal([]string{
+		"iptables -S",
+		"iptables -D NODEADM-INPUT -p tcp -m tcp --dport 30000:32767 -j ACCEPT",
+		"iptables -D INPUT -j NODEADM-INPUT",
+		"iptables -X NODEADM-INPUT",
+	}))
+
+	// nothing to remove
+	ipt, runner = newTestIptables(iptablesDrop)
+	g.Expect(ipt.RemoveTcpPort("10250")).To(Succeed())
+	g.Expect(runner.commands).To(Equal([]string{"iptables -S"}))
+}
+
+func TestIptablesFlushRules(t *testing.T) {
+	testCases := []struct {
+		name  string
+		saved string
+		rules string
+		want  string
+	}{
+		{
+			name: "add the nodeadm rules",
+			saved: `# Generated by iptables-save
+*nat
+:PREROUTING ACCEPT [0:0]
+COMMIT
+*filter
+:INPUT DROP [0:0]
+:FORWARD DROP [0:0]
+:OUTPUT ACCEPT [0:0]
+-A INPUT -p tcp -m tcp --dport 22 -j ACCEPT
+COMMIT
+`,
+			rules: iptablesNodeadm,
+			want: `# Generated by iptables-save
+*nat
+:PREROUTING ACCEPT [0:0]
+COMMIT
+*filter
+:INPUT DROP [0:0]
+:FORWARD DROP [0:0]
+:OUTPUT ACCEPT [0:0]
+:NODEADM-INPUT - [0:0]
+-A INPUT -j NODEADM-INPUT
+-A NODEADM-INPUT -p tcp -m tcp --dport 10250 -j ACCEPT
+-A NODEADM-INPUT -p tcp -m tcp --dport 30000:32767 -j ACCEPT
+-A INPUT -p tcp -m tcp --dport 22 -j ACCEPT
+COMMIT
+`,
+		},
+		{
+			name: "replace the nodeadm rules",
+			saved: `*filter
+:INPUT DROP [0:0]
+:NODEADM-INPUT - [0:0]
+-A INPUT -j NODEADM-INPUT
+-A INPUT -p tcp -m tcp --dport 22 -j ACCEPT
+-A NODEADM-INPUT -p tcp -m tcp --dport 10250 -j ACCEPT
+COMMIT
+`,
+			rules: `-P INPUT DROP
+-N NODEADM-INPUT
+-A INPUT -j NODEADM-INPUT
+-A NODEADM-INPUT -p udp -m udp --dport 8472 -j ACCEPT
+`,
+			want: `*filter
+:INPUT DROP [0:0]
+:NODEADM-INPUT - [0:0]
+-A INPUT -j NODEADM-INPUT
+-A NODEADM-INPUT -p udp -m udp --dport 8472 -j ACCEPT
+-A INPUT -p tcp -m tcp --dport 22 -j ACCEPT
+COMMIT
+`,
+		},
+		{
+			name: "remove the nodeadm rules",
+			saved: `*filter
+:INPUT DROP [0:0]
+:NODEADM-INPUT - [0:0]
+-A INPUT -j NODEADM-INPUT
+-A NODEADM-INPUT -p tcp -m tcp --dport 10250 -j ACCEPT
+COMMIT
+`,
+			rules: iptablesDrop,
+			want: `*filter
+:INPUT DROP [0:0]
+COMMIT
+`,
+		},
+		{
+			name:  "no filter table",
+			saved: "*nat\n:PREROUTING ACCEPT [0:0]\nCOMMIT\n",
+			rules: "-P INPUT DROP\n-N NODEADM-INPUT\n-A INPUT -j NODEADM-INPUT\n",
+			want:  "*nat\n:PREROUTING ACCEPT [0:0]\nCOMMIT\n*filter\n:NODEADM-INPUT - [0:0]\n-A INPUT -j NODEADM-INPUT\nCOMMIT\n",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			dir := t.TempDir()
+			saveFile := filepath.Join(dir, "rules.v4")
+			g.Expect(os.WriteFile(saveFile, []byte(tc.saved), 0o600)).To(Succeed())
+			ipt, _ := newTestIptables(tc.rules)
+			ipt.saveFiles = map[string][]string{"iptables": {filepath.Join(dir, "iptables"), saveFile}}
+
+			g.Expect(ipt.FlushRules()).To(Succeed())
+			g.Expect(os.ReadFile(saveFile)).To(BeEquivalentTo(tc.want))
+		})
+	}
+}
+
+func TestIptablesFlushRulesNotSaved(t *testing.T) {
+	g := NewWithT(t)
+	ipt, runner := newTestIptables(iptablesNodeadm)
+	ipt.saveFiles = map[string][]string{"iptables": {filepath.Join(t.TempDir(), "rules.v4")}}
+	g.Expect(ipt.FlushRules()).To(Succeed())
+	g.Expect(runner.commands).To(BeEmpty())
+}
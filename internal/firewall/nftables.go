@@ -0,0 +1,557 @@
+package firewall
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/aws/eks-hybrid/internal/util"
+)
+
+const (
+	nftBinary = "nft"
+
+	// nftablesChain holds the rules added by nodeadm. nftables runs every base
+	// chain of a hook and a packet accepted by one is still dropped by another,
+	// so the chain is added to the table of each input chain that drops
+	// traffic and jumped to from it, instead of being a base chain of its own.
+	// This covers hosts with separate ip and ip6 tables as well as inet ones.
+	nftablesChain = "nodeadm-input"
+	nftInputHook  = "input"
+
+	// nftablesRulesPath is included by the ruleset the nftables service loads
+	// on boot, to add the rules of nodeadm back.
+	nftablesRulesPath = "/etc/nftables/nodeadm.nft"
+)
+
+// nftablesConfigPaths are the rulesets loaded by the nftables service of RHEL
+// based and Debian based distros.
+var nftablesConfigPaths = []string{"/etc/sysconfig/nftables.conf", "/etc/nftables.conf"}
+
+// nftables manages the rules of hosts filtering traffic with an nftables
+// ruleset, without a firewall daemon.
+type nftables struct {
+	binPath     string
+	run         runner
+	configPaths []string
+	rulesPath   string
+}
+
+func NewNftables() Manager {
+	path, _ := exec.LookPath(nftBinary)
+	return &nftables{
+		binPath:     path,
+		run:         runCommand,
+		configPaths: nftablesConfigPaths,
+		rulesPath:   nftablesRulesPath,
+	}
+}
+
+// nftRuleset is the output of nft --json list ruleset, only with the fields
+// nodeadm reads.
+type nftRuleset struct {
+	Nftables []struct {
+		Chain *nftChain `json:"chain,omitempty"`
+		Rule  *nftRule  `json:"rule,omitempty"`
+	} `json:"nftables"`
+}
+
+type nftChain struct {
+	Family string `json:"family"`
+	Table  string `json:"table"`
+	Name   string `json:"name"`
+	Type   string `json:"type"`
+	Hook   string `json:"hook"`
+	Policy string `json:"policy"`
+}
+
+type nftRule struct {
+	Family string `json:"family"`
+	Table  string `json:"table"`
+	Chain  string `json:"chain"`
+	Handle int    `json:"handle"`
+	// Expr are statements with a single key, such as match, accept or jump
+	Expr []map[string]json.RawMessage `json:"expr"`
+}
+
+type nftMatch struct {
+	Op   string `json:"op"`
+	Left struct {
+		Payload *struct {
+			Protocol string `json:"protocol"`
+			Field    string `json:"field"`
+		} `json:"payload"`
+		Meta *struct {
+			Key string `json:"key"`
+		} `json:"meta"`
+	} `json:"left"`
+	Right json.RawMessage `json:"right"`
+}
+
+// IsEnabled returns true if an input chain drops traffic, either with its
+// policy or a rule
+func (nft *nftables) IsEnabled() (bool, error) {
+	if nft.binPath == "" {
+		return false, nil
+	}
+	ruleset, err := nft.ruleset()
+	if err != nil {
+		return false, err
+	}
+	return len(ruleset.inputChains()) > 0, nil
+}
+
+// AllowTcpPort adds a rule to the nodeadm chain to open input port
+func (nft *nftables) AllowTcpPort(port string) error {
+	return nft.allow(port, "tcp")
+}
+
+// AllowTcpPortRange adds a rule to the nodeadm chain to open the range of input port
+func (nft *nftables) AllowTcpPortRange(startPort, endPort string) error {
+	return nft.allow(fmt.Sprintf("%s-%s", startPort, endPort), "tcp")
+}
+
+// AllowUdpPort adds a rule to the nodeadm chain to open input UDP port
+func (nft *nftables) AllowUdpPort(port string) error {
+	return nft.allow(port, "udp")
+}
+
+// RemoveTcpPort removes the rule opening input port from the nodeadm chain
+func (nft *nftables) RemoveTcpPort(port string) error {
+	return nft.remove(port, "tcp")
+}
+
+// RemoveTcpPortRange removes the rule opening the range of input port from the nodeadm chain
+func (nft *nftables) RemoveTcpPortRange(startPort, endPort string) error {
+	return nft.remove(fmt.Sprintf("%s-%s", startPort, endPort), "tcp")
+}
+
+// RemoveUdpPort removes the rule opening input UDP port from the nodeadm chain
+func (nft *nftables) RemoveUdpPort(port string) error {
+	return nft.remove(port, "udp")
+}
+
+// FlushRules saves the rules of nodeadm to a file included by the ruleset the
+// nftables service loads on boot, nftables enforces them as soon as they are
+// added. Hosts without the nftables service load their ruleset some other way
+// and must add the ports to it.
+func (nft *nftables) FlushRules() error {
+	configPath, err := firstExistingFile(nft.configPaths)
+	if err != nil || configPath == "" {
+		return err
+	}
+	ruleset, err := nft.ruleset()
+	if err != nil {
+		return err
+	}
+	chains := ruleset.nodeadmChains()
+	if len(chains) == 0 {
+		if exists, err := util.IsFilePathExists(nft.rulesPath); err != nil || !exists {
+			return err
+		}
+	}
+	if err := os.MkdirAll(filepath.Dir(nft.rulesPath), 0o755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(nft.rulesPath, []byte(ruleset.nodeadmRules(configPath)), 0o600); err != nil {
+		return fmt.Errorf("failed to save nftables rules: %w", err)
+	}
+	if err := util.WriteFileUniqueLine(configPath, []byte(fmt.Sprintf("include %q", nft.rulesPath)), 0o600); err != nil {
+		return fmt.Errorf("failed to include nftables rules in %s: %w", configPath, err)
+	}
+	return nil
+}
+
+// nodeadmRules returns the nft commands adding the nodeadm chains, their rules
+// and the jumps to them. The tables and input chains are added when missing so
+// the ruleset still loads if they are gone from it.
+func (r *nftRuleset) nodeadmRules(configPath string) string {
+	var rules strings.Builder
+	fmt.Fprintf(&rules, "# Ports opened by nodeadm, included by %s\n", configPath)
+	for _, chain := range r.nodeadmChains() {
+		table := chain.Family + " " + chain.Table
+		fmt.Fprintf(&rules, "add table %s\n", table)
+		fmt.Fprintf(&rules, "add chain %s %s\n", table, nftablesChain)
+		fmt.Fprintf(&rules, "flush chain %s %s\n", table, nftablesChain)
+		for _, rule := range r.rules() {
+			if rule.Family != chain.Family || rule.Table != chain.Table || rule.Chain != nftablesChain || !rule.hasVerdict("accept") {
+				continue
+			}
+			for _, protocol := range []string{"tcp", "udp"} {
+				for _, ports := range rule.ports(protocol) {
+					fmt.Fprintf(&rules, "add rule %s %s %s dport %s accept\n", table, nftablesChain, protocol, ports.nftString())
+				}
+			}
+		}
+		for _, input := range r.chains() {
+			if input.Family == chain.Family && input.Table == chain.Table && len(r.jumpRules(input)) > 0 {
+				fmt.Fprintf(&rules, "add chain %s %s\n", table, input.Name)
+				fmt.Fprintf(&rules, "insert rule %s %s jump %s\n", table, input.Name, nftablesChain)
+			}
+		}
+	}
+	return rules.String()
+}
+
+// IsPortOpen returns true if the table of every input chain that drops
+// traffic has a rule accepting traffic on port/protocol. When no chain drops
+// traffic, a rule anywhere in the ruleset is enough.
+func (nft *nftables) IsPortOpen(port, protocol string) (bool, error) {
+	wanted, err := parsePortRange(port, "-")
+	if err != nil {
+		return false, err
+	}
+	ruleset, err := nft.ruleset()
+	if err != nil {
+		return false, err
+	}
+	inputs := ruleset.inputChains()
+	if len(inputs) == 0 {
+		return ruleset.acceptsPort(nil, protocol, wanted), nil
+	}
+	for _, input := range inputs {
+		if !ruleset.acceptsPort(input, protocol, wanted) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+func (nft *nftables) allow(port, protocol string) error {
+	wanted, err := parsePortRange(port, "-")
+	if err != nil {
+		return err
+	}
+	ruleset, err := nft.ruleset()
+	if err != nil {
+		return err
+	}
+	inputs := ruleset.inputChains()
+	if len(inputs) == 0 {
+		return fmt.Errorf("no nftables input chain drops traffic, there is nothing to open port %s in", port)
+	}
+	tables := map[string]bool{}
+	for _, input := range inputs {
+		if len(ruleset.jumpRules(input)) == 0 {
+			if ruleset.chain(input.Family, input.Table, nftablesChain) == nil && !tables[input.Family+" "+input.Table] {
+				if _, err := nft.run(nft.binPath, "add", "chain", input.Family, input.Table, nftablesChain); err != nil {
+					return fmt.Errorf("failed to create chain %s: %w", nftablesChain, err)
+				}
+			}
+			if _, err := nft.run(nft.binPath, "insert", "rule", input.Family, input.Table, input.Name, "jump", nftablesChain); err != nil {
+				return fmt.Errorf("failed to jump to chain %s: %w", nftablesChain, err)
+			}
+		}
+		// input chains of the same table share the nodeadm chain
+		if tables[input.Family+" "+input.Table] {
+			continue
+		}
+		tables[input.Family+" "+input.Table] = true
+		if ruleset.portRule(input.Family, input.Table, protocol, wanted) != nil {
+			continue
+		}
+		if _, err := nft.run(nft.binPath, "add", "rule", input.Family, input.Table, nftablesChain, protocol, "dport", port, "accept"); err != nil {
+			return fmt.Errorf("failed to allow port %s/%s in firewall: %w", port, protocol, err)
+		}
+	}
+	return nil
+}
+
+func (nft *nftables) remove(port, protocol string) error {
+	wanted, err := parsePortRange(port, "-")
+	if err != nil {
+		return err
+	}
+	ruleset, err := nft.ruleset()
+	if err != nil {
+		return err
+	}
+	for _, chain := range ruleset.nodeadmChains() {
+		if err := nft.removeFromChain(ruleset, chain, protocol, port, wanted); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// removeFromChain deletes the rule of the port from the nodeadm chain, then
+// the chain once it has no rules left.
+func (nft *nftables) removeFromChain(ruleset *nftRuleset, chain *nftChain, protocol, port string, wanted portRange) error {
+	var others int
+	for _, rule := range ruleset.rules() {
+		if rule.Family == chain.Family && rule.Table == chain.Table && rule.Chain == nftablesChain {
+			others++
+		}
+	}
+	if rule := ruleset.portRule(chain.Family, chain.Table, protocol, wanted); rule != nil {
+		if _, err := nft.run(nft.binPath, "delete", "rule", chain.Family, chain.Table, nftablesChain, "handle", strconv.Itoa(rule.Handle)); err != nil {
+			return fmt.Errorf("failed to remove port %s/%s from firewall: %w", port, protocol, err)
+		}
+		others--
+	}
+	if others > 0 {
+		return nil
+	}
+	for _, input := range ruleset.chains() {
+		if input.Family != chain.Family || input.Table != chain.Table {
+			continue
+		}
+		for _, rule := range ruleset.jumpRules(input) {
+			if _, err := nft.run(nft.binPath, "delete", "rule", rule.Family, rule.Table, rule.Chain, "handle", strconv.Itoa(rule.Handle)); err != nil {
+				return fmt.Errorf("failed to remove jump to chain %s: %w", nftablesChain, err)
+			}
+		}
+	}
+	if _, err := nft.run(nft.binPath, "delete", "chain", chain.Family, chain.Table, nftablesChain); err != nil {
+		return fmt.Errorf("failed to delete chain %s: %w", nftablesChain, err)
+	}
+	return nil
+}
+
+func (nft *nftables) ruleset() (*nftRuleset, error) {
+	out, err := nft.run(nft.binPath, "--json", "list", "ruleset")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list nftables ruleset: %w", err)
+	}
+	var ruleset nftRuleset
+	if err := json.Unmarshal([]byte(out), &ruleset); err != nil {
+		return nil, fmt.Errorf("failed to parse nftables ruleset: %w", err)
+	}
+	return &ruleset, nil
+}
+
+func (r *nftRuleset) chains() []*nftChain {
+	var chains []*nftChain
+	for _, object := range r.Nftables {
+		if object.Chain != nil {
+			chains = append(chains, object.Chain)
+		}
+	}
+	return chains
+}
+
+func (r *nftRuleset) rules() []*nftRule {
+	var rules []*nftRule
+	for _, object := range r.Nftables {
+		if object.Rule != nil {
+			rules = append(rules, object.Rule)
+		}
+	}
+	return rules
+}
+
+func (r *nftRuleset) chain(family, table, name string) *nftChain {
+	for _, chain := range r.chains() {
+		if chain.Family == family && chain.Table == table && chain.Name == name {
+			return chain
+		}
+	}
+	return nil
+}
+
+func (r *nftRuleset) nodeadmChains() []*nftChain {
+	var chains []*nftChain
+	for _, chain := range r.chains() {
+		if chain.Name == nftablesChain {
+			chains = append(chains, chain)
+		}
+	}
+	return chains
+}
+
+// inputChains returns the filter chains of the input hook that drop traffic,
+// either with their policy or a rule.
+func (r *nftRuleset) inputChains() []*nftChain {
+	var inputs []*nftChain
+	for _, chain := range r.chains() {
+		if chain.Type != "filter" || chain.Hook != nftInputHook {
+			continue
+		}
+		if chain.Policy == "drop" {
+			inputs = append(inputs, chain)
+			continue
+		}
+		for _, rule := range r.rules() {
+			if rule.Family == chain.Family && rule.Table == chain.Table && rule.Chain == chain.Name &&
+				(rule.hasVerdict("drop") || rule.hasVerdict("reject")) {
+				inputs = append(inputs, chain)
+				break
+			}
+		}
+	}
+	return inputs
+}
+
+// acceptsPort returns true if a rule of the input chain, or of a chain it
+// jumps to, accepts the ports from any address and interface. Without an input
+// chain, a rule of any chain is enough.
+func (r *nftRuleset) acceptsPort(input *nftChain, protocol string, ports portRange) bool {
+	var chains map[string]bool
+	if input != nil {
+		chains = r.reachableChains(input)
+	}
+	for _, rule := range r.rules() {
+		if input != nil && (rule.Family != input.Family || rule.Table != input.Table || !chains[rule.Chain]) {
+			continue
+		}
+		if !rule.hasVerdict("accept") || rule.restricted() {
+			continue
+		}
+		for _, accepted := range rule.ports(protocol) {
+			if accepted.contains(ports) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// reachableChains returns the names of the chain and of the chains of its
+// table it jumps or goes to, directly or through other chains.
+func (r *nftRuleset) reachableChains(chain *nftChain) map[string]bool {
+	chains := map[string]bool{chain.Name: true}
+	for added := true; added; {
+		added = false
+		for _, rule := range r.rules() {
+			if rule.Family != chain.Family || rule.Table != chain.Table || !chains[rule.Chain] || rule.restricted() {
+				continue
+			}
+			for _, target := range rule.targets() {
+				if !chains[target] {
+					chains[target] = true
+					added = true
+				}
+			}
+		}
+	}
+	return chains
+}
+
+// jumpRules returns the rules of the chain jumping to the nodeadm chain.
+func (r *nftRuleset) jumpRules(chain *nftChain) []*nftRule {
+	var jumps []*nftRule
+	for _, rule := range r.rules() {
+		if rule.Family != chain.Family || rule.Table != chain.Table || rule.Chain != chain.Name {
+			continue
+		}
+		for _, expr := range rule.Expr {
+			var jump struct {
+				Target string `json:"target"`
+			}
+			if raw, ok := expr["jump"]; ok && json.Unmarshal(raw, &jump) == nil && jump.Target == nftablesChain {
+				jumps = append(jumps, rule)
+			}
+		}
+	}
+	return jumps
+}
+
+// portRule returns the rule of the nodeadm chain accepting exactly the ports.
+func (r *nftRuleset) portRule(family, table, protocol string, ports portRange) *nftRule {
+	for _, rule := range r.rules() {
+		if rule.Family != family || rule.Table != table || rule.Chain != nftablesChain || !rule.hasVerdict("accept") {
+			continue
+		}
+		for _, accepted := range rule.ports(protocol) {
+			if accepted == ports {
+				return rule
+			}
+		}
+	}
+	return nil
+}
+
+// targets returns the chains the rule jumps or goes to.
+func (rule *nftRule) targets() []string {
+	var targets []string
+	for _, expr := range rule.Expr {
+		for _, verdict := range []string{"jump", "goto"} {
+			var target struct {
+				Target string `json:"target"`
+			}
+			if raw, ok := expr[verdict]; ok && json.Unmarshal(raw, &target) == nil {
+				targets = append(targets, target.Target)
+			}
+		}
+	}
+	return targets
+}
+
+// restricted returns true if the rule only matches some input interfaces or
+// source addresses.
+func (rule *nftRule) restricted() bool {
+	for _, expr := range rule.Expr {
+		raw, ok := expr["match"]
+		if !ok {
+			continue
+		}
+		var match nftMatch
+		if err := json.Unmarshal(raw, &match); err != nil {
+			continue
+		}
+		if meta := match.Left.Meta; meta != nil && (meta.Key == "iif" || meta.Key == "iifname") {
+			return true
+		}
+		if payload := match.Left.Payload; payload != nil && payload.Field == "saddr" {
+			return true
+		}
+	}
+	return false
+}
+
+func (rule *nftRule) hasVerdict(verdict string) bool {
+	for _, expr := range rule.Expr {
+		if _, ok := expr[verdict]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// ports returns the destination ports the rule matches for the protocol.
+func (rule *nftRule) ports(protocol string) []portRange {
+	var ports []portRange
+	for _, expr := range rule.Expr {
+		raw, ok := expr["match"]
+		if !ok {
+			continue
+		}
+		var match nftMatch
+		if err := json.Unmarshal(raw, &match); err != nil || match.Left.Payload == nil {
+			continue
+		}
+		if match.Op != "==" || match.Left.Payload.Protocol != protocol || match.Left.Payload.Field != "dport" {
+			continue
+		}
+		ports = append(ports, nftPorts(match.Right)...)
+	}
+	return ports
+}
+
+// nftPorts parses the right side of a match, a port, a range or a set of
+// both.
+func nftPorts(raw json.RawMessage) []portRange {
+	var port int
+	if json.Unmarshal(raw, &port) == nil {
+		return []portRange{{start: port, end: port}}
+	}
+	var object struct {
+		Range []int             `json:"range"`
+		Set   []json.RawMessage `json:"set"`
+	}
+	if json.Unmarshal(raw, &object) != nil {
+		return nil
+	}
+	if len(object.Range) == 2 {
+		return []portRange{{start: object.Range[0], end: object.Range[1]}}
+	}
+	var ports []portRange
+	for _, element := range object.Set {
+		ports = append(ports, nftPorts(element)...)
+	}
+	return ports
+}
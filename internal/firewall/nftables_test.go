@@ -0,0 +1,217 @@
+package firewall
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func newTestNftables(t *testing.T, ruleset string) (*nftables, *fakeRunner) {
+	runner := &fakeRunner{outputs: map[string]string{"nft --json list ruleset": readTestdata(t, ruleset)}}
+	return &nftables{binPath: "nft", run: runner.run}, runner
+}
+
+func TestNftablesIsEnabled(t *testing.T) {
+	testCases := []struct {
+		ruleset string
+		want    bool
+	}{
+		{ruleset: "nft-ruleset-drop.json", want: true},
+		{ruleset: "nft-ruleset-nodeadm.json", want: true},
+		{ruleset: "nft-ruleset-accept.json", want: false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.ruleset, func(t *testing.T) {
+			g := NewWithT(t)
+			nft, _ := newTestNftables(t, tc.ruleset)
+			g.Expect(nft.IsEnabled()).To(Equal(tc.want))
+		})
+	}
+
+	g := NewWithT(t)
+	g.Expect((&nftables{}).IsEnabled()).To(BeFalse())
+}
+
+func TestNftablesIsPortOpen(t *testing.T) {
+	testCases := []struct {
+		ruleset  string
+		port     string
+		protocol string
+		want     bool
+	}{
+		{ruleset: "nft-ruleset-drop.json", port: "22", protocol: "tcp", want: true},
+		{ruleset: "nft-ruleset-drop.json", port: "22", protocol: "udp", want: false},
+		{ruleset: "nft-ruleset-drop.json", port: "8472", protocol: "udp", want: true},
+		{ruleset: "nft-ruleset-drop.json", port: "4790", protocol: "udp", want: true},
+		{ruleset: "nft-ruleset-drop.json", port: "10250", protocol: "tcp", want: false},
+		{ruleset: "nft-ruleset-nodeadm.json", port: "10250", protocol: "tcp", want: true},
+		{ruleset: "nft-ruleset-nodeadm.json", port: "30000-32767", protocol: "tcp", want: true},
+		{ruleset: "nft-ruleset-nodeadm.json", port: "32768", protocol: "tcp", want: false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.ruleset+" "+tc.port+"/"+tc.protocol, func(t *testing.T) {
+			g := NewWithT(t)
+			nft, _ := newTestNftables(t, tc.ruleset)
+			g.Expect(nft.IsPortOpen(tc.port, tc.protocol)).To(Equal(tc.want))
+		})
+	}
+}
+
+func TestNftablesIsPortOpenRestricted(t *testing.T) {
+	g := NewWithT(t)
+	runner := &fakeRunner{outputs: map[string]string{"nft --json list ruleset": `{"nftables": [
+{"chain": {"family": "inet", "table": "filter", "name": "input", "handle": 1, "type": "filter", "hook": "input", "prio": 0, "policy": "drop"}},
+{"chain": {"family": "inet", "table": "filter", "name": "services", "handle": 2}},
+{"chain": {"family": "inet", "table": "filter", "name": "logging", "handle": 3}},
+{"rule": {"family": "inet", "table": "filter", "chain": "input", "handle": 4, "expr": [{"jump": {"target": "services"}}]}},
+{"rule": {"family": "inet", "table": "filter", "chain": "input", "handle": 5, "expr": [{"match": {"op": "==", "left": {"meta": {"key": "iifname"}}, "right": "lo"}}, {"match": {"op": "==", "left": {"payload": {"protocol": "tcp", "field": "dport"}}, "right": 10249}}, {"accept": null}]}},
+{"rule": {"family": "inet", "table": "filter", "chain": "input", "handle": 6, "expr": [{"match": {"op": "==", "left": {"payload": {"protocol": "ip", "field": "saddr"}}, "right": {"prefix": {"addr": "10.0.0.0", "len": 8}}}}, {"match": {"op": "==", "left": {"payload": {"protocol": "tcp", "field": "dport"}}, "right": 10257}}, {"accept": null}]}},
+{"rule": {"family": "inet", "table": "filter", "chain": "services", "handle": 7, "expr": [{"match": {"op": "==", "left": {"payload": {"protocol": "tcp", "field": "dport"}}, "right": 10250}}, {"accept": null}]}},
+{"rule": {"family": "inet", "table": "filter", "chain": "logging", "handle": 8, "expr": [{"match": {"op": "==", "left": {"payload": {"protocol": "tcp", "field": "dport"}}, "right": 10255}}, {"accept": null}]}}
+]}`}}
+	nft := &nftables{binPath: "nft", run: runner.run}
+
+	// in a chain the input chain jumps to
+	g.Expect(nft.IsPortOpen("10250", "tcp")).To(BeTrue())
+	// only from loopback, only from some addresses or in a chain the input
+	// chain doesn't jump to
+	g.Expect(nft.IsPortOpen("10249", "tcp")).To(BeFalse())
+	g.Expect(nft.IsPortOpen("10257", "tcp")).To(BeFalse())
+	g.Expect(nft.IsPortOpen("10255", "tcp")).To(BeFalse())
+}
+
+func TestNftablesAllow(t *testing.T) {
+	g := NewWithT(t)
+	nft, runner := newTestNftables(t, "nft-ruleset-drop.json")
+	g.Expect(nft.AllowTcpPortRange("30000", "32767")).To(Succeed())
+	g.Expect(runner.commands).To(Equal([]string{
+		"nft --json list ruleset",
+		"nft add chain inet filter nodeadm-input",
+		"nft insert rule inet filter input jump nodeadm-input",
+		"nft add rule inet filter nodeadm-input tcp dport 30000-32767 accept",
+	}))
+
+	nft, runner = newTestNftables(t, "nft-ruleset-nodeadm.json")
+	g.Expect(nft.AllowTcpPort("10250")).To(Succeed())
+	g.Expect(nft.AllowTcpPort("10256")).To(Succeed())
+	g.Expect(nft.AllowUdpPort("6081")).To(Succeed())
+	g.Expect(runner.commands).To(Equal([]string{
+		"nft --json list ruleset",
+		"nft --json list ruleset",
+		"nft add rule inet filter nodeadm-input tcp dport 10256 accept",
+		"nft --json list ruleset",
+		"nft add rule inet filter nodeadm-input udp dport 6081 accept",
+	}))
+
+	nft, _ = newTestNftables(t, "nft-ruleset-accept.json")
+	g.Expect(nft.AllowTcpPort("10250")).To(MatchError(ContainSubstring("no nftables input chain drops traffic")))
+}
+
+func TestNftablesSeparateFamilies(t *testing.T) {
+	g := NewWithT(t)
+	runner := &fakeRunner{outputs: map[string]string{"nft --json list ruleset": `{"nftables": [
+{"chain": {"family": "ip", "table": "filter", "name": "INPUT", "handle": 1, "type": "filter", "hook": "input", "prio": 0, "policy": "drop"}},
+{"chain": {"family": "ip6", "table": "filter", "name": "INPUT", "handle": 1, "type": "filter", "hook": "input", "prio": 0, "policy": "drop"}},
+{"chain": {"family": "ip", "table": "filter", "name": "nodeadm-input", "handle": 7}},
+{"rule": {"family": "ip", "table": "filter", "chain": "INPUT", "handle": 8, "expr": [{"jump": {"target": "nodeadm-input"}}]}},
+{"rule": {"family": "ip", "table": "filter", "chain": "nodeadm-input", "handle": 10, "expr": [{"match": {"op": "==", "left": {"payload": {"protocol": "tcp", "field": "dport"}}, "right": 10250}}, {"accept": null}]}}
+]}`}}
+	nft := &nftables{binPath: "nft", run: runner.run}
+
+	// only open for IPv4
+	g.Expect(nft.IsPortOpen("10250", "tcp")).To(BeFalse())
+
+	runner.commands = nil
+	g.Expect(nft.AllowTcpPort("10250")).To(Succeed())
+	g.Expect(runner.commands).To(Equal([]string{
+		"nft --json list ruleset",
+		"nft add chain ip6 filter nodeadm-input",
+		"nft insert rule ip6 filter INPUT jump nodeadm-input",
+		"nft add rule ip6 filter nodeadm-input tcp dport 10250 accept",
+	}))
+
+	runner.commands = nil
+	g.Expect(nft.RemoveTcpPort("10250")).To(Succeed())
+	g.Expect(runner.commands).To(Equal([]string{
+		"nft --json list ruleset",
+		"nft delete rule ip filter nodeadm-input handle 10",
+		"nft delete rule ip filter INPUT handle 8",
+		"nft delete chain ip filter nodeadm-input",
+	}))
+}
+
+func TestNftablesRemove(t *testing.T) {
+	g := NewWithT(t)
+	nft, runner := newTestNftables(t, "nft-ruleset-nodeadm.json")
+	g.Expect(nft.RemoveTcpPort("10250")).To(Succeed())
+	g.Expect(runner.commands).To(Equal([]string{
+		"nft --json list ruleset",
+		"nft delete rule inet filter nodeadm-input handle 9",
+	}))
+
+	// nothing to remove
+	nft, runner = newTestNftables(t, "nft-ruleset-drop.json")
+	g.Expect(nft.RemoveTcpPort("10250")).To(Succeed())
+	g.Expect(runner.commands).To(Equal([]string{"nft --json list ruleset"}))
+}
+
+func TestNftablesRemoveLastRule(t *testing.T) {
+	g := NewWithT(t)
+	runner := &fakeRunner{outputs: map[string]string{"nft --json list ruleset": `{"nftables": [
+{"chain": {"family": "inet", "table": "filter", "name": "input", "handle": 1, "type": "filter", "hook": "input", "prio": 0, "policy": "drop"}},
+{"chain": {"family": "inet", "table": "filter", "name": "nodeadm-input", "handle": 7}},
+{"rule": {"family": "inet", "table": "filter", "chain": "input", "handle": 8, "expr": [{"jump": {"target": "nodeadm-input"}}]}},
+{"rule": {"family": "inet", "table": "filter", "chain": "nodeadm-input", "handle": 10, "expr": [{"match": {"op": "==", "left": {"payload": {"protocol": "tcp", "field": "dport"}}, "right": {"range": [30000, 32767]}}}, {"accept": null}]}}
+]}`}}
+	nft := &nftables{binPath: "nft", run: runner.run}
+	g.Expect(nft.RemoveTcpPortRange("30000", "32767")).To(Succeed())
+	g.Expect(runner.commands).To(Equal([]string{
+		"nft --json list ruleset",
+		"nft delete rule inet filter nodeadm-input handle 10",
+		"nft delete rule inet filter input handle 8",
+		"nft delete chain inet filter nodeadm-input",
+	}))
+}
+
+func TestNftablesFlushRules(t *testing.T) {
+	g := NewWithT(t)
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "nftables.conf")
+	g.Expect(os.WriteFile(configPath, []byte("flush ruleset\n"), 0o600)).To(Succeed())
+	nft, _ := newTestNftables(t, "nft-ruleset-nodeadm.json")
+	nft.configPaths = []string{filepath.Join(dir, "sysconfig", "nftables.conf"), configPath}
+	nft.rulesPath = filepath.Join(dir, "nftables", "nodeadm.nft")
+
+	g.Expect(nft.FlushRules()).To(Succeed())
+	g.Expect(os.ReadFile(nft.rulesPath)).To(BeEquivalentTo(`# Ports opened by nodeadm, included by ` + configPath + `
+add table inet filter
+add chain inet filter nodeadm-input
+flush chain inet filter nodeadm-input
+add rule inet filter nodeadm-input tcp dport 10250 accept
+add rule inet filter nodeadm-input tcp dport 30000-32767 accept
+add chain inet filter input
+insert rule inet filter input jump nodeadm-input
+`))
+	include := `include "` + nft.rulesPath + `"`
+	g.Expect(os.ReadFile(configPath)).To(BeEquivalentTo("flush ruleset\n" + include + "\n"))
+
+	// the include is added once and the rules are emptied once removed
+	nft.run = (&fakeRunner{outputs: map[string]string{"nft --json list ruleset": readTestdata(t, "nft-ruleset-drop.json")}}).run
+	g.Expect(nft.FlushRules()).To(Succeed())
+	g.Expect(os.ReadFile(nft.rulesPath)).To(BeEquivalentTo("# Ports opened by nodeadm, included by " + configPath + "\n"))
+	g.Expect(os.ReadFile(configPath)).To(BeEquivalentTo("flush ruleset\n" + include + "\n"))
+}
+
+func TestNftablesFlushRulesNotSaved(t *testing.T) {
+	g := NewWithT(t)
+	dir := t.TempDir()
+	nft, runner := newTestNftables(t, "nft-ruleset-nodeadm.json")
+	nft.configPaths = []string{filepath.Join(dir, "nftables.conf")}
+	nft.rulesPath = filepath.Join(dir, "nftables", "nodeadm.nft")
+
+	g.Expect(nft.FlushRules()).To(Succeed())
+	g.Expect(runner.commands).To(BeEmpty())
+	g.Expect(filepath.Join(dir, "nftables")).NotTo(BeAnExistingFile())
+}
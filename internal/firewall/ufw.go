@@ -16,7 +16,7 @@ const (
 
 var (
 	ufwActiveRegex     = regexp.MustCompile(`.*Status: active*`)
-	ufwStatusRuleRegex = regexp.MustCompile(`(\d+)\s*/(\w+)\s+(ALLOW|DENY)\s+Anywhere`)
+	ufwStatusRuleRegex = regexp.MustCompile(`(\d+(?::\d+)?)\s*/(\w+)\s+(ALLOW|DENY)\s+Anywhere`)
 )
 
 type UncomplicatedFireWall struct {
@@ -74,6 +74,46 @@ func (ufw *UncomplicatedFireWall) AllowTcpPortRange(startPort, endPort string) e
 	return nil
 }
 
+// AllowUdpPort adds a rule to the firewall to open input UDP port
+func (ufw *UncomplicatedFireWall) AllowUdpPort(port string) error {
+	portAddCmd := exec.Command(ufw.binPath, "allow", fmt.Sprintf("%s/udp", port))
+	out, err := portAddCmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("failed to allow port %s/udp in firewall: %s, error: %v", port, out, err)
+	}
+	return nil
+}
+
+// RemoveTcpPort deletes the rule opening input port from the firewall
+func (ufw *UncomplicatedFireWall) RemoveTcpPort(port string) error {
+	portDeleteCmd := exec.Command(ufw.binPath, "delete", "allow", fmt.Sprintf("%s/tcp", port))
+	out, err := portDeleteCmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("failed to remove port %s from firewall: %s, error: %v", port, out, err)
+	}
+	return nil
+}
+
+// RemoveTcpPortRange deletes the rule opening the range of input port from the firewall
+func (ufw *UncomplicatedFireWall) RemoveTcpPortRange(startPort, endPort string) error {
+	portDeleteCmd := exec.Command(ufw.binPath, "delete", "allow", fmt.Sprintf("%s:%s/tcp", startPort, endPort))
+	out, err := portDeleteCmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("failed to remove ports from firewall: %s, error: %v", out, err)
+	}
+	return nil
+}
+
+// RemoveUdpPort deletes the rule opening input UDP port from the firewall
+func (ufw *UncomplicatedFireWall) RemoveUdpPort(port string) error {
+	portDeleteCmd := exec.Command(ufw.binPath, "delete", "allow", fmt.Sprintf("%s/udp", port))
+	out, err := portDeleteCmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("failed to remove port %s/udp from firewall: %s, error: %v", port, out, err)
+	}
+	return nil
+}
+
 // FlushRules flushes the rules and reloads the firewall to enforce the rules
 func (ufw *UncomplicatedFireWall) FlushRules() error {
 	// UFW activates the rules the moment its added, there is no need to flush them out to disk explicitly
@@ -84,6 +124,8 @@ func (ufw *UncomplicatedFireWall) FlushRules() error {
 // UFW doesn't have a way to query a port, so this function refreshes the active rules
 // maintained by firewall and checks if port/protocol is allowed.
 func (ufw *UncomplicatedFireWall) IsPortOpen(port, protocol string) (bool, error) {
+	// ufw writes ranges as start:end
+	port = strings.Replace(port, "-", ":", 1)
 	if len(ufw.rules) == 0 {
 		if err := ufw.refreshActiveRules(); err != nil {
 			return false, err
@@ -18,7 +18,9 @@ const (
 type Initer struct {
 	NodeProvider nodeprovider.NodeProvider
 	SkipPhases   []string
-	Logger       *zap.Logger
+	// Force joins the node even when its Node object belongs to another machine
+	Force  bool
+	Logger *zap.Logger
 }
 
 func (i *Initer) Run(ctx context.Context) error {
@@ -52,14 +54,14 @@ func (i *Initer) Run(ctx context.Context) error {
 		i.Logger.Info("Finished setting up system aspect", nameField)
 	}
 
-	if err := initDaemons(ctx, i.NodeProvider, i.SkipPhases, i.Logger); err != nil {
+	if err := initDaemons(ctx, i.NodeProvider, i.SkipPhases, i.Force, i.Logger); err != nil {
 		return err
 	}
 
 	return i.NodeProvider.Cleanup()
 }
 
-func initDaemons(ctx context.Context, nodeProvider nodeprovider.NodeProvider, skipPhases []string, logger *zap.Logger) error {
+func initDaemons(ctx context.Context, nodeProvider nodeprovider.NodeProvider, skipPhases []string, force bool, logger *zap.Logger) error {
 	if !slices.Contains(skipPhases, preprocessPhase) {
 		logger.Info("Configuring Pre-process daemons...")
 		if err := nodeProvider.PreProcessDaemon(ctx); err != nil {
@@ -85,6 +87,14 @@ func initDaemons(ctx context.Context, nodeProvider nodeprovider.NodeProvider, sk
 	}
 
 	if !slices.Contains(skipPhases, runPhase) {
+		identityChecker, checksIdentity := nodeProvider.(nodeprovider.NodeIdentityChecker)
+		if checksIdentity {
+			logger.Info("Checking node identity...")
+			if err := identityChecker.CheckNodeIdentity(ctx, force); err != nil {
+				return err
+			}
+		}
+
 		for _, daemon := range daemons {
 			nameField := zap.String("name", daemon.Name())
 
@@ -100,6 +110,12 @@ func initDaemons(ctx context.Context, nodeProvider nodeprovider.NodeProvider, sk
 			}
 			logger.Info("Finished post-launch tasks", nameField)
 		}
+
+		if checksIdentity {
+			if err := identityChecker.RecordNodeIdentity(ctx); err != nil {
+				return err
+			}
+		}
 	}
 	return nil
 }
@@ -6,29 +6,43 @@ import (
 
 	"go.uber.org/zap"
 
+	"github.com/aws/eks-hybrid/internal/artifact"
 	"github.com/aws/eks-hybrid/internal/aws"
 	"github.com/aws/eks-hybrid/internal/cni"
 	"github.com/aws/eks-hybrid/internal/containerd"
 	"github.com/aws/eks-hybrid/internal/creds"
+	"github.com/aws/eks-hybrid/internal/cri"
+	"github.com/aws/eks-hybrid/internal/crictl"
+	"github.com/aws/eks-hybrid/internal/crio"
+	"github.com/aws/eks-hybrid/internal/gvisor"
 	"github.com/aws/eks-hybrid/internal/iamauthenticator"
 	"github.com/aws/eks-hybrid/internal/iamrolesanywhere"
 	"github.com/aws/eks-hybrid/internal/imagecredentialprovider"
 	"github.com/aws/eks-hybrid/internal/iptables"
 	"github.com/aws/eks-hybrid/internal/kubectl"
 	"github.com/aws/eks-hybrid/internal/kubelet"
+	"github.com/aws/eks-hybrid/internal/nerdctl"
 	"github.com/aws/eks-hybrid/internal/packagemanager"
 	"github.com/aws/eks-hybrid/internal/ssm"
 	"github.com/aws/eks-hybrid/internal/tracker"
 )
 
 type Installer struct {
-	AwsSource          aws.Source
-	ContainerdSource   containerd.SourceName
+	AwsSource        aws.Source
+	ContainerdSource containerd.SourceName
+	// ContainerdVersion is the containerd major version to install, containerd.DefaultMajorVersion when empty.
+	ContainerdVersion string
+	// GvisorSource is the URL of the gVisor release to install.
+	GvisorSource string
+	// Runtime is the container runtime to install and configure the node with.
+	Runtime            cri.Runtime
 	PackageManager     *packagemanager.DistroPackageManager
 	CredentialProvider creds.CredentialProvider
 	SsmRegion          string
-	Tracker            *tracker.Tracker
-	Logger             *zap.Logger
+	// Components limits the artifacts to install. A nil selection installs all of them.
+	Components artifact.Selection
+	Tracker    *tracker.Tracker
+	Logger     *zap.Logger
 }
 
 func (i *Installer) Run(ctx context.Context) error {
@@ -57,20 +71,52 @@ func (i *Installer) Run(ctx context.Context) error {
 		return err
 	}
 
+	if err := i.installOptionalArtifacts(ctx); err != nil {
+		return err
+	}
+
 	i.Logger.Info("Finishing up install...")
 	return i.Tracker.Save()
 }
 
 func (i *Installer) installDistroPackages(ctx context.Context) error {
-	i.Logger.Info("Installing containerd...")
-	if err := containerd.Install(ctx, i.Tracker, i.PackageManager, i.ContainerdSource); err != nil {
+	if err := i.installRuntime(ctx); err != nil {
 		return err
 	}
 
+	if !i.Components.Includes(artifact.Iptables) {
+		i.Logger.Info("Skipping iptables install")
+		return nil
+	}
 	i.Logger.Info("Installing iptables...")
 	return iptables.Install(ctx, i.Tracker, i.PackageManager)
 }
 
+func (i *Installer) installRuntime(ctx context.Context) error {
+	runtime := i.Runtime
+	if runtime == "" {
+		runtime = cri.DefaultRuntime
+	}
+	i.Tracker.MarkRuntime(string(runtime))
+
+	containerdSource := i.ContainerdSource
+	if !i.Components.Includes(artifact.Containerd) || runtime != cri.Containerd {
+		containerdSource = containerd.ContainerdSourceNone
+	}
+	i.Logger.Info("Installing containerd...")
+	if err := containerd.Install(ctx, i.Tracker, i.PackageManager, containerdSource, i.ContainerdVersion); err != nil {
+		return err
+	}
+
+	if runtime == cri.Crio && i.Components.Includes(artifact.Crio) {
+		i.Logger.Info("Installing cri-o...")
+		if err := crio.Install(ctx, i.Tracker, i.PackageManager); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (i *Installer) installCredentialProcess(ctx context.Context) error {
 	switch i.CredentialProvider {
 	case creds.IamRolesAnywhereCredentialProvider:
@@ -101,46 +147,95 @@ func (i *Installer) installCredentialProcess(ctx context.Context) error {
 }
 
 func (i *Installer) installEksArtifacts(ctx context.Context) error {
-	i.Logger.Info("Installing kubelet...")
-	if err := kubelet.Install(ctx, kubelet.InstallOptions{
-		Tracker: i.Tracker,
-		Source:  i.AwsSource,
-		Logger:  i.Logger,
-	}); err != nil {
-		return err
+	if i.Components.Includes(artifact.Kubelet) {
+		i.Logger.Info("Installing kubelet...")
+		if err := kubelet.Install(ctx, kubelet.InstallOptions{
+			Tracker: i.Tracker,
+			Source:  i.AwsSource,
+			Logger:  i.Logger,
+		}); err != nil {
+			return err
+		}
 	}
 
-	i.Logger.Info("Installing kubectl...")
-	if err := kubectl.Install(ctx, kubectl.InstallOptions{
-		Tracker: i.Tracker,
-		Source:  i.AwsSource,
-		Logger:  i.Logger,
-	}); err != nil {
-		return err
+	if i.Components.Includes(artifact.Kubectl) {
+		i.Logger.Info("Installing kubectl...")
+		if err := kubectl.Install(ctx, kubectl.InstallOptions{
+			Tracker: i.Tracker,
+			Source:  i.AwsSource,
+			Logger:  i.Logger,
+		}); err != nil {
+			return err
+		}
 	}
 
-	i.Logger.Info("Installing cni-plugins...")
-	if err := cni.Install(ctx, cni.InstallOptions{
-		Tracker: i.Tracker,
-		Source:  i.AwsSource,
-		Logger:  i.Logger,
-	}); err != nil {
-		return err
+	if i.Components.Includes(artifact.CniPlugins) {
+		i.Logger.Info("Installing cni-plugins...")
+		if err := cni.Install(ctx, cni.InstallOptions{
+			Tracker: i.Tracker,
+			Source:  i.AwsSource,
+			Logger:  i.Logger,
+		}); err != nil {
+			return err
+		}
 	}
 
-	i.Logger.Info("Installing image credential provider...")
-	if err := imagecredentialprovider.Install(ctx, imagecredentialprovider.InstallOptions{
-		Tracker: i.Tracker,
-		Source:  i.AwsSource,
-		Logger:  i.Logger,
-	}); err != nil {
-		return err
+	if i.Components.Includes(artifact.ImageCredentialProvider) {
+		i.Logger.Info("Installing image credential provider...")
+		if err := imagecredentialprovider.Install(ctx, imagecredentialprovider.InstallOptions{
+			Tracker: i.Tracker,
+			Source:  i.AwsSource,
+			Logger:  i.Logger,
+		}); err != nil {
+			return err
+		}
+	}
+
+	if i.Components.Includes(artifact.IamAuthenticator) {
+		i.Logger.Info("Installing IAM authenticator...")
+		if err := iamauthenticator.Install(ctx, iamauthenticator.InstallOptions{
+			Tracker: i.Tracker,
+			Source:  i.AwsSource,
+			Logger:  i.Logger,
+		}); err != nil {
+			return err
+		}
 	}
+	return nil
+}
 
-	i.Logger.Info("Installing IAM authenticator...")
-	return iamauthenticator.Install(ctx, iamauthenticator.InstallOptions{
-		Tracker: i.Tracker,
-		Source:  i.AwsSource,
-		Logger:  i.Logger,
-	})
+func (i *Installer) installOptionalArtifacts(ctx context.Context) error {
+	if i.Components.Includes(artifact.Crictl) {
+		i.Logger.Info("Installing crictl...")
+		if err := crictl.Install(ctx, crictl.InstallOptions{
+			Tracker: i.Tracker,
+			Source:  i.AwsSource,
+			Logger:  i.Logger,
+		}); err != nil {
+			return err
+		}
+	}
+
+	if i.Components.Includes(artifact.Gvisor) {
+		i.Logger.Info("Installing gVisor...")
+		if err := gvisor.Install(ctx, gvisor.InstallOptions{
+			Tracker: i.Tracker,
+			Source:  gvisor.ReleaseSource{URL: i.GvisorSource},
+			Logger:  i.Logger,
+		}); err != nil {
+			return err
+		}
+	}
+
+	if i.Components.Includes(artifact.Nerdctl) {
+		i.Logger.Info("Installing nerdctl...")
+		if err := nerdctl.Install(ctx, nerdctl.InstallOptions{
+			Tracker: i.Tracker,
+			Source:  i.AwsSource,
+			Logger:  i.Logger,
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
 }
@@ -0,0 +1,312 @@
+package flows
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/pmezard/go-difflib/difflib"
+	"go.uber.org/zap"
+
+	"github.com/aws/eks-hybrid/internal/daemon"
+	"github.com/aws/eks-hybrid/internal/nodeprovider"
+)
+
+// Reconfigurer renders the daemon configuration for an already initialized
+// node, compares it with the files on disk and only restarts the daemons
+// whose configuration changed. The AWS credentials of the node are loaded,
+// not set up again, so the node isn't registered with SSM again.
+type Reconfigurer struct {
+	NodeProvider nodeprovider.NodeProvider
+	// DryRun writes the differences to Output without changing any file
+	// or restarting any daemon. The system aspects of the node are left as
+	// they are.
+	DryRun bool
+	Output io.Writer
+	Logger *zap.Logger
+}
+
+func (r *Reconfigurer) Run(ctx context.Context) error {
+	r.NodeProvider.PopulateNodeConfigDefaults()
+
+	if err := r.NodeProvider.ValidateConfig(); err != nil {
+		return err
+	}
+
+	// ConfigureAws registers the node with SSM again and restarts its agent,
+	// the daemons that keep the credentials up are reconfigured below instead
+	r.Logger.Info("Loading Aws config...")
+	if err := r.NodeProvider.LoadAws(ctx); err != nil {
+		return err
+	}
+
+	if err := r.NodeProvider.Enrich(ctx); err != nil {
+		return err
+	}
+
+	if err := r.NodeProvider.Validate(); err != nil {
+		return err
+	}
+
+	if !r.DryRun {
+		r.Logger.Info("Setting up system aspects...")
+		for _, aspect := range r.NodeProvider.GetAspects() {
+			if err := aspect.Setup(); err != nil {
+				return err
+			}
+		}
+	}
+
+	daemons, err := r.daemons()
+	if err != nil {
+		return err
+	}
+
+	for _, d := range daemons {
+		nameField := zap.String("name", d.Name())
+
+		changed, err := r.configureDaemon(d)
+		if err != nil {
+			return fmt.Errorf("reconfiguring daemon %s: %w", d.Name(), err)
+		}
+		if r.DryRun {
+			continue
+		}
+		if !changed {
+			r.Logger.Info("Daemon configuration is unchanged, skipping restart", nameField)
+			continue
+		}
+
+		r.Logger.Info("Restarting daemon with new configuration..", nameField)
+		if err := d.EnsureRunning(ctx); err != nil {
+			return err
+		}
+		if err := d.PostLaunch(); err != nil {
+			return err
+		}
+		r.Logger.Info("Daemon is running", nameField)
+	}
+
+	return r.NodeProvider.Cleanup()
+}
+
+// daemons returns the daemons that keep the AWS credentials of the node up,
+// followed by the node daemons.
+func (r *Reconfigurer) daemons() ([]daemon.Daemon, error) {
+	daemons, err := r.NodeProvider.GetDaemons()
+	if err != nil {
+		return nil, err
+	}
+	getter, ok := r.NodeProvider.(nodeprovider.PreProcessDaemonsGetter)
+	if !ok {
+		return daemons, nil
+	}
+	preProcessDaemons, err := getter.GetPreProcessDaemons()
+	if err != nil {
+		return nil, err
+	}
+	return append(preProcessDaemons, daemons...), nil
+}
+
+// configureDaemon configures the daemon and reports whether any of its
+// configuration files changed. Files the daemon manages that the
+// configuration no longer renders are removed. In dry-run mode the
+// configuration is only rendered and the differences are written to Output.
+func (r *Reconfigurer) configureDaemon(d daemon.Daemon) (bool, error) {
+	if r.DryRun {
+		return r.previewDaemon(d)
+	}
+	lister, ok := d.(daemon.ConfigFilesLister)
+	if !ok {
+		// without knowing the files involved, assume the configuration changed
+		return true, d.Configure()
+	}
+
+	paths := lister.ConfigFiles()
+	stale, err := staleFiles(d, paths)
+	if err != nil {
+		return false, err
+	}
+
+	before, err := snapshotFiles(paths)
+	if err != nil {
+		return false, err
+	}
+
+	// removed first, so the daemon validates the configuration without them
+	for _, path := range stale {
+		if err := os.Remove(path); err == nil {
+			r.Logger.Info("Removed configuration file that is no longer rendered", zap.String("path", path))
+		} else if !os.IsNotExist(err) {
+			return false, err
+		}
+	}
+
+	if err := d.Configure(); err != nil {
+		return false, err
+	}
+
+	after, err := snapshotFiles(paths)
+	if err != nil {
+		return false, err
+	}
+
+	diff, err := before.diff(after)
+	if err != nil {
+		return false, err
+	}
+	return len(diff) > 0, nil
+}
+
+// previewDaemon renders the configuration of the daemon in memory and writes
+// the differences with the files on disk to Output.
+func (r *Reconfigurer) previewDaemon(d daemon.Daemon) (bool, error) {
+	renderer, ok := d.(daemon.ConfigRenderer)
+	if !ok {
+		r.Logger.Info("Daemon does not support previewing configuration changes", zap.String("name", d.Name()))
+		return false, nil
+	}
+	rendered, err := renderer.RenderConfig()
+	if err != nil {
+		return false, err
+	}
+	paths := slices.Collect(maps.Keys(rendered))
+	if lister, ok := d.(daemon.ConfigFilesLister); ok {
+		// managed files that aren't rendered are removed
+		paths = append(paths, lister.ConfigFiles()...)
+	}
+	slices.Sort(paths)
+	paths = slices.Compact(paths)
+
+	before, err := snapshotFiles(paths)
+	if err != nil {
+		return false, err
+	}
+	diff, err := before.diff(renderedSnapshot(paths, rendered))
+	if err != nil {
+		return false, err
+	}
+	if len(diff) > 0 {
+		if _, err := fmt.Fprint(r.Output, diff); err != nil {
+			return false, err
+		}
+	}
+	return len(diff) > 0, nil
+}
+
+// staleFiles returns the files in paths the daemon no longer renders.
+func staleFiles(d daemon.Daemon, paths []string) ([]string, error) {
+	renderer, ok := d.(daemon.ConfigRenderer)
+	if !ok {
+		return nil, nil
+	}
+	rendered, err := renderer.RenderConfig()
+	if err != nil {
+		return nil, err
+	}
+	var stale []string
+	for _, path := range paths {
+		if rendered[path] == nil {
+			stale = append(stale, path)
+		}
+	}
+	return stale, nil
+}
+
+type fileSnapshot struct {
+	exists  bool
+	content []byte
+}
+
+// filesSnapshot holds the state of a set of files, keyed by path, in the order
+// they were requested.
+type filesSnapshot struct {
+	paths []string
+	files map[string]fileSnapshot
+}
+
+func snapshotFiles(paths []string) (*filesSnapshot, error) {
+	snapshot := &filesSnapshot{
+		paths: paths,
+		files: make(map[string]fileSnapshot, len(paths)),
+	}
+	for _, path := range paths {
+		content, err := os.ReadFile(path)
+		if os.IsNotExist(err) {
+			snapshot.files[path] = fileSnapshot{}
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		snapshot.files[path] = fileSnapshot{
+			exists:  true,
+			content: content,
+		}
+	}
+	return snapshot, nil
+}
+
+// renderedSnapshot holds the files rendered by a daemon, where a nil content
+// means the file is removed.
+func renderedSnapshot(paths []string, rendered map[string][]byte) *filesSnapshot {
+	snapshot := &filesSnapshot{
+		paths: paths,
+		files: make(map[string]fileSnapshot, len(paths)),
+	}
+	for _, path := range paths {
+		if content := rendered[path]; content != nil {
+			snapshot.files[path] = fileSnapshot{exists: true, content: content}
+		} else {
+			snapshot.files[path] = fileSnapshot{}
+		}
+	}
+	return snapshot
+}
+
+// diff returns a unified diff of all the files that differ between the two
+// snapshots. An empty string means nothing changed.
+func (s *filesSnapshot) diff(other *filesSnapshot) (string, error) {
+	var out bytes.Buffer
+	for _, path := range s.paths {
+		before, after := s.files[path], other.files[path]
+		if before.exists == after.exists && bytes.Equal(before.content, after.content) {
+			continue
+		}
+		fromFile, toFile := path, path
+		if !before.exists {
+			fromFile = "/dev/null"
+		}
+		if !after.exists {
+			toFile = "/dev/null"
+		}
+		diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+			A:        splitLines(before.content),
+			B:        splitLines(after.content),
+			FromFile: fromFile,
+			ToFile:   toFile,
+			Context:  3,
+		})
+		if err != nil {
+			return "", err
+		}
+		if diff == "" {
+			// the file was created or removed without any content
+			diff = fmt.Sprintf("--- %s\n+++ %s\n", fromFile, toFile)
+		}
+		out.WriteString(diff)
+	}
+	return out.String(), nil
+}
+
+func splitLines(content []byte) []string {
+	lines := strings.SplitAfter(string(content), "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
@@ -0,0 +1,234 @@
+package flows
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	. "github.com/onsi/gomega"
+	"go.uber.org/zap"
+
+	"github.com/aws/eks-hybrid/internal/api"
+	"github.com/aws/eks-hybrid/internal/daemon"
+	"github.com/aws/eks-hybrid/internal/system"
+)
+
+type fakeDaemon struct {
+	name  string
+	files map[string]string
+	// managed files the daemon no longer renders
+	stale    []string
+	restarts int
+}
+
+func (f *fakeDaemon) Configure() error {
+	for path, content := range f.files {
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (f *fakeDaemon) EnsureRunning(ctx context.Context) error {
+	f.restarts++
+	return nil
+}
+
+func (f *fakeDaemon) PostLaunch() error { return nil }
+func (f *fakeDaemon) Stop() error       { return nil }
+func (f *fakeDaemon) Name() string      { return f.name }
+
+func (f *fakeDaemon) RenderConfig() (map[string][]byte, error) {
+	rendered := map[string][]byte{}
+	for path, content := range f.files {
+		rendered[path] = []byte(content)
+	}
+	return rendered, nil
+}
+
+func (f *fakeDaemon) ConfigFiles() []string {
+	var paths []string
+	for path := range f.files {
+		paths = append(paths, path)
+	}
+	return append(paths, f.stale...)
+}
+
+func TestReconfigurerConfigureDaemon(t *testing.T) {
+	tests := []struct {
+		name        string
+		existing    string
+		rendered    string
+		dryRun      bool
+		wantChanged bool
+		wantDiff    string
+		wantContent string
+	}{
+		{
+			name:        "unchanged",
+			existing:    "a\nb\n",
+			rendered:    "a\nb\n",
+			wantChanged: false,
+			wantContent: "a\nb\n",
+		},
+		{
+			name:        "changed",
+			existing:    "a\nb\n",
+			rendered:    "a\nc\n",
+			wantChanged: true,
+			wantContent: "a\nc\n",
+		},
+		{
+			name:        "changed in dry run leaves file",
+			existing:    "a\nb\n",
+			rendered:    "a\nc\n",
+			dryRun:      true,
+			wantChanged: true,
+			wantDiff:    "@@ -1,2 +1,2 @@\n a\n-b\n+c\n",
+			wantContent: "a\nb\n",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			path := filepath.Join(t.TempDir(), "config")
+			g.Expect(os.WriteFile(path, []byte(tc.existing), 0o644)).To(Succeed())
+
+			out := &bytes.Buffer{}
+			r := &Reconfigurer{DryRun: tc.dryRun, Output: out, Logger: zap.NewNop()}
+			changed, err := r.configureDaemon(&fakeDaemon{files: map[string]string{path: tc.rendered}})
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(changed).To(Equal(tc.wantChanged))
+			g.Expect(out.String()).To(ContainSubstring(tc.wantDiff))
+
+			content, err := os.ReadFile(path)
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(string(content)).To(Equal(tc.wantContent))
+		})
+	}
+}
+
+func TestReconfigurerDryRunDoesNotCreateFiles(t *testing.T) {
+	g := NewWithT(t)
+	path := filepath.Join(t.TempDir(), "config")
+
+	out := &bytes.Buffer{}
+	r := &Reconfigurer{DryRun: true, Output: out, Logger: zap.NewNop()}
+	changed, err := r.configureDaemon(&fakeDaemon{files: map[string]string{path: "new\n"}})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(changed).To(BeTrue())
+	g.Expect(out.String()).To(ContainSubstring("--- /dev/null"))
+	g.Expect(path).NotTo(BeAnExistingFile())
+}
+
+type removingDaemon struct {
+	fakeDaemon
+	path string
+}
+
+func (d *removingDaemon) RenderConfig() (map[string][]byte, error) {
+	return map[string][]byte{d.path: nil}, nil
+}
+
+func TestReconfigurerDryRunRemovedFiles(t *testing.T) {
+	g := NewWithT(t)
+	path := filepath.Join(t.TempDir(), "config")
+	g.Expect(os.WriteFile(path, []byte("old\n"), 0o644)).To(Succeed())
+
+	out := &bytes.Buffer{}
+	r := &Reconfigurer{DryRun: true, Output: out, Logger: zap.NewNop()}
+	changed, err := r.configureDaemon(&removingDaemon{path: path})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(changed).To(BeTrue())
+	g.Expect(out.String()).To(ContainSubstring("+++ /dev/null"))
+	g.Expect(path).To(BeAnExistingFile())
+}
+
+type fakeNodeProvider struct {
+	preProcessDaemons []daemon.Daemon
+	daemons           []daemon.Daemon
+	configuredAws     bool
+}
+
+func (f *fakeNodeProvider) GetNodeConfig() *api.NodeConfig             { return &api.NodeConfig{} }
+func (f *fakeNodeProvider) PopulateNodeConfigDefaults()                {}
+func (f *fakeNodeProvider) ValidateConfig() error                      { return nil }
+func (f *fakeNodeProvider) PreProcessDaemon(ctx context.Context) error { return nil }
+func (f *fakeNodeProvider) GetDaemons() ([]daemon.Daemon, error)       { return f.daemons, nil }
+func (f *fakeNodeProvider) GetAspects() []system.SystemAspect          { return nil }
+func (f *fakeNodeProvider) Logger() *zap.Logger                        { return zap.NewNop() }
+func (f *fakeNodeProvider) Validate() error                            { return nil }
+func (f *fakeNodeProvider) Cleanup() error                             { return nil }
+func (f *fakeNodeProvider) Enrich(ctx context.Context) error           { return nil }
+func (f *fakeNodeProvider) LoadAws(ctx context.Context) error          { return nil }
+func (f *fakeNodeProvider) GetConfig() *aws.Config                     { return &aws.Config{} }
+
+func (f *fakeNodeProvider) ConfigureAws(ctx context.Context) error {
+	f.configuredAws = true
+	return nil
+}
+
+func (f *fakeNodeProvider) GetPreProcessDaemons() ([]daemon.Daemon, error) {
+	return f.preProcessDaemons, nil
+}
+
+func TestReconfigurerRun(t *testing.T) {
+	g := NewWithT(t)
+	dir := t.TempDir()
+	writeFile := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		g.Expect(os.WriteFile(path, []byte(content), 0o644)).To(Succeed())
+		return path
+	}
+
+	ssm := &fakeDaemon{name: "amazon-ssm-agent", files: map[string]string{writeFile("ssm-proxy.conf", "proxy\n"): "proxy\n"}}
+	signingHelper := &fakeDaemon{name: "aws_signing_helper_update", files: map[string]string{writeFile("signing-helper.service", "unit\n"): "unit\n"}}
+	containerd := &fakeDaemon{
+		name:  "containerd",
+		files: map[string]string{writeFile("config.toml", "config\n"): "config\n"},
+		stale: []string{writeFile("hosts.toml", "mirror\n")},
+	}
+	kubelet := &fakeDaemon{
+		name:  "kubelet",
+		files: map[string]string{writeFile("config.json", "old\n"): "new\n"},
+		stale: []string{writeFile("00-nodeadm.conf", "override\n")},
+	}
+	unchanged := &fakeDaemon{name: "unchanged", files: map[string]string{writeFile("unchanged.conf", "same\n"): "same\n"}}
+	provider := &fakeNodeProvider{
+		preProcessDaemons: []daemon.Daemon{ssm, signingHelper},
+		daemons:           []daemon.Daemon{containerd, kubelet, unchanged},
+	}
+
+	r := &Reconfigurer{NodeProvider: provider, Output: &bytes.Buffer{}, Logger: zap.NewNop()}
+	g.Expect(r.Run(context.Background())).To(Succeed())
+
+	g.Expect(provider.configuredAws).To(BeFalse())
+	g.Expect(ssm.restarts).To(Equal(0))
+	g.Expect(signingHelper.restarts).To(Equal(0))
+	g.Expect(unchanged.restarts).To(Equal(0))
+	g.Expect(containerd.restarts).To(Equal(1))
+	g.Expect(kubelet.restarts).To(Equal(1))
+	g.Expect(containerd.stale[0]).NotTo(BeAnExistingFile())
+	g.Expect(kubelet.stale[0]).NotTo(BeAnExistingFile())
+}
+
+func TestReconfigurerDryRunStaleFiles(t *testing.T) {
+	g := NewWithT(t)
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config")
+	stale := filepath.Join(dir, "stale")
+	g.Expect(os.WriteFile(path, []byte("same\n"), 0o644)).To(Succeed())
+	g.Expect(os.WriteFile(stale, []byte("old\n"), 0o644)).To(Succeed())
+
+	out := &bytes.Buffer{}
+	r := &Reconfigurer{DryRun: true, Output: out, Logger: zap.NewNop()}
+	changed, err := r.configureDaemon(&fakeDaemon{files: map[string]string{path: "same\n"}, stale: []string{stale}})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(changed).To(BeTrue())
+	g.Expect(out.String()).To(ContainSubstring("--- " + stale + "\n+++ /dev/null"))
+	g.Expect(stale).To(BeAnExistingFile())
+}
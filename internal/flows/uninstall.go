@@ -4,22 +4,30 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"slices"
 
 	"github.com/aws/aws-sdk-go-v2/config"
 	awsSsm "github.com/aws/aws-sdk-go-v2/service/ssm"
 	"go.uber.org/zap"
 
 	"github.com/aws/eks-hybrid/internal/containerd"
+	"github.com/aws/eks-hybrid/internal/crictl"
+	"github.com/aws/eks-hybrid/internal/crio"
 	"github.com/aws/eks-hybrid/internal/daemon"
+	"github.com/aws/eks-hybrid/internal/gvisor"
 	"github.com/aws/eks-hybrid/internal/iamauthenticator"
 	"github.com/aws/eks-hybrid/internal/iamrolesanywhere"
 	"github.com/aws/eks-hybrid/internal/imagecredentialprovider"
 	"github.com/aws/eks-hybrid/internal/iptables"
 	"github.com/aws/eks-hybrid/internal/kubectl"
 	"github.com/aws/eks-hybrid/internal/kubelet"
+	"github.com/aws/eks-hybrid/internal/nerdctl"
 	"github.com/aws/eks-hybrid/internal/packagemanager"
+	"github.com/aws/eks-hybrid/internal/proxy"
 	"github.com/aws/eks-hybrid/internal/ssm"
+	"github.com/aws/eks-hybrid/internal/system"
 	"github.com/aws/eks-hybrid/internal/tracker"
+	"github.com/aws/eks-hybrid/internal/trust"
 )
 
 const eksConfigDir = "/etc/eks"
@@ -34,6 +42,8 @@ type Uninstaller struct {
 	PackageManager *packagemanager.DistroPackageManager
 	Logger         *zap.Logger
 	CNIUninstall   CNIUninstall
+	// Aspects are torn down in reverse order, to revert what init changed on the host
+	Aspects []system.SystemAspect
 }
 
 func (u *Uninstaller) Run(ctx context.Context) error {
@@ -45,6 +55,10 @@ func (u *Uninstaller) Run(ctx context.Context) error {
 		return err
 	}
 
+	if err := u.teardownAspects(); err != nil {
+		return err
+	}
+
 	if err := u.cleanup(); err != nil {
 		return err
 	}
@@ -72,7 +86,7 @@ func (u *Uninstaller) uninstallDaemons(ctx context.Context) error {
 
 		ssmRegistration := ssm.NewSSMRegistration()
 		region := ssmRegistration.GetRegion()
-		opts := []func(*config.LoadOptions) error{}
+		opts := []func(*config.LoadOptions) error{trust.WithRootCAs}
 		if region != "" {
 			opts = append(opts, config.WithRegion(region))
 		}
@@ -109,7 +123,29 @@ func (u *Uninstaller) uninstallDaemons(ctx context.Context) error {
 			return err
 		}
 	}
-	return nil
+	if u.Artifacts.Crio {
+		u.Logger.Info("Uninstalling cri-o...")
+		if err := u.DaemonManager.StopDaemon(crio.CrioDaemonName); err != nil {
+			return err
+		}
+		if err := crio.Uninstall(ctx, u.PackageManager); err != nil {
+			return err
+		}
+	}
+	// these are written by init even when the runtime is not managed by nodeadm
+	if err := containerd.RemoveHostsConfig(); err != nil {
+		return err
+	}
+	if err := containerd.RemoveKernelModulesConfig(); err != nil {
+		return err
+	}
+	if err := crio.RemoveConfig(); err != nil {
+		return err
+	}
+	if err := proxy.RemoveDropIn(crio.CrioDaemonName); err != nil {
+		return err
+	}
+	return proxy.RemoveDropIn(containerd.ContainerdDaemonName)
 }
 
 func (u *Uninstaller) uninstallBinaries(ctx context.Context) error {
@@ -143,6 +179,24 @@ func (u *Uninstaller) uninstallBinaries(ctx context.Context) error {
 			return err
 		}
 	}
+	if u.Artifacts.Crictl {
+		u.Logger.Info("Uninstalling crictl...")
+		if err := crictl.Uninstall(); err != nil {
+			return err
+		}
+	}
+	if u.Artifacts.Nerdctl {
+		u.Logger.Info("Uninstalling nerdctl...")
+		if err := nerdctl.Uninstall(); err != nil {
+			return err
+		}
+	}
+	if u.Artifacts.Gvisor != "" {
+		u.Logger.Info("Uninstalling gVisor...")
+		if err := gvisor.Uninstall(); err != nil {
+			return err
+		}
+	}
 	if u.Artifacts.Iptables {
 		u.Logger.Info("Uninstalling iptables...")
 		if err := iptables.Uninstall(ctx, u.PackageManager); err != nil {
@@ -152,6 +206,16 @@ func (u *Uninstaller) uninstallBinaries(ctx context.Context) error {
 	return nil
 }
 
+func (u *Uninstaller) teardownAspects() error {
+	for _, aspect := range slices.Backward(u.Aspects) {
+		u.Logger.Info("Tearing down system aspect...", zap.String("name", aspect.Name()))
+		if err := aspect.Teardown(); err != nil {
+			return fmt.Errorf("tearing down system aspect %s: %w", aspect.Name(), err)
+		}
+	}
+	return nil
+}
+
 // cleanup removes directories or files that are not individually owned by single component
 func (u *Uninstaller) cleanup() error {
 	if err := u.PackageManager.Cleanup(); err != nil {
@@ -7,17 +7,22 @@ import (
 	"github.com/pkg/errors"
 	"go.uber.org/zap"
 
+	"github.com/aws/eks-hybrid/internal/artifact"
 	"github.com/aws/eks-hybrid/internal/aws"
 	"github.com/aws/eks-hybrid/internal/cni"
 	"github.com/aws/eks-hybrid/internal/containerd"
 	"github.com/aws/eks-hybrid/internal/creds"
+	"github.com/aws/eks-hybrid/internal/crictl"
+	"github.com/aws/eks-hybrid/internal/crio"
 	"github.com/aws/eks-hybrid/internal/daemon"
+	"github.com/aws/eks-hybrid/internal/gvisor"
 	"github.com/aws/eks-hybrid/internal/iamauthenticator"
 	"github.com/aws/eks-hybrid/internal/iamrolesanywhere"
 	"github.com/aws/eks-hybrid/internal/imagecredentialprovider"
 	"github.com/aws/eks-hybrid/internal/iptables"
 	"github.com/aws/eks-hybrid/internal/kubectl"
 	"github.com/aws/eks-hybrid/internal/kubelet"
+	"github.com/aws/eks-hybrid/internal/nerdctl"
 	"github.com/aws/eks-hybrid/internal/nodeprovider"
 	"github.com/aws/eks-hybrid/internal/packagemanager"
 	"github.com/aws/eks-hybrid/internal/ssm"
@@ -30,9 +35,13 @@ type Upgrader struct {
 	PackageManager     *packagemanager.DistroPackageManager
 	CredentialProvider creds.CredentialProvider
 	Artifacts          *tracker.InstalledArtifacts
-	DaemonManager      daemon.DaemonManager
-	SkipPhases         []string
-	Logger             *zap.Logger
+	// Components limits the installed artifacts to upgrade. A nil selection upgrades all of them.
+	Components    artifact.Selection
+	DaemonManager daemon.DaemonManager
+	SkipPhases    []string
+	// Force takes over the Node of the node name when it belongs to a different machine.
+	Force  bool
+	Logger *zap.Logger
 }
 
 func (u *Upgrader) Run(ctx context.Context) error {
@@ -54,7 +63,7 @@ func (u *Upgrader) Run(ctx context.Context) error {
 	if err := u.NodeProvider.Enrich(ctx); err != nil {
 		return err
 	}
-	if err := initDaemons(ctx, u.NodeProvider, u.SkipPhases, u.Logger); err != nil {
+	if err := initDaemons(ctx, u.NodeProvider, u.SkipPhases, u.Force, u.Logger); err != nil {
 		return err
 	}
 
@@ -66,14 +75,21 @@ func (u *Upgrader) upgradeDistroPackages(ctx context.Context) error {
 	if err := u.PackageManager.RefreshMetadataCache(ctx); err != nil {
 		return err
 	}
-	if u.Artifacts.Containerd != string(containerd.ContainerdSourceNone) {
+	if u.Artifacts.Containerd != string(containerd.ContainerdSourceNone) && u.Components.Includes(artifact.Containerd) {
 		u.Logger.Info("Upgrading containerd...")
 		if err := containerd.Upgrade(ctx, u.PackageManager); err != nil {
 			return err
 		}
 	}
 
-	if u.Artifacts.Iptables {
+	if u.Artifacts.Crio && u.Components.Includes(artifact.Crio) {
+		u.Logger.Info("Upgrading cri-o...")
+		if err := crio.Upgrade(ctx, u.PackageManager); err != nil {
+			return err
+		}
+	}
+
+	if u.Artifacts.Iptables && u.Components.Includes(artifact.Iptables) {
 		u.Logger.Info("Upgrading iptables...")
 		if err := iptables.Upgrade(ctx, u.PackageManager); err != nil {
 			return err
@@ -108,26 +124,60 @@ func (u *Upgrader) upgradeCredentialProvider(ctx context.Context) error {
 }
 
 func (u *Upgrader) upgradeEksArtifacts(ctx context.Context) error {
-	u.Logger.Info("Upgrading kubelet...")
-	if err := kubelet.Upgrade(ctx, u.AwsSource, u.Logger); err != nil {
-		return errors.Wrap(err, "failed to upgrade kubelet")
+	if u.Artifacts.Kubelet && u.Components.Includes(artifact.Kubelet) {
+		u.Logger.Info("Upgrading kubelet...")
+		if err := kubelet.Upgrade(ctx, u.AwsSource, u.Logger); err != nil {
+			return errors.Wrap(err, "failed to upgrade kubelet")
+		}
 	}
 
-	u.Logger.Info("Upgrading kubectl...")
-	if err := kubectl.Upgrade(ctx, u.AwsSource, u.Logger); err != nil {
-		return err
+	if u.Artifacts.Kubectl && u.Components.Includes(artifact.Kubectl) {
+		u.Logger.Info("Upgrading kubectl...")
+		if err := kubectl.Upgrade(ctx, u.AwsSource, u.Logger); err != nil {
+			return err
+		}
 	}
 
-	u.Logger.Info("Upgrading image credential provider...")
-	if err := imagecredentialprovider.Upgrade(ctx, u.AwsSource, u.Logger); err != nil {
-		return err
+	if u.Artifacts.ImageCredentialProvider && u.Components.Includes(artifact.ImageCredentialProvider) {
+		u.Logger.Info("Upgrading image credential provider...")
+		if err := imagecredentialprovider.Upgrade(ctx, u.AwsSource, u.Logger); err != nil {
+			return err
+		}
 	}
 
-	u.Logger.Info("Upgrading IAM authenticator...")
-	if err := iamauthenticator.Upgrade(ctx, u.AwsSource, u.Logger); err != nil {
-		return err
+	if u.Artifacts.IamAuthenticator && u.Components.Includes(artifact.IamAuthenticator) {
+		u.Logger.Info("Upgrading IAM authenticator...")
+		if err := iamauthenticator.Upgrade(ctx, u.AwsSource, u.Logger); err != nil {
+			return err
+		}
+	}
+
+	if u.Artifacts.CniPlugins && u.Components.Includes(artifact.CniPlugins) {
+		u.Logger.Info("Upgrading cni-plugins...")
+		if err := cni.Upgrade(ctx, u.AwsSource, u.Logger); err != nil {
+			return err
+		}
+	}
+
+	if u.Artifacts.Crictl && u.Components.Includes(artifact.Crictl) {
+		u.Logger.Info("Upgrading crictl...")
+		if err := crictl.Upgrade(ctx, u.AwsSource, u.Logger); err != nil {
+			return err
+		}
 	}
 
-	u.Logger.Info("Upgrading cni-plugins...")
-	return cni.Upgrade(ctx, u.AwsSource, u.Logger)
+	if u.Artifacts.Gvisor != "" && u.Components.Includes(artifact.Gvisor) {
+		u.Logger.Info("Upgrading gVisor...")
+		if err := gvisor.Upgrade(ctx, gvisor.ReleaseSource{URL: u.Artifacts.Gvisor}, u.Logger); err != nil {
+			return err
+		}
+	}
+
+	if u.Artifacts.Nerdctl && u.Components.Includes(artifact.Nerdctl) {
+		u.Logger.Info("Upgrading nerdctl...")
+		if err := nerdctl.Upgrade(ctx, u.AwsSource, u.Logger); err != nil {
+			return err
+		}
+	}
+	return nil
 }
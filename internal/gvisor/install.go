@@ -0,0 +1,100 @@
+package gvisor
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	"github.com/aws/eks-hybrid/internal/artifact"
+	"github.com/aws/eks-hybrid/internal/tracker"
+)
+
+const (
+	binDir            = "/usr/local/bin"
+	artifactFilePerms = 0o755
+)
+
+// binaries are the gVisor runtime and its containerd shim.
+var binaries = []string{"runsc", "containerd-shim-runsc-v1"}
+
+// InstallOptions contains options for installing gVisor
+type InstallOptions struct {
+	InstallRoot string
+	Tracker     *tracker.Tracker
+	Source      ReleaseSource
+	Logger      *zap.Logger
+}
+
+// Install installs runsc and its containerd shim in /usr/local/bin.
+func Install(ctx context.Context, opts InstallOptions) error {
+	for _, binary := range binaries {
+		if err := downloadFileWithRetries(ctx, opts, binary); err != nil {
+			return errors.Wrapf(err, "installing %s", binary)
+		}
+	}
+	opts.Tracker.MarkGvisor(opts.Source.URL)
+	return nil
+}
+
+func downloadFileWithRetries(ctx context.Context, opts InstallOptions, binary string) error {
+	// Retry up to 3 times to download and validate the checksum
+	var err error
+	for range 3 {
+		err = downloadFileTo(ctx, opts, binary)
+		if err == nil {
+			break
+		}
+		opts.Logger.Error("Downloading gVisor failed. Retrying...", zap.String("binary", binary), zap.Error(err))
+	}
+	return err
+}
+
+func downloadFileTo(ctx context.Context, opts InstallOptions, binary string) error {
+	source, err := opts.Source.GetBinary(ctx, binary)
+	if err != nil {
+		return err
+	}
+	defer source.Close()
+
+	if err := artifact.InstallFile(filepath.Join(opts.InstallRoot, binDir, binary), source, artifactFilePerms); err != nil {
+		return err
+	}
+
+	if !source.VerifyChecksum() {
+		return errors.Errorf("%s checksum mismatch: %v", binary, artifact.NewChecksumError(source))
+	}
+	return nil
+}
+
+// Uninstall removes the gVisor binaries.
+func Uninstall() error {
+	for _, binary := range binaries {
+		if err := os.RemoveAll(filepath.Join(binDir, binary)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Upgrade installs the binaries from the source gVisor was installed from, if
+// they changed.
+func Upgrade(ctx context.Context, source ReleaseSource, log *zap.Logger) error {
+	for _, binary := range binaries {
+		if err := upgradeBinary(ctx, source, binary, log); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func upgradeBinary(ctx context.Context, source ReleaseSource, binary string, log *zap.Logger) error {
+	src, err := source.GetBinary(ctx, binary)
+	if err != nil {
+		return errors.Wrapf(err, "getting %s source", binary)
+	}
+	defer src.Close()
+	return artifact.Upgrade(binary, filepath.Join(binDir, binary), src, artifactFilePerms, log)
+}
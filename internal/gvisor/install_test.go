@@ -0,0 +1,62 @@
+package gvisor_test
+
+import (
+	"context"
+	"crypto/sha512"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"go.uber.org/zap"
+
+	"github.com/aws/eks-hybrid/internal/gvisor"
+	"github.com/aws/eks-hybrid/internal/tracker"
+)
+
+func TestInstall(t *testing.T) {
+	if runtime.GOARCH != "amd64" && runtime.GOARCH != "arm64" {
+		t.Skipf("gVisor is not available for %s", runtime.GOARCH)
+	}
+	arch := map[string]string{"amd64": "x86_64", "arm64": "aarch64"}[runtime.GOARCH]
+	files := map[string][]byte{}
+	for _, binary := range []string{"runsc", "containerd-shim-runsc-v1"} {
+		data := []byte("test " + binary + " binary")
+		sum := sha512.Sum512(data)
+		files[fmt.Sprintf("/release/%s/%s", arch, binary)] = data
+		files[fmt.Sprintf("/release/%s/%s.sha512", arch, binary)] = []byte(hex.EncodeToString(sum[:]) + "  " + binary + "\n")
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, ok := files[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write(data)
+	}))
+	defer server.Close()
+
+	g := NewWithT(t)
+	tempDir := t.TempDir()
+	tr := &tracker.Tracker{Artifacts: &tracker.InstalledArtifacts{}}
+	source := gvisor.ReleaseSource{URL: server.URL + "/release"}
+
+	g.Expect(gvisor.Install(context.Background(), gvisor.InstallOptions{
+		InstallRoot: tempDir,
+		Tracker:     tr,
+		Source:      source,
+		Logger:      zap.NewNop(),
+	})).To(Succeed())
+
+	g.Expect(tr.Artifacts.Gvisor).To(Equal(source.URL))
+	for _, binary := range []string{"runsc", "containerd-shim-runsc-v1"} {
+		data, err := os.ReadFile(filepath.Join(tempDir, "/usr/local/bin", binary))
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(string(data)).To(Equal("test " + binary + " binary"))
+	}
+}
@@ -0,0 +1,51 @@
+package gvisor
+
+import (
+	"context"
+	"crypto/sha512"
+	"fmt"
+	"runtime"
+
+	"github.com/aws/eks-hybrid/internal/artifact"
+	"github.com/aws/eks-hybrid/internal/util"
+)
+
+// DefaultSource is the latest gVisor release.
+const DefaultSource = "https://storage.googleapis.com/gvisor/releases/release/latest"
+
+// releaseArch maps GOARCH to the architecture directories of gVisor releases.
+var releaseArch = map[string]string{
+	"amd64": "x86_64",
+	"arm64": "aarch64",
+}
+
+// ReleaseSource serves the binaries of a gVisor release, laid out like the
+// official releases: <url>/<arch>/<binary> with a <binary>.sha512 checksum.
+type ReleaseSource struct {
+	URL string
+}
+
+// GetBinary returns the release binary with its checksum.
+func (s ReleaseSource) GetBinary(ctx context.Context, name string) (artifact.Source, error) {
+	arch, ok := releaseArch[runtime.GOARCH]
+	if !ok {
+		return nil, fmt.Errorf("gVisor is not available for %s arch", runtime.GOARCH)
+	}
+	uri := fmt.Sprintf("%s/%s/%s", s.URL, arch, name)
+
+	obj, err := util.GetHttpFileReader(ctx, uri)
+	if err != nil {
+		return nil, fmt.Errorf("getting %s file reader: %w", name, err)
+	}
+	checksum, err := util.GetHttpFile(ctx, uri+".sha512")
+	if err != nil {
+		obj.Close()
+		return nil, fmt.Errorf("getting %s checksum: %w", name, err)
+	}
+	source, err := artifact.WithChecksum(obj, sha512.New(), checksum)
+	if err != nil {
+		obj.Close()
+		return nil, fmt.Errorf("getting %s with checksum: %w", name, err)
+	}
+	return source, nil
+}
@@ -54,7 +54,8 @@ type AWSConfig struct {
 	// PrivateKeyPath is the location on disk for the certificate's private key.
 	PrivateKeyPath string `json:"privateKeyPath,omitempty"`
 
-	// ProxyEnabled marks if proxy is enabled on the host
+	// ProxyEnabled makes the signing helper connect through the proxy set in the
+	// HTTP_PROXY/HTTPS_PROXY environment variables.
 	ProxyEnabled bool `json:"proxyEnabled,omitempty"`
 }
 
@@ -64,6 +65,7 @@ func WriteAWSConfig(cfg AWSConfig) error {
 		cfg.ConfigPath = DefaultAWSConfigPath
 	}
 
+	// hosts can also set the proxy in the environment of nodeadm instead of spec.proxy
 	if httpProxy, httpsProxy := os.Getenv("HTTP_PROXY"), os.Getenv("HTTPS_PROXY"); httpProxy != "" || httpsProxy != "" {
 		cfg.ProxyEnabled = true
 	}
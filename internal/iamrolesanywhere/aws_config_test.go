@@ -168,43 +168,32 @@ func TestWriteAWSConfigValidation(t *testing.T) {
 func TestWriteAWSConfigProxy(t *testing.T) {
 	testCases := []struct {
 		name          string
-		envVars       map[string]string
+		proxyEnabled  bool
+		httpsProxyEnv string
 		expectedProxy bool
 	}{
 		{
-			name: "proxy enabled via HTTP_PROXY",
-			envVars: map[string]string{
-				"HTTP_PROXY": "http://proxy.example.com:8080",
-			},
-			expectedProxy: true,
-		},
-		{
-			name: "proxy enabled via HTTPS_PROXY",
-			envVars: map[string]string{
-				"HTTPS_PROXY": "https://proxy.example.com:8080",
-			},
+			name:          "proxy enabled",
+			proxyEnabled:  true,
 			expectedProxy: true,
 		},
 		{
 			name:          "proxy disabled",
-			envVars:       map[string]string{},
+			proxyEnabled:  false,
 			expectedProxy: false,
 		},
+		{
+			name:          "proxy in environment",
+			proxyEnabled:  false,
+			httpsProxyEnv: "http://proxy.example.com:3128",
+			expectedProxy: true,
+		},
 	}
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			// Set up environment variables
-			for k, v := range tc.envVars {
-				os.Setenv(k, v)
-			}
-			defer func() {
-				// Clean up environment variables
-				for env := range tc.envVars {
-					os.Unsetenv(env)
-				}
-			}()
-
+			t.Setenv("HTTP_PROXY", "")
+			t.Setenv("HTTPS_PROXY", tc.httpsProxyEnv)
 			dir := t.TempDir()
 			path := filepath.Join(dir, "aws-config")
 
@@ -218,6 +207,7 @@ func TestWriteAWSConfigProxy(t *testing.T) {
 				SigningHelperBinPath: "/random/path",
 				CertificatePath:      "/etc/certificates/iam/pki/my-server.crt",
 				PrivateKeyPath:       "/etc/certificates/iam/pki/my-server.key",
+				ProxyEnabled:         tc.proxyEnabled,
 			}
 
 			err := iamrolesanywhere.WriteAWSConfig(cfg)
@@ -9,6 +9,7 @@ import (
 
 	"github.com/aws/eks-hybrid/internal/api"
 	"github.com/aws/eks-hybrid/internal/daemon"
+	"github.com/aws/eks-hybrid/internal/proxy"
 	"github.com/aws/eks-hybrid/internal/util"
 )
 
@@ -25,6 +26,12 @@ var (
 	signingHelperServiceTemplate = template.Must(template.New("").Parse(rawSigningHelperServiceTemplate))
 )
 
+var (
+	_ daemon.Daemon            = &SigningHelperDaemon{}
+	_ daemon.ConfigFilesLister = &SigningHelperDaemon{}
+	_ daemon.ConfigRenderer    = &SigningHelperDaemon{}
+)
+
 type SigningHelperDaemon struct {
 	daemonManager daemon.DaemonManager
 	node          *api.NodeConfig
@@ -47,12 +54,37 @@ func (s *SigningHelperDaemon) Configure() error {
 		return fmt.Errorf("writing aws_signing_helper_update service file %s: %v", EksHybridAwsCredentialsPath, err)
 	}
 
+	if err := proxy.WriteDropIn(DaemonName, s.node); err != nil {
+		return err
+	}
+
 	if err := s.daemonManager.DaemonReload(); err != nil {
 		return fmt.Errorf("reloading systemd daemon: %v", err)
 	}
 	return nil
 }
 
+// RenderConfig returns the unit file and the proxy drop-in written by Configure.
+func (s *SigningHelperDaemon) RenderConfig() (map[string][]byte, error) {
+	service, err := GenerateUpdateSystemdService(s.node)
+	if err != nil {
+		return nil, err
+	}
+	dropIn, err := proxy.RenderDropIn(s.node)
+	if err != nil {
+		return nil, err
+	}
+	return map[string][]byte{
+		SigningHelperServiceFilePath: service,
+		proxy.DropInPath(DaemonName): dropIn,
+	}, nil
+}
+
+// ConfigFiles returns the files written by Configure.
+func (s *SigningHelperDaemon) ConfigFiles() []string {
+	return []string{SigningHelperServiceFilePath, proxy.DropInPath(DaemonName)}
+}
+
 // EnsureRunning enables and starts the aws_signing_helper unit.
 func (s *SigningHelperDaemon) EnsureRunning(ctx context.Context) error {
 	err := s.daemonManager.EnableDaemon(s.Name())
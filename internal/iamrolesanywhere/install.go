@@ -10,6 +10,7 @@ import (
 	"go.uber.org/zap"
 
 	"github.com/aws/eks-hybrid/internal/artifact"
+	"github.com/aws/eks-hybrid/internal/proxy"
 	"github.com/aws/eks-hybrid/internal/tracker"
 )
 
@@ -88,6 +89,9 @@ func Uninstall() error {
 	if err := os.RemoveAll(SigningHelperServiceFilePath); err != nil {
 		return err
 	}
+	if err := proxy.RemoveDropIn(DaemonName); err != nil {
+		return err
+	}
 	if err := os.RemoveAll(path.Dir(EksHybridAwsCredentialsPath)); err != nil {
 		return err
 	}
@@ -0,0 +1,205 @@
+package iptables
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/aws/eks-hybrid/internal/api"
+	"github.com/aws/eks-hybrid/internal/validation"
+)
+
+// Backend is the kernel interface the iptables commands use.
+type Backend string
+
+const (
+	BackendLegacy Backend = "legacy"
+	BackendNft    Backend = "nft"
+)
+
+// alternatives are the commands switched together between backends.
+var alternatives = []string{"iptables", "ip6tables"}
+
+var alternativesDir = "/etc/alternatives"
+
+// runner runs a command and returns its standard output.
+type runner func(name string, args ...string) (string, error)
+
+func runCommand(name string, args ...string) (string, error) {
+	// #nosec G204 Subprocess launched with variable
+	cmd := exec.Command(name, args...)
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return string(out), fmt.Errorf("running %s: %s: %w", cmd.Args, strings.TrimSpace(stderr.String()), err)
+	}
+	return string(out), nil
+}
+
+// BackendStatus is the backend the iptables command uses and the rules
+// each backend holds.
+type BackendStatus struct {
+	Active Backend
+	// Rules counts the iptables and ip6tables rules of each backend.
+	Rules map[Backend]int
+}
+
+// Mixed returns true when both backends have rules. Rules in the backend
+// kube-proxy or the CNI don't use are still applied by the kernel, so
+// traffic can be dropped by rules nothing manages.
+func (s BackendStatus) Mixed() bool {
+	return s.Rules[BackendLegacy] > 0 && s.Rules[BackendNft] > 0
+}
+
+// Preferred returns the backend with the most rules, so kube-proxy and the
+// CNI add their rules next to the ones already on the host. The active
+// backend is kept when both have as many rules.
+func (s BackendStatus) Preferred() Backend {
+	other := BackendNft
+	if s.Active == BackendNft {
+		other = BackendLegacy
+	}
+	if s.Rules[other] > s.Rules[s.Active] {
+		return other
+	}
+	return s.Active
+}
+
+// DetectBackend returns the backend of the iptables command and counts the
+// rules of both backends.
+func DetectBackend() (BackendStatus, error) {
+	return detectBackend(runCommand)
+}
+
+func detectBackend(run runner) (BackendStatus, error) {
+	out, err := run(iptablesBinName, "--version")
+	if err != nil {
+		return BackendStatus{}, err
+	}
+	status := BackendStatus{Active: parseVersionBackend(out), Rules: map[Backend]int{}}
+	for _, backend := range []Backend{BackendLegacy, BackendNft} {
+		for _, command := range alternatives {
+			// distros that ship a single backend don't have the save command
+			// of the other one
+			out, err := run(fmt.Sprintf("%s-%s-save", command, backend))
+			if err != nil {
+				continue
+			}
+			status.Rules[backend] += countRules(out)
+		}
+	}
+	return status, nil
+}
+
+// parseVersionBackend parses the backend from `iptables --version`, like
+// `iptables v1.8.7 (nf_tables)`. Versions before 1.8 only have the legacy
+// backend and don't print it.
+func parseVersionBackend(version string) Backend {
+	if strings.Contains(version, "(nf_tables)") {
+		return BackendNft
+	}
+	return BackendLegacy
+}
+
+// countRules counts the rules of an iptables-save output.
+func countRules(save string) int {
+	var rules int
+	scanner := bufio.NewScanner(strings.NewReader(save))
+	for scanner.Scan() {
+		if strings.HasPrefix(scanner.Text(), "-A ") {
+			rules++
+		}
+	}
+	return rules
+}
+
+// Alternatives returns the commands the iptables alternatives point to, or
+// nil if the host doesn't use alternatives for them.
+func Alternatives() (map[string]string, error) {
+	paths := map[string]string{}
+	for _, name := range alternatives {
+		path, err := os.Readlink(filepath.Join(alternativesDir, name))
+		if os.IsNotExist(err) {
+			return nil, nil
+		} else if err != nil {
+			return nil, err
+		}
+		paths[name] = path
+	}
+	return paths, nil
+}
+
+// SetAlternatives points the iptables alternatives to the commands.
+func SetAlternatives(paths map[string]string) error {
+	command := "update-alternatives"
+	if _, err := exec.LookPath(command); err != nil {
+		// RHEL based distros name it alternatives
+		command = "alternatives"
+	}
+	for _, name := range alternatives {
+		path, ok := paths[name]
+		if !ok {
+			continue
+		}
+		if _, err := runCommand(command, "--set", name, path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// BackendAlternatives returns the commands of the backend for the iptables
+// alternatives.
+func BackendAlternatives(backend Backend) (map[string]string, error) {
+	paths := map[string]string{}
+	for _, name := range alternatives {
+		path, err := exec.LookPath(fmt.Sprintf("%s-%s", name, backend))
+		if err != nil {
+			return nil, fmt.Errorf("iptables %s backend is not installed: %w", backend, err)
+		}
+		paths[name] = path
+	}
+	return paths, nil
+}
+
+// ValidateBackend checks the host doesn't have rules in both iptables
+// backends and the iptables command uses the one with the rules.
+func ValidateBackend(ctx context.Context, informer validation.Informer, _ *api.NodeConfig) error {
+	if !isIptablesInstalled() {
+		return nil
+	}
+	name := "iptables-backend"
+	var err error
+	informer.Starting(ctx, name, "Validating iptables backend")
+	defer func() {
+		informer.Done(ctx, name, err)
+	}()
+
+	status, err := DetectBackend()
+	if err != nil {
+		return err
+	}
+	err = checkBackend(status)
+	return err
+}
+
+func checkBackend(status BackendStatus) error {
+	if status.Mixed() {
+		return validation.WithRemediation(
+			fmt.Errorf("both iptables backends have rules, %d in legacy and %d in nft", status.Rules[BackendLegacy], status.Rules[BackendNft]),
+			"Remove the rules of the backend the host doesn't use, then restart kube-proxy and the CNI so they recreate their rules. Kube-proxy and the CNI must use the backend of the iptables command on the host.",
+		)
+	}
+	if preferred := status.Preferred(); preferred != status.Active {
+		return validation.WithRemediation(
+			fmt.Errorf("iptables command uses the %s backend but the rules are in the %s backend", status.Active, preferred),
+			fmt.Sprintf("Run nodeadm init to switch the iptables alternatives to the %s backend, or switch them with update-alternatives.", preferred),
+		)
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+package iptables
+
+import (
+	"fmt"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+const kubeProxySave = `# Generated by iptables-nft-save v1.8.7 on Mon Oct  7 10:00:00 2024
+*filter
+:INPUT ACCEPT [0:0]
+:KUBE-FIREWALL - [0:0]
+-A INPUT -j KUBE-FIREWALL
+-A KUBE-FIREWALL -m mark --mark 0x8000/0x8000 -j DROP
+COMMIT
+`
+
+func TestDetectBackend(t *testing.T) {
+	testCases := []struct {
+		name      string
+		outputs   map[string]string
+		want      BackendStatus
+		preferred Backend
+		wantErr   string
+	}{
+		{
+			name: "nft with rules",
+			outputs: map[string]string{
+				"iptables":             "iptables v1.8.7 (nf_tables)\n",
+				"iptables-nft-save":    kubeProxySave,
+				"ip6tables-nft-save":   "",
+				"iptables-legacy-save": "",
+			},
+			want:      BackendStatus{Active: BackendNft, Rules: map[Backend]int{BackendLegacy: 0, BackendNft: 2}},
+			preferred: BackendNft,
+		},
+		{
+			name: "nft command with legacy rules",
+			outputs: map[string]string{
+				"iptables":              "iptables v1.8.7 (nf_tables)\n",
+				"iptables-legacy-save":  kubeProxySave,
+				"ip6tables-legacy-save": kubeProxySave,
+				"iptables-nft-save":     "",
+			},
+			want:      BackendStatus{Active: BackendNft, Rules: map[Backend]int{BackendLegacy: 4, BackendNft: 0}},
+			preferred: BackendLegacy,
+			wantErr:   "iptables command uses the nft backend but the rules are in the legacy backend",
+		},
+		{
+			name: "mixed",
+			outputs: map[string]string{
+				"iptables":             "iptables v1.8.4 (legacy)\n",
+				"iptables-legacy-save": kubeProxySave,
+				"iptables-nft-save":    kubeProxySave,
+			},
+			want:      BackendStatus{Active: BackendLegacy, Rules: map[Backend]int{BackendLegacy: 2, BackendNft: 2}},
+			preferred: BackendLegacy,
+			wantErr:   "both iptables backends have rules, 2 in legacy and 2 in nft",
+		},
+		{
+			name: "old iptables without save commands",
+			outputs: map[string]string{
+				"iptables": "iptables v1.6.1\n",
+			},
+			want:      BackendStatus{Active: BackendLegacy, Rules: map[Backend]int{}},
+			preferred: BackendLegacy,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			status, err := detectBackend(func(name string, args ...string) (string, error) {
+				out, ok := tc.outputs[name]
+				if !ok {
+					return "", fmt.Errorf("%s not found", name)
+				}
+				return out, nil
+			})
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(status).To(Equal(tc.want))
+			g.Expect(status.Preferred()).To(Equal(tc.preferred))
+			if tc.wantErr == "" {
+				g.Expect(checkBackend(status)).To(Succeed())
+			} else {
+				g.Expect(checkBackend(status)).To(MatchError(tc.wantErr))
+			}
+		})
+	}
+}
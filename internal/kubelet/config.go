@@ -28,7 +28,7 @@ import (
 	"sigs.k8s.io/yaml"
 
 	"github.com/aws/eks-hybrid/internal/api"
-	"github.com/aws/eks-hybrid/internal/containerd"
+	"github.com/aws/eks-hybrid/internal/cri"
 	"github.com/aws/eks-hybrid/internal/system"
 	"github.com/aws/eks-hybrid/internal/util"
 )
@@ -39,25 +39,28 @@ const (
 	kubeletConfigDir  = "config.json.d"
 	kubeletConfigPerm = 0o644
 
-	hybridNodeLabel            = "eks.amazonaws.com/compute-type=hybrid"
-	credentialProviderLabelKey = "eks.amazonaws.com/hybrid-credential-provider"
+	kubeletConfigDropInFile = "00-nodeadm.conf"
+
+	hybridNodeLabel = "eks.amazonaws.com/compute-type=hybrid"
+	// CredentialProviderLabelKey is the node label with the credential provider of hybrid nodes.
+	CredentialProviderLabelKey = "eks.amazonaws.com/hybrid-credential-provider"
 
 	hybridProviderIdPrefix = "eks-hybrid"
 )
 
 var nodeNameProviderIdRegexPattern = regexp.MustCompile(`^eks-hybrid:///[^/]+/[^/]+/(.+)$`)
 
-func (k *kubelet) writeKubeletConfig() error {
+func (k *kubelet) renderKubeletConfig() ([]configFile, error) {
 	kubeletVersion, err := GetKubeletVersion()
 	if err != nil {
-		return err
+		return nil, err
 	}
 	// tracking: https://github.com/kubernetes/enhancements/issues/3983
 	// for enabling drop-in configuration
 	if semver.Compare(kubeletVersion, "v1.29.0") < 0 {
-		return k.writeKubeletConfigToFile()
+		return k.renderKubeletConfigToFile()
 	} else {
-		return k.writeKubeletConfigToDir()
+		return k.renderKubeletConfigToDir()
 	}
 }
 
@@ -66,32 +69,35 @@ func (k *kubelet) writeKubeletConfig() error {
 // KubeletConfiguration types:
 // https://pkg.go.dev/k8s.io/kubelet/config/v1beta1#KubeletConfiguration
 type kubeletConfig struct {
-	Address                  string                           `json:"address"`
-	Authentication           k8skubelet.KubeletAuthentication `json:"authentication"`
-	Authorization            k8skubelet.KubeletAuthorization  `json:"authorization"`
-	CgroupDriver             string                           `json:"cgroupDriver"`
-	CgroupRoot               string                           `json:"cgroupRoot"`
-	ClusterDNS               []string                         `json:"clusterDNS"`
-	ClusterDomain            string                           `json:"clusterDomain"`
-	ContainerRuntimeEndpoint string                           `json:"containerRuntimeEndpoint"`
-	EvictionHard             map[string]string                `json:"evictionHard,omitempty"`
-	FeatureGates             map[string]bool                  `json:"featureGates"`
-	HairpinMode              string                           `json:"hairpinMode"`
-	KubeAPIBurst             *int                             `json:"kubeAPIBurst,omitempty"`
-	KubeAPIQPS               *int                             `json:"kubeAPIQPS,omitempty"`
-	KubeReserved             map[string]string                `json:"kubeReserved,omitempty"`
-	KubeReservedCgroup       *string                          `json:"kubeReservedCgroup,omitempty"`
-	Logging                  loggingConfiguration             `json:"logging"`
-	MaxPods                  int32                            `json:"maxPods,omitempty"`
-	ProtectKernelDefaults    bool                             `json:"protectKernelDefaults"`
-	ProviderID               *string                          `json:"providerID,omitempty"`
-	ReadOnlyPort             int                              `json:"readOnlyPort"`
-	RegisterWithTaints       []v1.Taint                       `json:"registerWithTaints,omitempty"`
-	SerializeImagePulls      bool                             `json:"serializeImagePulls"`
-	ServerTLSBootstrap       bool                             `json:"serverTLSBootstrap"`
-	SystemReservedCgroup     *string                          `json:"systemReservedCgroup,omitempty"`
-	TLSCipherSuites          []string                         `json:"tlsCipherSuites"`
-	ResolvConf               string                           `json:"resolvConf,omitempty"`
+	Address                  string                              `json:"address"`
+	Authentication           k8skubelet.KubeletAuthentication    `json:"authentication"`
+	Authorization            k8skubelet.KubeletAuthorization     `json:"authorization"`
+	CgroupDriver             string                              `json:"cgroupDriver"`
+	CgroupRoot               string                              `json:"cgroupRoot"`
+	ClusterDNS               []string                            `json:"clusterDNS"`
+	ClusterDomain            string                              `json:"clusterDomain"`
+	ContainerRuntimeEndpoint string                              `json:"containerRuntimeEndpoint"`
+	EvictionHard             map[string]string                   `json:"evictionHard,omitempty"`
+	FailSwapOn               *bool                               `json:"failSwapOn,omitempty"`
+	FeatureGates             map[string]bool                     `json:"featureGates"`
+	HairpinMode              string                              `json:"hairpinMode"`
+	KubeAPIBurst             *int                                `json:"kubeAPIBurst,omitempty"`
+	KubeAPIQPS               *int                                `json:"kubeAPIQPS,omitempty"`
+	KubeReserved             map[string]string                   `json:"kubeReserved,omitempty"`
+	KubeReservedCgroup       *string                             `json:"kubeReservedCgroup,omitempty"`
+	Logging                  loggingConfiguration                `json:"logging"`
+	MaxPods                  int32                               `json:"maxPods,omitempty"`
+	MemorySwap               *k8skubelet.MemorySwapConfiguration `json:"memorySwap,omitempty"`
+	PodLogsDir               string                              `json:"podLogsDir,omitempty"`
+	ProtectKernelDefaults    bool                                `json:"protectKernelDefaults"`
+	ProviderID               *string                             `json:"providerID,omitempty"`
+	ReadOnlyPort             int                                 `json:"readOnlyPort"`
+	RegisterWithTaints       []v1.Taint                          `json:"registerWithTaints,omitempty"`
+	SerializeImagePulls      bool                                `json:"serializeImagePulls"`
+	ServerTLSBootstrap       bool                                `json:"serverTLSBootstrap"`
+	SystemReservedCgroup     *string                             `json:"systemReservedCgroup,omitempty"`
+	TLSCipherSuites          []string                            `json:"tlsCipherSuites"`
+	ResolvConf               string                              `json:"resolvConf,omitempty"`
 	metav1.TypeMeta          `json:",inline"`
 }
 
@@ -130,7 +136,7 @@ func defaultKubeletSubConfig() kubeletConfig {
 		CgroupDriver:             "systemd",
 		CgroupRoot:               "/",
 		ClusterDomain:            "cluster.local",
-		ContainerRuntimeEndpoint: containerd.ContainerRuntimeEndpoint,
+		ContainerRuntimeEndpoint: cri.ContainerdEndpoint,
 		EvictionHard: map[string]string{
 			"memory.available":  "100Mi",
 			"nodefs.available":  "10%",
@@ -229,6 +235,48 @@ func (ksc *kubeletConfig) withResolvConf(resolvConfPath string) {
 	ksc.ResolvConf = resolvConfPath
 }
 
+func (ksc *kubeletConfig) withContainerRuntimeEndpoint(endpoint string) {
+	if endpoint != "" {
+		ksc.ContainerRuntimeEndpoint = endpoint
+	}
+}
+
+// withDataDirs relocates the kubelet root and pod logs directories. The
+// certificates stay in the default root so their path doesn't depend on the
+// node config.
+func (ksc *kubeletConfig) withDataDirs(cfg *api.NodeConfig, kubeletVersion string, flags map[string]string) error {
+	dataDirs := cfg.Spec.Instance.DataDirs
+	if dataDirs.KubeletRoot != "" {
+		flags["root-dir"] = dataDirs.KubeletRoot
+		flags["cert-dir"] = path.Dir(KubeletCurrentCertPath)
+	}
+	if dataDirs.PodLogs != "" {
+		// podLogsDir was added to the kubelet config in 1.29
+		if semver.Compare(kubeletVersion, "v1.29.0") < 0 {
+			return fmt.Errorf("spec.instance.dataDirs.podLogs requires kubelet v1.29 or later, found %s", kubeletVersion)
+		}
+		ksc.PodLogsDir = dataDirs.PodLogs
+	}
+	return nil
+}
+
+// withSwap lets pods use the node's swap when the swap mode is limited.
+func (ksc *kubeletConfig) withSwap(cfg *api.NodeConfig, kubeletVersion string) error {
+	if cfg.Spec.Instance.Swap.Mode != api.SwapModeLimited {
+		return nil
+	}
+	// LimitedSwap was added in 1.28, behind the NodeSwap feature gate until 1.30
+	if semver.Compare(kubeletVersion, "v1.28.0") < 0 {
+		return fmt.Errorf("spec.instance.swap.mode %s requires kubelet v1.28 or later, found %s", api.SwapModeLimited, kubeletVersion)
+	}
+	if semver.Compare(kubeletVersion, "v1.30.0") < 0 {
+		ksc.FeatureGates["NodeSwap"] = true
+	}
+	ksc.FailSwapOn = ptr.Bool(false)
+	ksc.MemorySwap = &k8skubelet.MemorySwapConfiguration{SwapBehavior: "LimitedSwap"}
+	return nil
+}
+
 func (ksc *kubeletConfig) withVersionToggles(kubeletVersion string, flags map[string]string) {
 	// TODO: remove when 1.26 is EOL
 	if semver.Compare(kubeletVersion, "v1.27.0") < 0 {
@@ -271,15 +319,23 @@ func (ksc *kubeletConfig) withCloudProvider(kubeletVersion string, cfg *api.Node
 func (ksc *kubeletConfig) withHybridCloudProvider(cfg *api.NodeConfig, flags map[string]string) {
 	flags["cloud-provider"] = ""
 	// provider ID needs to be specified when the cloud provider is external or empty string
-	ksc.ProviderID = ptr.String(getHybridProviderId(cfg))
+	ksc.ProviderID = ptr.String(HybridProviderID(cfg))
 	// hostname is overridden to the node name provided in the spec
 	flags["hostname-override"] = cfg.Status.Hybrid.NodeName
 }
 
+// withHybridNodeIp sets the node IP nodeadm selected for the hybrid node, the
+// --node-ip flag of the node config still takes precedence.
+func (ksc *kubeletConfig) withHybridNodeIp(cfg *api.NodeConfig, flags map[string]string) {
+	if cfg.Status.Hybrid.NodeIP != "" {
+		flags["node-ip"] = cfg.Status.Hybrid.NodeIP
+	}
+}
+
 func (ksc *kubeletConfig) withHybridNodeLabels(cfg *api.NodeConfig, flags map[string]string) {
 	var labels []string
 	labels = append(labels, hybridNodeLabel)
-	labels = append(labels, fmt.Sprintf("%s=%s", credentialProviderLabelKey, cfg.GetNodeType()))
+	labels = append(labels, fmt.Sprintf("%s=%s", CredentialProviderLabelKey, cfg.GetNodeType()))
 	flags["node-labels"] = strings.Join(labels, ",")
 }
 
@@ -382,11 +438,20 @@ func (k *kubelet) GenerateKubeletConfig() (*kubeletConfig, error) {
 		return nil, err
 	}
 
+	if err := kubeletConfig.withDataDirs(k.nodeConfig, kubeletVersion, k.flags); err != nil {
+		return nil, err
+	}
+	if err := kubeletConfig.withSwap(k.nodeConfig, kubeletVersion); err != nil {
+		return nil, err
+	}
+
+	kubeletConfig.withContainerRuntimeEndpoint(k.containerRuntimeEndpoint)
 	kubeletConfig.withVersionToggles(kubeletVersion, k.flags)
 
 	if k.nodeConfig.IsHybridNode() {
 		kubeletConfig.withHybridCloudProvider(k.nodeConfig, k.flags)
 		kubeletConfig.withHybridNodeLabels(k.nodeConfig, k.flags)
+		kubeletConfig.withHybridNodeIp(k.nodeConfig, k.flags)
 		if err := kubeletConfig.withHybridReservedResources(); err != nil {
 			return nil, err
 		}
@@ -409,58 +474,53 @@ func (k *kubelet) GenerateKubeletConfig() (*kubeletConfig, error) {
 	return &kubeletConfig, nil
 }
 
-// WriteConfig writes the kubelet config to a file.
+// renderKubeletConfigToFile renders the kubelet config to a single file.
 // This should only be used for kubelet versions < 1.28.
-func (k *kubelet) writeKubeletConfigToFile() error {
+func (k *kubelet) renderKubeletConfigToFile() ([]configFile, error) {
 	kubeletConfig, err := k.GenerateKubeletConfig()
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	var kubeletConfigBytes []byte
 	if len(k.nodeConfig.Spec.Kubelet.Config) > 0 {
 		mergedMap, err := util.DocumentMerge(kubeletConfig, k.nodeConfig.Spec.Kubelet.Config, mergo.WithOverride)
 		if err != nil {
-			return err
+			return nil, err
 		}
 		if kubeletConfigBytes, err = json.MarshalIndent(mergedMap, "", strings.Repeat(" ", 4)); err != nil {
-			return err
+			return nil, err
 		}
 	} else {
 		var err error
 		if kubeletConfigBytes, err = json.MarshalIndent(kubeletConfig, "", strings.Repeat(" ", 4)); err != nil {
-			return err
+			return nil, err
 		}
 	}
 
 	configPath := path.Join(kubeletConfigRoot, kubeletConfigFile)
 	k.flags["config"] = configPath
 
-	zap.L().Info("Writing kubelet config to file..", zap.String("path", configPath))
-	return util.WriteFileWithDir(configPath, kubeletConfigBytes, kubeletConfigPerm)
+	return []configFile{{path: configPath, content: kubeletConfigBytes, perm: kubeletConfigPerm}}, nil
 }
 
-// WriteKubeletConfigToDir writes nodeadm's generated kubelet config to the
-// standard config file and writes the user's provided config to a directory for
+// renderKubeletConfigToDir renders nodeadm's generated kubelet config to the
+// standard config file and the user's provided config to a directory for
 // drop-in support. This is only supported on kubelet versions >= 1.28. see:
 // https://kubernetes.io/docs/tasks/administer-cluster/kubelet-config-file/#kubelet-conf-d
-func (k *kubelet) writeKubeletConfigToDir() error {
+func (k *kubelet) renderKubeletConfigToDir() ([]configFile, error) {
 	kubeletConfig, err := k.GenerateKubeletConfig()
 	if err != nil {
-		return err
+		return nil, err
 	}
 	kubeletConfigBytes, err := json.MarshalIndent(kubeletConfig, "", strings.Repeat(" ", 4))
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	configPath := path.Join(kubeletConfigRoot, kubeletConfigFile)
 	k.flags["config"] = configPath
-
-	zap.L().Info("Writing kubelet config to file..", zap.String("path", configPath))
-	if err := util.WriteFileWithDir(configPath, kubeletConfigBytes, kubeletConfigPerm); err != nil {
-		return err
-	}
+	files := []configFile{{path: configPath, content: kubeletConfigBytes, perm: kubeletConfigPerm}}
 
 	if len(k.nodeConfig.Spec.Kubelet.Config) > 0 {
 		dirPath := path.Join(kubeletConfigRoot, kubeletConfigDir)
@@ -468,34 +528,32 @@ func (k *kubelet) writeKubeletConfigToDir() error {
 
 		zap.L().Info("Enabling kubelet config drop-in dir..")
 		k.setEnv("KUBELET_CONFIG_DROPIN_DIR_ALPHA", "on")
-		filePath := path.Join(dirPath, "00-nodeadm.conf")
+		filePath := path.Join(dirPath, kubeletConfigDropInFile)
 
 		// merge in default type metadata like kind and apiVersion in case the
 		// user has not specified this, as it is required to qualify a drop-in
 		// config as a valid KubeletConfiguration
 		userKubeletConfigMap, err := util.DocumentMerge(defaultKubeletSubConfig().TypeMeta, k.nodeConfig.Spec.Kubelet.Config)
 		if err != nil {
-			return err
+			return nil, err
 		}
 
-		zap.L().Info("Writing user kubelet config to drop-in file..", zap.String("path", filePath))
 		userKubeletConfigBytes, err := json.MarshalIndent(userKubeletConfigMap, "", strings.Repeat(" ", 4))
 		if err != nil {
-			return err
-		}
-		if err := util.WriteFileWithDir(filePath, userKubeletConfigBytes, kubeletConfigPerm); err != nil {
-			return err
+			return nil, err
 		}
+		files = append(files, configFile{path: filePath, content: userKubeletConfigBytes, perm: kubeletConfigPerm})
 	}
 
-	return nil
+	return files, nil
 }
 
 func getProviderId(availabilityZone, instanceId string) string {
 	return fmt.Sprintf("aws:///%s/%s", availabilityZone, instanceId)
 }
 
-func getHybridProviderId(cfg *api.NodeConfig) string {
+// HybridProviderID returns the providerID of the hybrid node.
+func HybridProviderID(cfg *api.NodeConfig) string {
 	return fmt.Sprintf("%s:///%s/%s/%s", hybridProviderIdPrefix, cfg.Spec.Cluster.Region, cfg.Spec.Cluster.Name, cfg.Status.Hybrid.NodeName)
 }
 
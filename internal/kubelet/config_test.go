@@ -8,6 +8,7 @@ import (
 
 	"github.com/aws/eks-hybrid/internal/api"
 	"github.com/aws/eks-hybrid/internal/containerd"
+	"github.com/aws/eks-hybrid/internal/cri"
 )
 
 func TestKubeletCredentialProvidersFeatureFlag(t *testing.T) {
@@ -179,3 +180,122 @@ func TestResolvConf(t *testing.T) {
 	kubeletConfig.withResolvConf(resolvConfPath)
 	assert.Equal(t, kubeletConfig.ResolvConf, resolvConfPath)
 }
+
+func TestContainerRuntimeEndpoint(t *testing.T) {
+	tests := []struct {
+		endpoint         string
+		expectedEndpoint string
+	}{
+		{endpoint: "", expectedEndpoint: cri.ContainerdEndpoint},
+		{endpoint: cri.ContainerdEndpoint, expectedEndpoint: cri.ContainerdEndpoint},
+		{endpoint: cri.CrioEndpoint, expectedEndpoint: cri.CrioEndpoint},
+	}
+
+	for _, test := range tests {
+		kubeletArguments := make(map[string]string)
+		kubeletConfig := defaultKubeletSubConfig()
+		kubeletConfig.withContainerRuntimeEndpoint(test.endpoint)
+		kubeletConfig.withVersionToggles("v1.26.0", kubeletArguments)
+		assert.Equal(t, test.expectedEndpoint, kubeletConfig.ContainerRuntimeEndpoint)
+		assert.Equal(t, test.expectedEndpoint, kubeletArguments["container-runtime-endpoint"])
+	}
+}
+
+func TestDataDirs(t *testing.T) {
+	tests := []struct {
+		name               string
+		kubeletVersion     string
+		dataDirs           api.DataDirOptions
+		expectedFlags      map[string]string
+		expectedPodLogsDir string
+		expectedErr        string
+	}{
+		{
+			name:           "defaults",
+			kubeletVersion: "v1.30.0",
+			expectedFlags:  map[string]string{},
+		},
+		{
+			name:           "relocated kubelet root",
+			kubeletVersion: "v1.30.0",
+			dataDirs:       api.DataDirOptions{KubeletRoot: "/mnt/data/kubelet"},
+			expectedFlags: map[string]string{
+				"root-dir": "/mnt/data/kubelet",
+				"cert-dir": "/var/lib/kubelet/pki",
+			},
+		},
+		{
+			name:               "relocated pod logs",
+			kubeletVersion:     "v1.29.0",
+			dataDirs:           api.DataDirOptions{PodLogs: "/mnt/data/pods"},
+			expectedFlags:      map[string]string{},
+			expectedPodLogsDir: "/mnt/data/pods",
+		},
+		{
+			name:           "pod logs on old kubelet",
+			kubeletVersion: "v1.28.5",
+			dataDirs:       api.DataDirOptions{PodLogs: "/mnt/data/pods"},
+			expectedErr:    "spec.instance.dataDirs.podLogs requires kubelet v1.29 or later, found v1.28.5",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			kubeletArguments := make(map[string]string)
+			kubeletConfig := defaultKubeletSubConfig()
+			err := kubeletConfig.withDataDirs(&api.NodeConfig{
+				Spec: api.NodeConfigSpec{
+					Instance: api.InstanceOptions{DataDirs: test.dataDirs},
+				},
+			}, test.kubeletVersion, kubeletArguments)
+			if test.expectedErr != "" {
+				assert.EqualError(t, err, test.expectedErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, test.expectedFlags, kubeletArguments)
+			assert.Equal(t, test.expectedPodLogsDir, kubeletConfig.PodLogsDir)
+		})
+	}
+}
+
+func TestSwap(t *testing.T) {
+	tests := []struct {
+		name               string
+		kubeletVersion     string
+		mode               api.SwapMode
+		expectedFailSwapOn *bool
+		expectedBehavior   string
+		expectedNodeSwap   bool
+		expectedErr        string
+	}{
+		{name: "disable", kubeletVersion: "v1.30.0", mode: api.SwapModeDisable},
+		{name: "default", kubeletVersion: "v1.30.0"},
+		{name: "limited", kubeletVersion: "v1.30.0", mode: api.SwapModeLimited, expectedFailSwapOn: ptr.Bool(false), expectedBehavior: "LimitedSwap"},
+		{name: "limited with feature gate", kubeletVersion: "v1.28.2", mode: api.SwapModeLimited, expectedFailSwapOn: ptr.Bool(false), expectedBehavior: "LimitedSwap", expectedNodeSwap: true},
+		{name: "limited on old kubelet", kubeletVersion: "v1.27.0", mode: api.SwapModeLimited, expectedErr: "spec.instance.swap.mode limited requires kubelet v1.28 or later, found v1.27.0"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			kubeletConfig := defaultKubeletSubConfig()
+			err := kubeletConfig.withSwap(&api.NodeConfig{
+				Spec: api.NodeConfigSpec{
+					Instance: api.InstanceOptions{Swap: api.SwapOptions{Mode: test.mode}},
+				},
+			}, test.kubeletVersion)
+			if test.expectedErr != "" {
+				assert.EqualError(t, err, test.expectedErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, test.expectedFailSwapOn, kubeletConfig.FailSwapOn)
+			if test.expectedBehavior == "" {
+				assert.Nil(t, kubeletConfig.MemorySwap)
+			} else {
+				assert.Equal(t, test.expectedBehavior, kubeletConfig.MemorySwap.SwapBehavior)
+			}
+			assert.Equal(t, test.expectedNodeSwap, kubeletConfig.FeatureGates["NodeSwap"])
+		})
+	}
+}
@@ -2,54 +2,128 @@ package kubelet
 
 import (
 	"context"
+	"io/fs"
+	"path"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
+	"go.uber.org/zap"
 
 	"github.com/aws/eks-hybrid/internal/api"
 	"github.com/aws/eks-hybrid/internal/daemon"
+	"github.com/aws/eks-hybrid/internal/proxy"
+	"github.com/aws/eks-hybrid/internal/util"
 )
 
 const KubeletDaemonName = "kubelet"
 
-var _ daemon.Daemon = &kubelet{}
+var (
+	_ daemon.Daemon            = &kubelet{}
+	_ daemon.ConfigFilesLister = &kubelet{}
+	_ daemon.ConfigRenderer    = &kubelet{}
+)
+
+// configFile is a file rendered by the kubelet configuration.
+type configFile struct {
+	path    string
+	content []byte
+	perm    fs.FileMode
+}
 
 type kubelet struct {
 	daemonManager daemon.DaemonManager
 	awsConfig     *aws.Config
 	nodeConfig    *api.NodeConfig
+	// CRI socket of the node's container runtime
+	containerRuntimeEndpoint string
 	// environment variables to write for kubelet
 	environment map[string]string
 	// kubelet config flags without leading dashes
 	flags map[string]string
 }
 
-func NewKubeletDaemon(daemonManager daemon.DaemonManager, cfg *api.NodeConfig, awsConfig *aws.Config) daemon.Daemon {
+func NewKubeletDaemon(daemonManager daemon.DaemonManager, cfg *api.NodeConfig, awsConfig *aws.Config, containerRuntimeEndpoint string) daemon.Daemon {
 	return &kubelet{
-		daemonManager: daemonManager,
-		nodeConfig:    cfg,
-		awsConfig:     awsConfig,
-		environment:   make(map[string]string),
-		flags:         make(map[string]string),
+		daemonManager:            daemonManager,
+		nodeConfig:               cfg,
+		awsConfig:                awsConfig,
+		containerRuntimeEndpoint: containerRuntimeEndpoint,
+		environment:              make(map[string]string),
+		flags:                    make(map[string]string),
 	}
 }
 
 func (k *kubelet) Configure() error {
-	if err := k.writeKubeletConfig(); err != nil {
+	files, err := k.renderConfigFiles()
+	if err != nil {
 		return err
 	}
-	if err := k.writeKubeconfig(); err != nil {
-		return err
+	for _, file := range files {
+		zap.L().Info("Writing kubelet config to file..", zap.String("path", file.path))
+		if err := util.WriteFileWithDir(file.path, file.content, file.perm); err != nil {
+			return err
+		}
 	}
-	if err := k.writeImageCredentialProviderConfig(); err != nil {
+	if err := proxy.WriteDropIn(KubeletDaemonName, k.nodeConfig); err != nil {
 		return err
 	}
-	if err := writeClusterCaCert(k.nodeConfig.Spec.Cluster.CertificateAuthority); err != nil {
-		return err
+	return nil
+}
+
+func (k *kubelet) RenderConfig() (map[string][]byte, error) {
+	files, err := k.renderConfigFiles()
+	if err != nil {
+		return nil, err
 	}
-	if err := k.writeKubeletEnvironment(); err != nil {
-		return err
+	rendered := make(map[string][]byte, len(files)+1)
+	for _, file := range files {
+		rendered[file.path] = file.content
+	}
+	dropIn, err := proxy.RenderDropIn(k.nodeConfig)
+	if err != nil {
+		return nil, err
+	}
+	rendered[proxy.DropInPath(KubeletDaemonName)] = dropIn
+	return rendered, nil
+}
+
+func (k *kubelet) renderConfigFiles() ([]configFile, error) {
+	files, err := k.renderKubeletConfig()
+	if err != nil {
+		return nil, err
+	}
+	kubeconfig, err := k.renderKubeconfig()
+	if err != nil {
+		return nil, err
+	}
+	imageCredentialProviderConfig, err := k.renderImageCredentialProviderConfig()
+	if err != nil {
+		return nil, err
+	}
+	files = append(files,
+		kubeconfig,
+		imageCredentialProviderConfig,
+		renderClusterCaCert(k.nodeConfig.Spec.Cluster.CertificateAuthority),
+		// rendered last, with the flags set by the other files
+		k.renderKubeletEnvironment(),
+	)
+	return files, nil
+}
+
+func (k *kubelet) ConfigFiles() []string {
+	// kubelet writes the kubeconfig of outpost nodes once it bootstraps
+	kubeconfig := kubeconfigPath
+	if k.nodeConfig.IsOutpostNode() {
+		kubeconfig = kubeconfigBootstrapPath
+	}
+	return []string{
+		path.Join(kubeletConfigRoot, kubeletConfigFile),
+		path.Join(kubeletConfigRoot, kubeletConfigDir, kubeletConfigDropInFile),
+		kubeconfig,
+		imageCredentialProviderConfigPath,
+		caCertificatePath,
+		kubeletEnvironmentFilePath,
+		proxy.DropInPath(KubeletDaemonName),
 	}
-	return nil
 }
 
 func (k *kubelet) EnsureRunning(ctx context.Context) error {
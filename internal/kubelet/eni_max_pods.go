@@ -10,6 +10,7 @@ import (
 	"github.com/aws/aws-sdk-go-v2/service/ec2"
 	"go.uber.org/zap"
 
+	"github.com/aws/eks-hybrid/internal/trust"
 	"github.com/aws/eks-hybrid/internal/util"
 )
 
@@ -53,7 +54,7 @@ func init() {
 //	# of ENI * (# of IPv4 per ENI - 1) + 2
 func CalcMaxPods(awsRegion, instanceType string) int32 {
 	zap.L().Info("calculate the max pod for instance type", zap.String("instanceType", instanceType))
-	cfg, err := config.LoadDefaultConfig(context.Background(), config.WithRegion(awsRegion))
+	cfg, err := config.LoadDefaultConfig(context.Background(), trust.WithRootCAs, config.WithRegion(awsRegion))
 	if err != nil {
 		zap.L().Warn("error loading AWS SDK config when calculating the max pod, setting it to default value", zap.Error(err))
 		return defaultMaxPods
@@ -2,9 +2,8 @@ package kubelet
 
 import (
 	"fmt"
+	"sort"
 	"strings"
-
-	"github.com/aws/eks-hybrid/internal/util"
 )
 
 const (
@@ -12,16 +11,18 @@ const (
 	kubeletArgsEnvironmentName = "NODEADM_KUBELET_ARGS"
 )
 
-// Write environment variables needed for kubelet runtime. This should be the
+// Render environment variables needed for kubelet runtime. This should be the
 // last method called on the kubelet object so that environment side effects of
 // other methods are properly recorded
-func (k *kubelet) writeKubeletEnvironment() error {
+func (k *kubelet) renderKubeletEnvironment() configFile {
 	// transform kubelet flags into a single string and write them to the
 	// kubelet environment variable
 	var kubeletFlags []string
 	for flag, value := range k.flags {
 		kubeletFlags = append(kubeletFlags, fmt.Sprintf("--%s=%s", flag, value))
 	}
+	// keep the rendered file stable across runs so that changes can be detected
+	sort.Strings(kubeletFlags)
 	// append user-provided flags at the end to give them precedence
 	kubeletFlags = append(kubeletFlags, k.nodeConfig.Spec.Kubelet.Flags...)
 	// expose these flags via an environment variable scoped to nodeadm
@@ -31,7 +32,8 @@ func (k *kubelet) writeKubeletEnvironment() error {
 	for eKey, eValue := range k.environment {
 		kubeletEnvironment = append(kubeletEnvironment, fmt.Sprintf(`%s="%s"`, eKey, eValue))
 	}
-	return util.WriteFileWithDir(kubeletEnvironmentFilePath, []byte(strings.Join(kubeletEnvironment, "\n")), kubeletConfigPerm)
+	sort.Strings(kubeletEnvironment)
+	return configFile{path: kubeletEnvironmentFilePath, content: []byte(strings.Join(kubeletEnvironment, "\n")), perm: kubeletConfigPerm}
 }
 
 // Add values to the environment variables map in a terse manner
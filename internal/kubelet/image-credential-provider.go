@@ -13,7 +13,6 @@ import (
 	"golang.org/x/mod/semver"
 
 	"github.com/aws/eks-hybrid/internal/api"
-	"github.com/aws/eks-hybrid/internal/util"
 )
 
 const (
@@ -34,7 +33,7 @@ var (
 	imageCredentialProviderConfigPath                      = path.Join(imageCredentialProviderRoot, imageCredentialProviderConfig)
 )
 
-func (k *kubelet) writeImageCredentialProviderConfig() error {
+func (k *kubelet) renderImageCredentialProviderConfig() (configFile, error) {
 	// fallback default for image credential provider binary if not overridden
 	ecrCredentialProviderBinPath := path.Join(imageCredentialProviderRoot, "ecr-credential-provider")
 	if binPath, set := os.LookupEnv(ecrCredentialProviderBinPathEnvironmentName); set {
@@ -42,18 +41,18 @@ func (k *kubelet) writeImageCredentialProviderConfig() error {
 		ecrCredentialProviderBinPath = binPath
 	}
 	if err := ensureCredentialProviderBinaryExists(ecrCredentialProviderBinPath); err != nil {
-		return err
+		return configFile{}, err
 	}
 
 	config, err := generateImageCredentialProviderConfig(k.nodeConfig, ecrCredentialProviderBinPath)
 	if err != nil {
-		return err
+		return configFile{}, err
 	}
 
 	k.flags["image-credential-provider-bin-dir"] = path.Dir(ecrCredentialProviderBinPath)
 	k.flags["image-credential-provider-config"] = imageCredentialProviderConfigPath
 
-	return util.WriteFileWithDir(imageCredentialProviderConfigPath, config, imageCredentialProviderPerm)
+	return configFile{path: imageCredentialProviderConfigPath, content: config, perm: imageCredentialProviderPerm}, nil
 }
 
 type imageCredentialProviderTemplateVars struct {
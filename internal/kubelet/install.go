@@ -13,6 +13,7 @@ import (
 	"go.uber.org/zap"
 
 	"github.com/aws/eks-hybrid/internal/artifact"
+	"github.com/aws/eks-hybrid/internal/proxy"
 	"github.com/aws/eks-hybrid/internal/tracker"
 )
 
@@ -111,6 +112,7 @@ func Uninstall(opts UninstallOptions) error {
 	pathsToRemove := []string{
 		filepath.Join(opts.InstallRoot, BinPath),
 		filepath.Join(opts.InstallRoot, UnitPath),
+		filepath.Join(opts.InstallRoot, proxy.DropInPath(KubeletDaemonName)),
 		filepath.Join(opts.InstallRoot, kubeconfigPath),
 		filepath.Join(opts.InstallRoot, path.Dir(kubeletConfigRoot)),
 		filepath.Join(opts.InstallRoot, KubeletCurrentCertPath),
@@ -12,7 +12,6 @@ import (
 
 	"github.com/aws/eks-hybrid/internal/api"
 	"github.com/aws/eks-hybrid/internal/iamauthenticator"
-	"github.com/aws/eks-hybrid/internal/util"
 )
 
 const (
@@ -31,20 +30,20 @@ var (
 	kubeconfigBootstrapPath      = path.Join(kubeconfigRoot, kubeconfigBootstrapFile)
 )
 
-func (k *kubelet) writeKubeconfig() error {
+func (k *kubelet) renderKubeconfig() (configFile, error) {
 	kubeconfig, err := generateKubeconfig(k.nodeConfig)
 	if err != nil {
-		return err
+		return configFile{}, err
 	}
 	if k.nodeConfig.IsOutpostNode() {
 		// kubelet bootstrap kubeconfig uses aws-iam-authenticator with cluster id to authenticate to cluster
 		//   - if "aws eks describe-cluster" is bypassed, for local outpost, the value of CLUSTER_NAME parameter will be cluster id.
 		//   - otherwise, the cluster id will use the id returned by "aws eks describe-cluster".
 		k.flags["bootstrap-kubeconfig"] = kubeconfigBootstrapPath
-		return util.WriteFileWithDir(kubeconfigBootstrapPath, kubeconfig, kubeconfigPerm)
+		return configFile{path: kubeconfigBootstrapPath, content: kubeconfig, perm: kubeconfigPerm}, nil
 	} else {
 		k.flags["kubeconfig"] = kubeconfigPath
-		return util.WriteFileWithDir(kubeconfigPath, kubeconfig, kubeconfigPerm)
+		return configFile{path: kubeconfigPath, content: kubeconfig, perm: kubeconfigPerm}, nil
 	}
 }
 
@@ -27,3 +27,8 @@ func (k Kubelet) KubeconfigPath() string {
 func (k Kubelet) Version() (string, error) {
 	return GetKubeletVersion()
 }
+
+// NodeName returns the node name from the kubelet's config.
+func (k Kubelet) NodeName() (string, error) {
+	return GetNodeName()
+}
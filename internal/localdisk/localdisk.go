@@ -0,0 +1,283 @@
+package localdisk
+
+import (
+	"cmp"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"github.com/aws/eks-hybrid/internal/api"
+)
+
+const (
+	// MountDir is the directory the disks are mounted under.
+	MountDir = "/mnt/k8s-disks"
+
+	// instanceStoreGlob matches the NVMe instance store devices of EC2 instances.
+	instanceStoreGlob = "/dev/disk/by-id/nvme-Amazon_EC2_NVMe_Instance_Storage_*"
+
+	systemdUnitDir = "/etc/systemd/system"
+	unitFilePerms  = 0o644
+	dirPerms       = 0o755
+)
+
+// boundDir is a directory moved to the RAID0 array with a bind mount.
+type boundDir struct {
+	path string
+	// name is the directory on the array the path is bound to
+	name string
+	// services must be stopped while the content is copied
+	services []string
+}
+
+// boundDirs returns the kubelet, containerd and pod logs directories at the
+// paths set in spec.instance.dataDirs, or their defaults.
+func boundDirs(dataDirs api.DataDirOptions) []boundDir {
+	return []boundDir{
+		{path: cmp.Or(dataDirs.KubeletRoot, "/var/lib/kubelet"), name: "kubelet", services: []string{"kubelet"}},
+		{path: cmp.Or(dataDirs.ContainerdRoot, "/var/lib/containerd"), name: "containerd", services: []string{"containerd"}},
+		{path: cmp.Or(dataDirs.PodLogs, "/var/log/pods"), name: "pods", services: []string{"kubelet"}},
+	}
+}
+
+// runner runs a command and returns its standard output.
+type runner func(name string, args ...string) (string, error)
+
+// Setup prepares the local disks with the configured strategy.
+type Setup struct {
+	opts     api.LocalStorageOptions
+	dataDirs api.DataDirOptions
+	logger   *zap.Logger
+	// root prefixes the paths read and written, for tests.
+	root string
+	run  runner
+	// units are the mount units enabled by Run
+	units []string
+}
+
+// New creates a Setup for the local storage options of the node config.
+func New(cfg *api.NodeConfig, logger *zap.Logger) *Setup {
+	return &Setup{
+		opts:     cfg.Spec.Instance.LocalStorage,
+		dataDirs: cfg.Spec.Instance.DataDirs,
+		logger:   logger,
+		root:     "/",
+		run:      runCommand,
+	}
+}
+
+// Validate checks the local storage options of the node config.
+func Validate(cfg *api.NodeConfig) error {
+	opts := cfg.Spec.Instance.LocalStorage
+	if len(opts.Devices) > 0 && opts.Strategy == "" {
+		return fmt.Errorf("spec.instance.localStorage.strategy is required when devices are set")
+	}
+	for _, device := range opts.Devices {
+		if !strings.HasPrefix(device, "/dev/") {
+			return fmt.Errorf("spec.instance.localStorage.devices must be paths under /dev, got %q", device)
+		}
+	}
+	return nil
+}
+
+// Run sets up the disks. Every step checks the current state of the node, so
+// running it again, for example on reboot, only mounts the existing arrays and
+// filesystems.
+func (s *Setup) Run() error {
+	devices, err := s.devices()
+	if err != nil {
+		return err
+	}
+	if len(devices) == 0 {
+		s.logger.Info("No local disks found, skipping disk setup")
+		return nil
+	}
+	s.logger.Info("Setting up local disks", zap.String("strategy", string(s.opts.Strategy)), zap.Strings("devices", devices))
+	switch s.opts.Strategy {
+	case api.LocalStorageRAID0:
+		return s.raid0(devices)
+	case api.LocalStorageMount:
+		return s.mount(devices)
+	default:
+		return fmt.Errorf("unknown local storage strategy %q", s.opts.Strategy)
+	}
+}
+
+// Units returns the mount units Run enabled, in the order they were enabled.
+func (s *Setup) Units() []string {
+	return s.units
+}
+
+// RemoveMounts stops and removes the mount units, in reverse order so bind
+// mounts are unmounted before the disks they are on. The arrays and
+// filesystems are left on the disks, along with the data copied to them.
+func (s *Setup) RemoveMounts(units []string) error {
+	for _, unit := range slices.Backward(units) {
+		s.logger.Info("Removing local disk mount", zap.String("unit", unit))
+		if _, err := s.run("systemctl", "disable", "--now", unit); err != nil {
+			return err
+		}
+		if err := os.RemoveAll(s.path(filepath.Join(systemdUnitDir, unit))); err != nil {
+			return err
+		}
+	}
+	if len(units) == 0 {
+		return nil
+	}
+	_, err := s.run("systemctl", "daemon-reload")
+	return err
+}
+
+// devices returns the explicit device list or the instance store devices,
+// resolved to their /dev/<name> path.
+func (s *Setup) devices() ([]string, error) {
+	paths := s.opts.Devices
+	if len(paths) == 0 {
+		matches, err := filepath.Glob(s.path(instanceStoreGlob))
+		if err != nil {
+			return nil, err
+		}
+		for _, match := range matches {
+			paths = append(paths, s.unrooted(match))
+		}
+	}
+	var devices []string
+	for _, path := range paths {
+		device, err := filepath.EvalSymlinks(s.path(path))
+		if err != nil {
+			return nil, fmt.Errorf("resolving device %s: %w", path, err)
+		}
+		devices = append(devices, s.unrooted(device))
+	}
+	slices.Sort(devices)
+	return slices.Compact(devices), nil
+}
+
+// fsType returns the filesystem on the device, empty if it's not formatted.
+func (s *Setup) fsType(device string) (string, error) {
+	out, err := s.run("lsblk", device, "--output", "FSTYPE", "--noheadings", "--nodeps")
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(out), nil
+}
+
+// ensureFilesystem creates an xfs filesystem on the device if it doesn't have
+// one and returns the filesystem type.
+func (s *Setup) ensureFilesystem(device string) (string, error) {
+	fsType, err := s.fsType(device)
+	if err != nil {
+		return "", err
+	}
+	if fsType != "" {
+		s.logger.Info("Device already has a filesystem", zap.String("device", device), zap.String("type", fsType))
+		return fsType, nil
+	}
+	// mkfs uses the stripe unit of RAID arrays (512k) for the log stripe unit,
+	// which is larger than the 256k maximum. Use 32k (8 blocks) instead.
+	if _, err := s.run("mkfs.xfs", "-l", "su=8b", device); err != nil {
+		return "", err
+	}
+	return "xfs", nil
+}
+
+// uuid returns the filesystem UUID of the device. Mount units reference the
+// UUID since device names can change between reboots.
+func (s *Setup) uuid(device string) (string, error) {
+	out, err := s.run("blkid", "--match-tag", "UUID", "--output", "value", device)
+	if err != nil {
+		return "", err
+	}
+	uuid := strings.TrimSpace(out)
+	if uuid == "" {
+		return "", fmt.Errorf("device %s has no filesystem UUID", device)
+	}
+	return uuid, nil
+}
+
+// enableMount writes a systemd mount unit for the mount point and starts it.
+// Mount units are used instead of fstab entries so they are mounted again on
+// reboot before nodeadm runs.
+func (s *Setup) enableMount(description, what, where, vfsType, options string) error {
+	if err := os.MkdirAll(s.path(where), dirPerms); err != nil {
+		return err
+	}
+	unitName := mountUnitName(where)
+	unit := fmt.Sprintf(`[Unit]
+Description=%s
+
+[Mount]
+What=%s
+Where=%s
+Type=%s
+Options=%s
+
+[Install]
+WantedBy=multi-user.target
+`, description, what, where, vfsType, options)
+	if err := os.WriteFile(s.path(filepath.Join(systemdUnitDir, unitName)), []byte(unit), unitFilePerms); err != nil {
+		return err
+	}
+	if _, err := s.run("systemctl", "daemon-reload"); err != nil {
+		return err
+	}
+	if _, err := s.run("systemctl", "enable", "--now", unitName); err != nil {
+		return err
+	}
+	s.units = append(s.units, unitName)
+	return nil
+}
+
+func (s *Setup) isActive(unit string) bool {
+	out, _ := s.run("systemctl", "is-active", unit)
+	return strings.TrimSpace(out) == "active"
+}
+
+func (s *Setup) path(path string) string {
+	return filepath.Join(s.root, path)
+}
+
+func (s *Setup) unrooted(path string) string {
+	return filepath.Join("/", strings.TrimPrefix(path, s.root))
+}
+
+func runCommand(name string, args ...string) (string, error) {
+	// #nosec G204 Subprocess launched with variable
+	cmd := exec.Command(name, args...)
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return string(out), fmt.Errorf("running %s: %s: %w", cmd.Args, strings.TrimSpace(stderr.String()), err)
+	}
+	return string(out), nil
+}
+
+// mountUnitName returns the name systemd expects for the mount unit of path,
+// like `systemd-escape --path --suffix=mount`.
+func mountUnitName(path string) string {
+	path = strings.Trim(filepath.Clean(path), "/")
+	if path == "" {
+		return "-.mount"
+	}
+	var b strings.Builder
+	for i := 0; i < len(path); i++ {
+		c := path[i]
+		switch {
+		case c == '/':
+			b.WriteByte('-')
+		case c == '.' && i == 0:
+			fmt.Fprintf(&b, `\x%02x`, c)
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9', c == ':', c == '_', c == '.':
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, `\x%02x`, c)
+		}
+	}
+	return b.String() + ".mount"
+}
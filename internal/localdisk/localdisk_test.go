@@ -0,0 +1,304 @@
+package localdisk
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"go.uber.org/zap"
+
+	"github.com/aws/eks-hybrid/internal/api"
+)
+
+// fakeRunner records the commands and answers them from outputs, keyed by the
+// command line.
+type fakeRunner struct {
+	commands []string
+	outputs  map[string]string
+}
+
+func (f *fakeRunner) run(name string, args ...string) (string, error) {
+	command := strings.Join(append([]string{name}, args...), " ")
+	f.commands = append(f.commands, command)
+	return f.outputs[command], nil
+}
+
+func newTestSetup(t *testing.T, opts api.LocalStorageOptions, outputs map[string]string) (*Setup, *fakeRunner) {
+	root := t.TempDir()
+	for _, dir := range []string{"/dev/disk/by-id", systemdUnitDir} {
+		if err := os.MkdirAll(filepath.Join(root, dir), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	runner := &fakeRunner{outputs: outputs}
+	return &Setup{opts: opts, logger: zap.NewNop(), root: root, run: runner.run}, runner
+}
+
+func addDevice(t *testing.T, root, name string, links ...string) {
+	if err := os.WriteFile(filepath.Join(root, "dev", name), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	for _, link := range links {
+		if err := os.Symlink("../../"+name, filepath.Join(root, "dev/disk/by-id", link)); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestDevices(t *testing.T) {
+	g := NewWithT(t)
+	s, _ := newTestSetup(t, api.LocalStorageOptions{}, nil)
+	addDevice(t, s.root, "nvme2n1", "nvme-Amazon_EC2_NVMe_Instance_Storage_AWS2", "nvme-Amazon_EC2_NVMe_Instance_Storage_AWS2_1")
+	addDevice(t, s.root, "nvme1n1", "nvme-Amazon_EC2_NVMe_Instance_Storage_AWS1")
+	addDevice(t, s.root, "nvme0n1", "nvme-Amazon_Elastic_Block_Store_vol1")
+
+	devices, err := s.devices()
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(devices).To(Equal([]string{"/dev/nvme1n1", "/dev/nvme2n1"}))
+
+	s.opts.Devices = []string{"/dev/disk/by-id/nvme-Amazon_Elastic_Block_Store_vol1"}
+	devices, err = s.devices()
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(devices).To(Equal([]string{"/dev/nvme0n1"}))
+}
+
+func TestRunNoDevices(t *testing.T) {
+	g := NewWithT(t)
+	s, runner := newTestSetup(t, api.LocalStorageOptions{Strategy: api.LocalStorageRAID0}, nil)
+	g.Expect(s.Run()).To(Succeed())
+	g.Expect(runner.commands).To(BeEmpty())
+}
+
+func TestRunRAID0(t *testing.T) {
+	g := NewWithT(t)
+	s, runner := newTestSetup(t, api.LocalStorageOptions{Strategy: api.LocalStorageRAID0}, map[string]string{
+		"blkid --match-tag UUID --output value /dev/md/kubernetes": "1234-abcd\n",
+		"mdadm --detail --scan":          "ARRAY /dev/md/kubernetes metadata=1.2 name=kubernetes\n",
+		"systemctl is-active kubelet":    "active\n",
+		"systemctl is-active containerd": "inactive\n",
+	})
+	addDevice(t, s.root, "nvme1n1", "nvme-Amazon_EC2_NVMe_Instance_Storage_AWS1")
+	addDevice(t, s.root, "nvme2n1", "nvme-Amazon_EC2_NVMe_Instance_Storage_AWS2")
+
+	g.Expect(s.Run()).To(Succeed())
+
+	g.Expect(runner.commands).To(ContainElements(
+		"mdadm --create --force --verbose /dev/md/kubernetes --level=0 --name=kubernetes --raid-devices=2 /dev/nvme1n1 /dev/nvme2n1",
+		"mkfs.xfs -l su=8b /dev/md/kubernetes",
+		"systemctl enable --now mnt-k8s\\x2ddisks-0.mount",
+		"systemctl stop kubelet",
+		"cp -a /var/lib/kubelet/. /mnt/k8s-disks/0/kubelet/",
+		"cp -a /var/lib/containerd/. /mnt/k8s-disks/0/containerd/",
+		"cp -a /var/log/pods/. /mnt/k8s-disks/0/pods/",
+		"systemctl enable --now var-lib-kubelet.mount",
+		"systemctl start kubelet",
+	))
+	g.Expect(runner.commands).NotTo(ContainElement("systemctl stop containerd"))
+	g.Expect(s.Units()).To(Equal([]string{
+		"mnt-k8s\\x2ddisks-0.mount",
+		"var-lib-kubelet.mount",
+		"var-lib-containerd.mount",
+		"var-log-pods.mount",
+	}))
+
+	mdadmConf, err := os.ReadFile(filepath.Join(s.root, mdadmConfig))
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(string(mdadmConf)).To(ContainSubstring("ARRAY /dev/md/kubernetes"))
+
+	arrayUnit, err := os.ReadFile(filepath.Join(s.root, systemdUnitDir, "mnt-k8s\\x2ddisks-0.mount"))
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(string(arrayUnit)).To(ContainSubstring("What=UUID=1234-abcd\nWhere=/mnt/k8s-disks/0\nType=xfs\n"))
+
+	bindUnit, err := os.ReadFile(filepath.Join(s.root, systemdUnitDir, "var-log-pods.mount"))
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(string(bindUnit)).To(ContainSubstring("What=/mnt/k8s-disks/0/pods\nWhere=/var/log/pods\nType=none\nOptions=bind\n"))
+}
+
+func TestRunRAID0DataDirs(t *testing.T) {
+	g := NewWithT(t)
+	s, runner := newTestSetup(t, api.LocalStorageOptions{Strategy: api.LocalStorageRAID0}, map[string]string{
+		"blkid --match-tag UUID --output value /dev/md/kubernetes": "1234-abcd\n",
+	})
+	s.dataDirs = api.DataDirOptions{KubeletRoot: "/data/kubelet", ContainerdRoot: "/data/containerd"}
+	addDevice(t, s.root, "nvme1n1", "nvme-Amazon_EC2_NVMe_Instance_Storage_AWS1")
+
+	g.Expect(s.Run()).To(Succeed())
+
+	g.Expect(runner.commands).To(ContainElements(
+		"cp -a /data/kubelet/. /mnt/k8s-disks/0/kubelet/",
+		"cp -a /data/containerd/. /mnt/k8s-disks/0/containerd/",
+		"cp -a /var/log/pods/. /mnt/k8s-disks/0/pods/",
+		"systemctl enable --now data-kubelet.mount",
+		"systemctl enable --now data-containerd.mount",
+	))
+	g.Expect(runner.commands).NotTo(ContainElement("cp -a /var/lib/kubelet/. /mnt/k8s-disks/0/kubelet/"))
+}
+
+func TestRunRAID0DeviceSignature(t *testing.T) {
+	g := NewWithT(t)
+	outputs := map[string]string{
+		"lsblk /dev/nvme2n1 --output FSTYPE,PTTYPE --noheadings --nodeps": "       gpt\n",
+		"blkid --match-tag UUID --output value /dev/md/kubernetes":        "1234-abcd\n",
+	}
+	s, runner := newTestSetup(t, api.LocalStorageOptions{Strategy: api.LocalStorageRAID0}, outputs)
+	addDevice(t, s.root, "nvme1n1", "nvme-Amazon_EC2_NVMe_Instance_Storage_AWS1")
+	addDevice(t, s.root, "nvme2n1", "nvme-Amazon_EC2_NVMe_Instance_Storage_AWS2")
+
+	g.Expect(s.Run()).To(MatchError("device /dev/nvme2n1 already has a gpt signature, set spec.instance.localStorage.overwriteDevices to create the RAID0 array on it and destroy its data"))
+	for _, command := range runner.commands {
+		g.Expect(command).NotTo(HavePrefix("mdadm --create"))
+	}
+
+	s.opts.OverwriteDevices = true
+	g.Expect(s.Run()).To(Succeed())
+	g.Expect(runner.commands).To(ContainElement("mdadm --create --force --verbose /dev/md/kubernetes --level=0 --name=kubernetes --raid-devices=2 /dev/nvme1n1 /dev/nvme2n1"))
+}
+
+func TestRunRAID0ResyncTimeout(t *testing.T) {
+	g := NewWithT(t)
+	originalInterval, originalTimeout := resyncPollInterval, resyncTimeout
+	resyncPollInterval, resyncTimeout = time.Millisecond, 10*time.Millisecond
+	t.Cleanup(func() { resyncPollInterval, resyncTimeout = originalInterval, originalTimeout })
+
+	s, _ := newTestSetup(t, api.LocalStorageOptions{Strategy: api.LocalStorageRAID0}, map[string]string{
+		"mdadm --detail /dev/md/kubernetes": "State : clean, resyncing\n",
+	})
+	addDevice(t, s.root, "nvme1n1", "nvme-Amazon_EC2_NVMe_Instance_Storage_AWS1")
+
+	g.Expect(s.Run()).To(MatchError("timed out after 10ms waiting for RAID0 array /dev/md/kubernetes to resync"))
+}
+
+func TestRunRAID0Existing(t *testing.T) {
+	g := NewWithT(t)
+	s, runner := newTestSetup(t, api.LocalStorageOptions{Strategy: api.LocalStorageRAID0}, map[string]string{
+		"lsblk /dev/md/kubernetes_0 --output FSTYPE --noheadings --nodeps": "xfs\n",
+		"blkid --match-tag UUID --output value /dev/md/kubernetes_0":       "1234-abcd\n",
+		"systemctl is-active var-lib-kubelet.mount":                        "active\n",
+		"systemctl is-active var-lib-containerd.mount":                     "active\n",
+		"systemctl is-active var-log-pods.mount":                           "active\n",
+	})
+	addDevice(t, s.root, "nvme1n1", "nvme-Amazon_EC2_NVMe_Instance_Storage_AWS1")
+	g.Expect(os.MkdirAll(filepath.Join(s.root, filepath.Dir(mdadmConfig)), 0o755)).To(Succeed())
+	g.Expect(os.WriteFile(filepath.Join(s.root, mdadmConfig), []byte("ARRAY /dev/md/kubernetes\n"), 0o644)).To(Succeed())
+	g.Expect(os.MkdirAll(filepath.Join(s.root, "/dev/md"), 0o755)).To(Succeed())
+	g.Expect(os.Symlink("../md127", filepath.Join(s.root, "/dev/md/kubernetes_0"))).To(Succeed())
+
+	g.Expect(s.Run()).To(Succeed())
+
+	for _, command := range runner.commands {
+		g.Expect(command).NotTo(HavePrefix("mdadm --create"))
+		g.Expect(command).NotTo(HavePrefix("mkfs.xfs"))
+		g.Expect(command).NotTo(HavePrefix("cp"))
+	}
+	g.Expect(runner.commands).To(ContainElement("systemctl enable --now mnt-k8s\\x2ddisks-0.mount"))
+}
+
+func TestRunMount(t *testing.T) {
+	g := NewWithT(t)
+	s, runner := newTestSetup(t, api.LocalStorageOptions{
+		Strategy: api.LocalStorageMount,
+		Devices:  []string{"/dev/sdb", "/dev/sdc", "/dev/sdd"},
+	}, map[string]string{
+		"lsblk /dev/sdc --output FSTYPE --noheadings --nodeps":     "ext4\n",
+		"lsblk /dev/sdd --output MOUNTPOINT --noheadings --nodeps": "/data\n",
+		"blkid --match-tag UUID --output value /dev/sdb":           "uuid-b\n",
+		"blkid --match-tag UUID --output value /dev/sdc":           "uuid-c\n",
+	})
+	for _, device := range []string{"sdb", "sdc", "sdd"} {
+		addDevice(t, s.root, device)
+	}
+
+	g.Expect(s.Run()).To(Succeed())
+
+	g.Expect(runner.commands).To(ContainElements(
+		"mkfs.xfs -l su=8b /dev/sdb",
+		"mkfs.xfs -l su=8b /dev/sdd",
+		"systemctl enable --now mnt-k8s\\x2ddisks-1.mount",
+		"systemctl enable --now mnt-k8s\\x2ddisks-2.mount",
+	))
+	g.Expect(runner.commands).NotTo(ContainElement("mkfs.xfs -l su=8b /dev/sdc"))
+	g.Expect(runner.commands).NotTo(ContainElement("systemctl enable --now mnt-k8s\\x2ddisks-3.mount"))
+
+	unit, err := os.ReadFile(filepath.Join(s.root, systemdUnitDir, "mnt-k8s\\x2ddisks-2.mount"))
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(string(unit)).To(ContainSubstring("What=UUID=uuid-c\nWhere=/mnt/k8s-disks/2\nType=ext4\n"))
+}
+
+func TestValidate(t *testing.T) {
+	testCases := []struct {
+		name    string
+		opts    api.LocalStorageOptions
+		wantErr string
+	}{
+		{
+			name: "empty",
+		},
+		{
+			name: "devices",
+			opts: api.LocalStorageOptions{Strategy: api.LocalStorageMount, Devices: []string{"/dev/sdb"}},
+		},
+		{
+			name:    "devices without strategy",
+			opts:    api.LocalStorageOptions{Devices: []string{"/dev/sdb"}},
+			wantErr: "spec.instance.localStorage.strategy is required when devices are set",
+		},
+		{
+			name:    "device outside /dev",
+			opts:    api.LocalStorageOptions{Strategy: api.LocalStorageRAID0, Devices: []string{"sdb"}},
+			wantErr: `spec.instance.localStorage.devices must be paths under /dev, got "sdb"`,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			err := Validate(&api.NodeConfig{
+				Spec: api.NodeConfigSpec{
+					Instance: api.InstanceOptions{LocalStorage: tc.opts},
+				},
+			})
+			if tc.wantErr == "" {
+				g.Expect(err).NotTo(HaveOccurred())
+			} else {
+				g.Expect(err).To(MatchError(tc.wantErr))
+			}
+		})
+	}
+}
+
+func TestMountUnitName(t *testing.T) {
+	testCases := map[string]string{
+		"/":                 "-.mount",
+		"/var/lib/kubelet":  "var-lib-kubelet.mount",
+		"/var/lib/kubelet/": "var-lib-kubelet.mount",
+		"/mnt/k8s-disks/0":  "mnt-k8s\\x2ddisks-0.mount",
+		"/mnt/.hidden/a b":  "mnt-.hidden-a\\x20b.mount",
+		"/.data":            "\\x2edata.mount",
+	}
+	for path, want := range testCases {
+		t.Run(path, func(t *testing.T) {
+			NewWithT(t).Expect(mountUnitName(path)).To(Equal(want))
+		})
+	}
+}
+
+func TestRemoveMounts(t *testing.T) {
+	g := NewWithT(t)
+	s, runner := newTestSetup(t, api.LocalStorageOptions{}, nil)
+	units := []string{"mnt-k8s\\x2ddisks-0.mount", "var-lib-kubelet.mount"}
+	for _, unit := range units {
+		g.Expect(os.WriteFile(filepath.Join(s.root, systemdUnitDir, unit), nil, 0o644)).To(Succeed())
+	}
+
+	g.Expect(s.RemoveMounts(units)).To(Succeed())
+	g.Expect(runner.commands).To(Equal([]string{
+		"systemctl disable --now var-lib-kubelet.mount",
+		"systemctl disable --now mnt-k8s\\x2ddisks-0.mount",
+		"systemctl daemon-reload",
+	}))
+	for _, unit := range units {
+		g.Expect(filepath.Join(s.root, systemdUnitDir, unit)).NotTo(BeAnExistingFile())
+	}
+}
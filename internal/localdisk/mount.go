@@ -0,0 +1,39 @@
+package localdisk
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+// mount creates a filesystem on each device and mounts them individually in
+// MountDir/1, MountDir/2 and so on.
+func (s *Setup) mount(devices []string) error {
+	for i, device := range devices {
+		mountPoint := filepath.Join(MountDir, strconv.Itoa(i+1))
+		fsType, err := s.ensureFilesystem(device)
+		if err != nil {
+			return err
+		}
+		out, err := s.run("lsblk", device, "--output", "MOUNTPOINT", "--noheadings", "--nodeps")
+		if err != nil {
+			return err
+		}
+		if current := strings.TrimSpace(out); current != "" && current != mountPoint {
+			s.logger.Info("Device is already mounted", zap.String("device", device), zap.String("mountPoint", current))
+			continue
+		}
+		uuid, err := s.uuid(device)
+		if err != nil {
+			return err
+		}
+		description := fmt.Sprintf("Mount local disk %d", i+1)
+		if err := s.enableMount(description, "UUID="+uuid, mountPoint, fsType, "defaults,noatime"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
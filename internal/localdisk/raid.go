@@ -0,0 +1,193 @@
+package localdisk
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const (
+	raidName   = "kubernetes"
+	raidDevice = "/dev/md/" + raidName
+	// mdadmConfig records the array once it's created. It's the file the
+	// setup-local-disks script of the EKS AMIs used, so arrays created by it
+	// are reused.
+	mdadmConfig = "/.aws/mdadm.conf"
+)
+
+// raidDeviceRegex matches the array symlink, which can get a homehost suffix
+// after a reboot.
+var raidDeviceRegex = regexp.MustCompile(`^` + raidName + `_?[0-9a-z]*$`)
+
+var (
+	// resyncPollInterval is the time between checks of the array resync status.
+	resyncPollInterval = time.Second
+	// resyncTimeout is the maximum time to wait for the array to resync.
+	resyncTimeout = 10 * time.Minute
+)
+
+// raid0 creates a RAID0 array of the devices, mounts it and moves the
+// kubelet, containerd and pod logs directories to it.
+func (s *Setup) raid0(devices []string) error {
+	array, err := s.ensureArray(devices)
+	if err != nil {
+		return err
+	}
+	fsType, err := s.ensureFilesystem(array)
+	if err != nil {
+		return err
+	}
+	uuid, err := s.uuid(array)
+	if err != nil {
+		return err
+	}
+	mountPoint := filepath.Join(MountDir, "0")
+	if err := s.enableMount("Mount local disks RAID0", "UUID="+uuid, mountPoint, fsType, "defaults,noatime"); err != nil {
+		return err
+	}
+	return s.bindDirs(mountPoint)
+}
+
+// ensureArray creates the array unless it was already created and returns
+// its device.
+func (s *Setup) ensureArray(devices []string) (string, error) {
+	info, err := os.Stat(s.path(mdadmConfig))
+	if err != nil && !os.IsNotExist(err) {
+		return "", err
+	}
+	if err != nil || info.Size() == 0 {
+		if err := s.createArray(devices); err != nil {
+			return "", err
+		}
+	}
+
+	entries, err := os.ReadDir(s.path(filepath.Dir(raidDevice)))
+	if err != nil && !os.IsNotExist(err) {
+		return "", err
+	}
+	var matches []string
+	for _, entry := range entries {
+		if raidDeviceRegex.MatchString(entry.Name()) {
+			matches = append(matches, entry.Name())
+		}
+	}
+	if len(matches) == 0 {
+		return raidDevice, nil
+	}
+	slices.Sort(matches)
+	return filepath.Join(filepath.Dir(raidDevice), matches[len(matches)-1]), nil
+}
+
+func (s *Setup) createArray(devices []string) error {
+	if !s.opts.OverwriteDevices {
+		for _, device := range devices {
+			signatures, err := s.signatures(device)
+			if err != nil {
+				return err
+			}
+			if len(signatures) > 0 {
+				return fmt.Errorf("device %s already has a %s signature, set spec.instance.localStorage.overwriteDevices to create the RAID0 array on it and destroy its data",
+					device, strings.Join(signatures, " and "))
+			}
+		}
+	}
+	s.logger.Info("Creating RAID0 array", zap.String("device", raidDevice), zap.Strings("devices", devices))
+	args := []string{
+		"--create", "--force", "--verbose", raidDevice,
+		"--level=0",
+		"--name=" + raidName,
+		fmt.Sprintf("--raid-devices=%d", len(devices)),
+	}
+	if _, err := s.run("mdadm", append(args, devices...)...); err != nil {
+		return err
+	}
+	deadline := time.Now().Add(resyncTimeout)
+	for {
+		detail, err := s.run("mdadm", "--detail", raidDevice)
+		if err != nil {
+			return err
+		}
+		if !strings.Contains(strings.ToLower(detail), "resyncing") {
+			break
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("timed out after %s waiting for RAID0 array %s to resync", resyncTimeout, raidDevice)
+		}
+		s.logger.Info("RAID0 array is resyncing...")
+		time.Sleep(resyncPollInterval)
+	}
+	scan, err := s.run("mdadm", "--detail", "--scan")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(s.path(filepath.Dir(mdadmConfig)), dirPerms); err != nil {
+		return err
+	}
+	return os.WriteFile(s.path(mdadmConfig), []byte(scan), unitFilePerms)
+}
+
+// signatures returns the filesystem, RAID member or partition table types
+// found on the device.
+func (s *Setup) signatures(device string) ([]string, error) {
+	out, err := s.run("lsblk", device, "--output", "FSTYPE,PTTYPE", "--noheadings", "--nodeps")
+	if err != nil {
+		return nil, err
+	}
+	return strings.Fields(out), nil
+}
+
+// bindDirs copies the content of the bound directories to the array and bind
+// mounts them. The services using them are stopped during the copy.
+func (s *Setup) bindDirs(mountPoint string) error {
+	var stopped []string
+	var pending []boundDir
+	for _, dir := range boundDirs(s.dataDirs) {
+		if s.isActive(mountUnitName(dir.path)) {
+			continue
+		}
+		pending = append(pending, dir)
+		for _, service := range dir.services {
+			if !slices.Contains(stopped, service) && s.isActive(service) {
+				stopped = append(stopped, service)
+			}
+		}
+	}
+	if len(pending) == 0 {
+		return nil
+	}
+
+	if len(stopped) > 0 {
+		s.logger.Info("Stopping services to move their data to the local disks", zap.Strings("services", stopped))
+		if _, err := s.run("systemctl", append([]string{"stop"}, stopped...)...); err != nil {
+			return err
+		}
+	}
+	for _, dir := range pending {
+		target := filepath.Join(mountPoint, dir.name)
+		for _, path := range []string{dir.path, target} {
+			if err := os.MkdirAll(s.path(path), dirPerms); err != nil {
+				return err
+			}
+		}
+		s.logger.Info("Copying directory to local disks", zap.String("source", dir.path), zap.String("target", target))
+		if _, err := s.run("cp", "-a", dir.path+"/.", target+"/"); err != nil {
+			return err
+		}
+		description := fmt.Sprintf("Mount %s on local disks RAID0", dir.name)
+		if err := s.enableMount(description, target, dir.path, "none", "bind"); err != nil {
+			return err
+		}
+	}
+	if len(stopped) > 0 {
+		if _, err := s.run("systemctl", append([]string{"start"}, stopped...)...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
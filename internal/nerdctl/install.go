@@ -0,0 +1,92 @@
+package nerdctl
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	"github.com/aws/eks-hybrid/internal/artifact"
+	"github.com/aws/eks-hybrid/internal/tracker"
+)
+
+const (
+	// BinPath is the path to the nerdctl binary.
+	BinPath = "/usr/local/bin/nerdctl"
+
+	artifactName      = "nerdctl"
+	artifactFilePerms = 0o755
+)
+
+// Source represents a source that serves a nerdctl binary.
+type Source interface {
+	GetNerdctl(context.Context) (artifact.Source, error)
+}
+
+// InstallOptions contains options for installing nerdctl
+type InstallOptions struct {
+	InstallRoot string
+	Tracker     *tracker.Tracker
+	Source      Source
+	Logger      *zap.Logger
+}
+
+// Install installs nerdctl at BinPath.
+func Install(ctx context.Context, opts InstallOptions) error {
+	if err := downloadFileWithRetries(ctx, opts); err != nil {
+		return errors.Wrap(err, "installing nerdctl")
+	}
+
+	if err := opts.Tracker.Add(artifact.Nerdctl); err != nil {
+		return errors.Wrap(err, "adding nerdctl to tracker")
+	}
+
+	return nil
+}
+
+func downloadFileWithRetries(ctx context.Context, opts InstallOptions) error {
+	// Retry up to 3 times to download and validate the checksum
+	var err error
+	for range 3 {
+		err = downloadFileTo(ctx, opts)
+		if err == nil {
+			break
+		}
+		opts.Logger.Error("Downloading nerdctl failed. Retrying...", zap.Error(err))
+	}
+	return err
+}
+
+func downloadFileTo(ctx context.Context, opts InstallOptions) error {
+	nerdctl, err := opts.Source.GetNerdctl(ctx)
+	if err != nil {
+		return errors.Wrap(err, "getting nerdctl source")
+	}
+	defer nerdctl.Close()
+
+	if err := artifact.InstallFile(filepath.Join(opts.InstallRoot, BinPath), nerdctl, artifactFilePerms); err != nil {
+		return errors.Wrap(err, "installing nerdctl")
+	}
+
+	if !nerdctl.VerifyChecksum() {
+		return errors.Errorf("nerdctl checksum mismatch: %v", artifact.NewChecksumError(nerdctl))
+	}
+
+	return nil
+}
+
+func Uninstall() error {
+	return os.RemoveAll(BinPath)
+}
+
+func Upgrade(ctx context.Context, src Source, log *zap.Logger) error {
+	nerdctl, err := src.GetNerdctl(ctx)
+	if err != nil {
+		return errors.Wrap(err, "getting nerdctl source")
+	}
+	defer nerdctl.Close()
+
+	return artifact.Upgrade(artifactName, BinPath, nerdctl, artifactFilePerms, log)
+}
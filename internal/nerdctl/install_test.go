@@ -0,0 +1,36 @@
+package nerdctl_test
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"go.uber.org/zap"
+
+	"github.com/aws/eks-hybrid/internal/aws"
+	"github.com/aws/eks-hybrid/internal/nerdctl"
+	"github.com/aws/eks-hybrid/internal/test"
+	"github.com/aws/eks-hybrid/internal/tracker"
+)
+
+func TestInstall(t *testing.T) {
+	nerdctlData := []byte("test nerdctl binary")
+
+	test.RunInstallTest(t, test.TestData{
+		ArtifactName: "nerdctl",
+		BinaryName:   "nerdctl",
+		Data:         nerdctlData,
+		Install: func(ctx context.Context, tempDir string, source aws.Source, tr *tracker.Tracker) error {
+			return nerdctl.Install(ctx, nerdctl.InstallOptions{
+				InstallRoot: tempDir,
+				Tracker:     tr,
+				Source:      source,
+				Logger:      zap.NewNop(),
+			})
+		},
+		Verify: func(g *GomegaWithT, tempDir string, tr *tracker.Tracker) {
+			g.Expect(tr.Artifacts.Nerdctl).To(BeTrue())
+		},
+		VerifyFilePaths: []string{nerdctl.BinPath},
+	})
+}
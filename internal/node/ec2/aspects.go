@@ -3,8 +3,17 @@ package ec2
 import "github.com/aws/eks-hybrid/internal/system"
 
 func (enp *ec2NodeProvider) GetAspects() []system.SystemAspect {
-	return []system.SystemAspect{
-		system.NewLocalDiskAspect(enp.nodeConfig),
-		system.NewNetworkingAspect(enp.nodeConfig),
+	aspects := []system.SystemAspect{
+		system.NewTrustAspect(enp.nodeConfig, enp.logger),
+	}
+	// the EKS AMIs already set the sysctl defaults of nodeadm, the aspect only
+	// runs for the settings of the config
+	if instance := enp.nodeConfig.Spec.Instance; len(instance.Sysctls) > 0 || len(instance.KernelModules) > 0 {
+		aspects = append(aspects, system.NewSysctlAspect(enp.nodeConfig))
 	}
+	return append(aspects,
+		system.NewLocalDiskAspect(enp.nodeConfig, enp.logger),
+		system.NewDataDirsAspect(enp.nodeConfig, enp.logger),
+		system.NewNetworkingAspect(enp.nodeConfig),
+	)
 }
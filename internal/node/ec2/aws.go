@@ -5,11 +5,13 @@ import (
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/config"
+
+	"github.com/aws/eks-hybrid/internal/trust"
 )
 
 func (enp *ec2NodeProvider) ConfigureAws(ctx context.Context) error {
 	region := enp.nodeConfig.Status.Instance.Region
-	awsConfig, err := config.LoadDefaultConfig(ctx, config.WithRegion(region))
+	awsConfig, err := config.LoadDefaultConfig(ctx, trust.WithRootCAs, config.WithRegion(region))
 	if err != nil {
 		return err
 	}
@@ -17,6 +19,11 @@ func (enp *ec2NodeProvider) ConfigureAws(ctx context.Context) error {
 	return nil
 }
 
+// LoadAws is the same as ConfigureAws, which doesn't change EC2 nodes.
+func (enp *ec2NodeProvider) LoadAws(ctx context.Context) error {
+	return enp.ConfigureAws(ctx)
+}
+
 func (enp *ec2NodeProvider) GetConfig() *aws.Config {
 	return enp.awsConfig
 }
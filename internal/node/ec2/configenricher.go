@@ -11,12 +11,13 @@ import (
 
 	"github.com/aws/eks-hybrid/internal/api"
 	"github.com/aws/eks-hybrid/internal/aws/ecr"
+	"github.com/aws/eks-hybrid/internal/trust"
 )
 
 func (enp *ec2NodeProvider) Enrich(ctx context.Context) error {
 	enp.logger.Info("Fetching instance details..")
 	imdsClient := imds.New(imds.Options{})
-	awsConfig, err := config.LoadDefaultConfig(ctx, config.WithClientLogMode(aws.LogRetries), config.WithEC2IMDSRegion(func(o *config.UseEC2IMDSRegion) {
+	awsConfig, err := config.LoadDefaultConfig(ctx, trust.WithRootCAs, config.WithClientLogMode(aws.LogRetries), config.WithEC2IMDSRegion(func(o *config.UseEC2IMDSRegion) {
 		o.Client = imdsClient
 	}))
 	if err != nil {
@@ -6,6 +6,8 @@ import (
 	"github.com/pkg/errors"
 
 	"github.com/aws/eks-hybrid/internal/containerd"
+	"github.com/aws/eks-hybrid/internal/cri"
+	"github.com/aws/eks-hybrid/internal/crio"
 	"github.com/aws/eks-hybrid/internal/daemon"
 	"github.com/aws/eks-hybrid/internal/kubelet"
 )
@@ -23,9 +25,14 @@ func (enp *ec2NodeProvider) GetDaemons() ([]daemon.Daemon, error) {
 	if enp.awsConfig == nil {
 		return nil, errors.New("aws config not set")
 	}
+	runtime := cri.InstalledRuntime()
+	runtimeDaemon := containerd.NewContainerdDaemon(enp.daemonManager, enp.nodeConfig, enp.awsConfig, enp.logger)
+	if runtime == cri.Crio {
+		runtimeDaemon = crio.NewCrioDaemon(enp.daemonManager, enp.nodeConfig, enp.awsConfig, enp.logger)
+	}
 	return []daemon.Daemon{
-		containerd.NewContainerdDaemon(enp.daemonManager, enp.nodeConfig, enp.awsConfig, enp.logger),
-		kubelet.NewKubeletDaemon(enp.daemonManager, enp.nodeConfig, enp.awsConfig),
+		runtimeDaemon,
+		kubelet.NewKubeletDaemon(enp.daemonManager, enp.nodeConfig, enp.awsConfig, cri.Endpoint(runtime)),
 	}, nil
 }
 
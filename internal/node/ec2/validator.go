@@ -4,6 +4,9 @@ import (
 	"fmt"
 
 	"github.com/aws/eks-hybrid/internal/api"
+	"github.com/aws/eks-hybrid/internal/containerd"
+	"github.com/aws/eks-hybrid/internal/localdisk"
+	"github.com/aws/eks-hybrid/internal/system"
 )
 
 func (enp *ec2NodeProvider) withEc2NodeValidators() {
@@ -25,6 +28,21 @@ func (enp *ec2NodeProvider) withEc2NodeValidators() {
 				return fmt.Errorf("CIDR is missing in cluster configuration")
 			}
 		}
+		if err := containerd.Validate(cfg); err != nil {
+			return err
+		}
+		if err := localdisk.Validate(cfg); err != nil {
+			return err
+		}
+		if err := system.ValidateDataDirs(cfg); err != nil {
+			return err
+		}
+		if err := system.ValidateKernelSettings(cfg); err != nil {
+			return err
+		}
+		if err := system.ValidateCNI(cfg); err != nil {
+			return err
+		}
 		return nil
 	}
 }
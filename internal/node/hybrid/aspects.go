@@ -4,8 +4,13 @@ import "github.com/aws/eks-hybrid/internal/system"
 
 func (hnp *HybridNodeProvider) GetAspects() []system.SystemAspect {
 	return []system.SystemAspect{
+		system.NewTrustAspect(hnp.nodeConfig, hnp.logger),
 		system.NewSysctlAspect(hnp.nodeConfig),
 		system.NewSwapAspect(hnp.nodeConfig, hnp.logger),
+		system.NewIptablesAspect(hnp.nodeConfig, hnp.logger),
 		system.NewPortsAspect(hnp.nodeConfig, hnp.logger),
+		system.NewLocalDiskAspect(hnp.nodeConfig, hnp.logger),
+		system.NewDataDirsAspect(hnp.nodeConfig, hnp.logger),
+		system.NewCNIPrerequisitesAspect(hnp.nodeConfig, hnp.logger),
 	}
 }
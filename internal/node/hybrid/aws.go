@@ -13,6 +13,7 @@ import (
 	"github.com/aws/eks-hybrid/internal/daemon"
 	"github.com/aws/eks-hybrid/internal/iamrolesanywhere"
 	"github.com/aws/eks-hybrid/internal/ssm"
+	"github.com/aws/eks-hybrid/internal/trust"
 )
 
 const iamRoleAnywhereProfileName = "hybrid"
@@ -53,6 +54,26 @@ func (hnp *HybridNodeProvider) ConfigureAws(ctx context.Context) error {
 	return nil
 }
 
+// LoadAws loads the AWS config of a node that was already inited, without
+// registering it with SSM or writing the IAM Roles Anywhere config.
+func (hnp *HybridNodeProvider) LoadAws(ctx context.Context) error {
+	var awsConfig aws.Config
+	var err error
+	if hnp.nodeConfig.IsSSM() {
+		// registered nodes already have the credentials file, don't wait for it
+		configCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		defer cancel()
+		awsConfig, err = ssm.WaitForAWSConfig(configCtx, hnp.nodeConfig, time.Second)
+	} else {
+		awsConfig, err = LoadAWSConfigForRolesAnywhere(ctx, hnp.nodeConfig)
+	}
+	if err != nil {
+		return fmt.Errorf("loading aws config: %w", err)
+	}
+	hnp.awsConfig = &awsConfig
+	return nil
+}
+
 func (hnp *HybridNodeProvider) GetConfig() *aws.Config {
 	return hnp.awsConfig
 }
@@ -90,6 +111,7 @@ func (c RolesAnywhereAWSConfigurator) Configure(_ context.Context, nodeConfig *a
 		SigningHelperBinPath: iamrolesanywhere.SigningHelperBinPath,
 		CertificatePath:      nodeConfig.Spec.Hybrid.IAMRolesAnywhere.CertificatePath,
 		PrivateKeyPath:       nodeConfig.Spec.Hybrid.IAMRolesAnywhere.PrivateKeyPath,
+		ProxyEnabled:         nodeConfig.IsProxyEnabled(),
 	}); err != nil {
 		return err
 	}
@@ -99,6 +121,7 @@ func (c RolesAnywhereAWSConfigurator) Configure(_ context.Context, nodeConfig *a
 
 func LoadAWSConfigForRolesAnywhere(ctx context.Context, nodeConfig *api.NodeConfig) (aws.Config, error) {
 	return config.LoadDefaultConfig(ctx,
+		trust.WithRootCAs,
 		config.WithRegion(nodeConfig.Spec.Cluster.Region),
 		config.WithSharedConfigFiles([]string{nodeConfig.Spec.Hybrid.IAMRolesAnywhere.AwsConfigPath}),
 		config.WithSharedConfigProfile(iamRoleAnywhereProfileName),
@@ -12,6 +12,7 @@ import (
 
 	"github.com/aws/eks-hybrid/internal/api"
 	"github.com/aws/eks-hybrid/internal/aws/ecr"
+	internaleks "github.com/aws/eks-hybrid/internal/aws/eks"
 )
 
 func (hnp *HybridNodeProvider) Enrich(ctx context.Context) error {
@@ -32,6 +33,21 @@ func (hnp *HybridNodeProvider) Enrich(ctx context.Context) error {
 		hnp.logger.Info("Cluster details populated", zap.Reflect("cluster", hnp.nodeConfig.Spec.Cluster))
 	}
 
+	if hnp.nodeConfig.IsProxyEnabled() {
+		// The cluster details can all be in the config, so nodes whose role
+		// can't call DescribeCluster still join, only without excluding the
+		// pod networks from the proxy.
+		if err := hnp.ensureRemotePodNetworks(ctx); err != nil {
+			hnp.logger.Warn("Can't read the cluster's remote pod networks, they won't be excluded from the proxy. Add them to spec.proxy.noProxy", zap.Error(err))
+		} else {
+			hnp.logger.Info("Remote pod networks populated", zap.Strings("remotePodNetworks", hnp.nodeConfig.Status.Hybrid.RemotePodNetworks))
+		}
+	}
+
+	if err := hnp.ensureNodeIP(ctx); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -82,7 +98,27 @@ func (hnp *HybridNodeProvider) ensureClusterDetails(ctx context.Context) error {
 	}
 
 	if hnp.nodeConfig.Spec.Cluster.CIDR == "" {
-		hnp.nodeConfig.Spec.Cluster.CIDR = *cluster.KubernetesNetworkConfig.ServiceIpv4Cidr
+		hnp.nodeConfig.Spec.Cluster.CIDR = internaleks.ServiceCIDR(cluster)
+	}
+
+	return nil
+}
+
+// ensureRemotePodNetworks populates the cluster's remote pod networks so that they can
+// be excluded from the proxy.
+func (hnp *HybridNodeProvider) ensureRemotePodNetworks(ctx context.Context) error {
+	cluster, err := hnp.getCluster(ctx)
+	if err != nil {
+		return err
+	}
+
+	if cluster.RemoteNetworkConfig == nil {
+		return nil
+	}
+
+	hnp.nodeConfig.Status.Hybrid.RemotePodNetworks = nil
+	for _, network := range cluster.RemoteNetworkConfig.RemotePodNetworks {
+		hnp.nodeConfig.Status.Hybrid.RemotePodNetworks = append(hnp.nodeConfig.Status.Hybrid.RemotePodNetworks, network.Cidrs...)
 	}
 
 	return nil
@@ -407,6 +407,78 @@ func Test_hybridNodeProvider_Enrich(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "proxy is configured",
+			cluster: &types.Cluster{
+				Endpoint: aws_sdk.String("https://my-endpoint.example.com"),
+				Name:     aws_sdk.String("my-cluster"),
+				Status:   types.ClusterStatusActive,
+				CertificateAuthority: &types.Certificate{
+					Data: aws_sdk.String(base64.StdEncoding.EncodeToString([]byte("my-ca-cert"))),
+				},
+				KubernetesNetworkConfig: &types.KubernetesNetworkConfigResponse{
+					ServiceIpv4Cidr: aws_sdk.String("172.0.0.0/16"),
+				},
+				RemoteNetworkConfig: &types.RemoteNetworkConfigResponse{
+					RemoteNodeNetworks: []types.RemoteNodeNetwork{
+						{
+							Cidrs: []string{"10.1.0.0/16"},
+						},
+					},
+					RemotePodNetworks: []types.RemotePodNetwork{
+						{
+							Cidrs: []string{"10.2.0.0/16", "10.3.0.0/16"},
+						},
+					},
+				},
+			},
+			node: &api.NodeConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "my-node",
+				},
+				Spec: api.NodeConfigSpec{
+					Cluster: api.ClusterDetails{
+						Name:                 "my-cluster",
+						Region:               "us-west-2",
+						APIServerEndpoint:    "https://my-endpoint-2.example.com",
+						CertificateAuthority: []byte("my-other-CA"),
+						CIDR:                 "172.1.0.0/16",
+					},
+					Hybrid: &api.HybridOptions{
+						IAMRolesAnywhere: &api.IAMRolesAnywhere{
+							NodeName:       "my-node",
+							TrustAnchorARN: "trust-anchor-arn",
+							ProfileARN:     "profile-arn",
+							RoleARN:        "role-arn",
+						},
+					},
+					Proxy: api.ProxyOptions{
+						HTTPSProxy: "http://proxy.example.com:3128",
+					},
+				},
+				Status: api.NodeConfigStatus{
+					Hybrid: api.HybridDetails{
+						NodeName: "my-node",
+					},
+				},
+			},
+			wantClusterDetails: api.ClusterDetails{
+				Name:                 "my-cluster",
+				Region:               "us-west-2",
+				APIServerEndpoint:    "https://my-endpoint-2.example.com",
+				CertificateAuthority: []byte("my-other-CA"),
+				CIDR:                 "172.1.0.0/16",
+			},
+			wantStatus: api.NodeConfigStatus{
+				Hybrid: api.HybridDetails{
+					NodeName:          "my-node",
+					RemotePodNetworks: []string{"10.2.0.0/16", "10.3.0.0/16"},
+				},
+				Defaults: api.DefaultOptions{
+					SandboxImage: "602401143452.dkr.ecr.us-west-2.amazonaws.com/eks/pause:3.5",
+				},
+			},
+		},
 	}
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
@@ -6,11 +6,17 @@ import (
 	"github.com/pkg/errors"
 
 	"github.com/aws/eks-hybrid/internal/containerd"
+	"github.com/aws/eks-hybrid/internal/cri"
+	"github.com/aws/eks-hybrid/internal/crio"
 	"github.com/aws/eks-hybrid/internal/daemon"
 	"github.com/aws/eks-hybrid/internal/iamrolesanywhere"
 	"github.com/aws/eks-hybrid/internal/kubelet"
+	"github.com/aws/eks-hybrid/internal/nodeprovider"
+	"github.com/aws/eks-hybrid/internal/ssm"
 )
 
+var _ nodeprovider.PreProcessDaemonsGetter = &HybridNodeProvider{}
+
 func (hnp *HybridNodeProvider) withDaemonManager() error {
 	manager, err := daemon.NewDaemonManager()
 	if err != nil {
@@ -24,12 +30,28 @@ func (hnp *HybridNodeProvider) GetDaemons() ([]daemon.Daemon, error) {
 	if hnp.awsConfig == nil {
 		return nil, errors.New("aws config not set")
 	}
+	runtime := cri.InstalledRuntime()
+	runtimeDaemon := containerd.NewContainerdDaemon(hnp.daemonManager, hnp.nodeConfig, hnp.awsConfig, hnp.logger)
+	if runtime == cri.Crio {
+		runtimeDaemon = crio.NewCrioDaemon(hnp.daemonManager, hnp.nodeConfig, hnp.awsConfig, hnp.logger)
+	}
 	return []daemon.Daemon{
-		containerd.NewContainerdDaemon(hnp.daemonManager, hnp.nodeConfig, hnp.awsConfig, hnp.logger),
-		kubelet.NewKubeletDaemon(hnp.daemonManager, hnp.nodeConfig, hnp.awsConfig),
+		runtimeDaemon,
+		kubelet.NewKubeletDaemon(hnp.daemonManager, hnp.nodeConfig, hnp.awsConfig, cri.Endpoint(runtime)),
 	}, nil
 }
 
+func (hnp *HybridNodeProvider) GetPreProcessDaemons() ([]daemon.Daemon, error) {
+	var daemons []daemon.Daemon
+	if hnp.nodeConfig.IsSSM() {
+		daemons = append(daemons, ssm.NewRegisteredSsmDaemon(hnp.daemonManager, hnp.nodeConfig, hnp.logger))
+	}
+	if hnp.nodeConfig.IsIAMRolesAnywhere() && hnp.nodeConfig.Spec.Hybrid.EnableCredentialsFile {
+		daemons = append(daemons, iamrolesanywhere.NewSigningHelperDaemon(hnp.daemonManager, hnp.nodeConfig))
+	}
+	return daemons, nil
+}
+
 func (hnp *HybridNodeProvider) PreProcessDaemon(ctx context.Context) error {
 	if hnp.nodeConfig.IsIAMRolesAnywhere() {
 		if hnp.nodeConfig.Spec.Hybrid.EnableCredentialsFile {
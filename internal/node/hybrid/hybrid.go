@@ -2,10 +2,12 @@ package hybrid
 
 import (
 	"context"
+	"errors"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/eks/types"
 	"go.uber.org/zap"
+	"k8s.io/client-go/kubernetes"
 	"k8s.io/utils/strings/slices"
 
 	"github.com/aws/eks-hybrid/internal/api"
@@ -27,6 +29,7 @@ type HybridNodeProvider struct {
 	cluster       *types.Cluster
 	skipPhases    []string
 	network       Network
+	kubeClient    kubernetes.Interface
 	// InstallRoot is optionally the root directory of the installation
 	// If not provided, the cert
 	installRoot string
@@ -114,6 +117,9 @@ func (hnp *HybridNodeProvider) getCluster(ctx context.Context) (*types.Cluster,
 	if hnp.cluster != nil {
 		return hnp.cluster, nil
 	}
+	if hnp.awsConfig == nil {
+		return nil, errors.New("no AWS config to describe the cluster")
+	}
 
 	cluster, err := readCluster(ctx, *hnp.awsConfig, hnp.nodeConfig)
 	if err != nil {
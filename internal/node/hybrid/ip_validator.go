@@ -3,6 +3,7 @@ package hybrid
 import (
 	"fmt"
 	"net"
+	"strings"
 
 	"github.com/aws/aws-sdk-go-v2/service/eks/types"
 	apimachinerynet "k8s.io/apimachinery/pkg/util/net"
@@ -18,6 +19,8 @@ type Network interface {
 	LookupIP(host string) ([]net.IP, error)
 	ResolveBindAddress(bindAddress net.IP) (net.IP, error)
 	InterfaceAddrs() ([]net.Addr, error)
+	// InterfaceAddrsByName returns the addresses of each interface by name.
+	InterfaceAddrsByName() (map[string][]net.Addr, error)
 }
 
 // defaultKubeletNetwork provides the network util functions used by kubelet.
@@ -35,6 +38,22 @@ func (u defaultKubeletNetwork) InterfaceAddrs() ([]net.Addr, error) {
 	return net.InterfaceAddrs()
 }
 
+func (u defaultKubeletNetwork) InterfaceAddrsByName() (map[string][]net.Addr, error) {
+	interfaces, err := net.Interfaces()
+	if err != nil {
+		return nil, err
+	}
+	addrsByName := make(map[string][]net.Addr, len(interfaces))
+	for _, iface := range interfaces {
+		addrs, err := iface.Addrs()
+		if err != nil {
+			return nil, err
+		}
+		addrsByName[iface.Name] = addrs
+	}
+	return addrsByName, nil
+}
+
 func containsIP(cidr string, ip net.IP) (bool, error) {
 	_, ipnet, err := net.ParseCIDR(cidr)
 	if err != nil {
@@ -45,7 +64,7 @@ func containsIP(cidr string, ip net.IP) (bool, error) {
 }
 
 func isIPInCIDRs(ip net.IP, cidrs []string) (bool, error) {
-	if ip.To4() == nil {
+	if ip.To16() == nil {
 		return false, fmt.Errorf("error: ip is invalid")
 	}
 
@@ -72,21 +91,28 @@ func extractCIDRsFromNodeNetworks(networks []types.RemoteNodeNetwork) []string {
 	return cidrs
 }
 
-func extractNodeIPFromFlags(kubeletArgs []string) (net.IP, error) {
+// extractNodeIPsFromFlags returns the addresses of the --node-ip flag, one
+// address or one of each IP family for dual-stack nodes.
+func extractNodeIPsFromFlags(kubeletArgs []string) ([]net.IP, error) {
 	ipStr := extractFlagValue(kubeletArgs, nodeIPFlag)
 
-	if ipStr != "" {
-		ip := net.ParseIP(ipStr)
+	if ipStr == "" {
+		//--node-ip flag not set
+		return nil, nil
+	}
+
+	var ips []net.IP
+	for _, value := range strings.Split(ipStr, ",") {
+		ip := net.ParseIP(strings.TrimSpace(value))
 		if ip == nil {
-			return nil, fmt.Errorf("invalid ip %s in --node-ip flag. only 1 IPv4 address is allowed", ipStr)
-		} else if ip.To4() == nil {
-			return nil, fmt.Errorf("invalid IPv6 address %s in --node-ip flag. only IPv4 is supported", ipStr)
+			return nil, fmt.Errorf("invalid ip %s in --node-ip flag. only 1 address, or 1 IPv4 and 1 IPv6 address for dual-stack, are allowed", ipStr)
 		}
-		return ip, nil
+		ips = append(ips, ip)
 	}
-
-	//--node-ip flag not set
-	return nil, nil
+	if len(ips) > 2 || (len(ips) == 2 && (ips[0].To4() == nil) == (ips[1].To4() == nil)) {
+		return nil, fmt.Errorf("invalid ips %s in --node-ip flag. dual-stack nodes need 1 IPv4 and 1 IPv6 address", ipStr)
+	}
+	return ips, nil
 }
 
 func validateClusterRemoteNetworkConfig(cluster *types.Cluster) error {
@@ -140,8 +166,8 @@ func validateNodeIP(nodeIP net.IP, interfaceAddrs func() ([]net.Addr, error)) er
 	return fmt.Errorf("node IP: %q not found in the host's network interfaces", nodeIP.String())
 }
 
-// getNodeIP determines the node's IP address based on kubelet configuration and system information.
-func getNodeIP(kubeletArgs []string, nodeName string, network Network) (net.IP, error) {
+// getNodeIPs determines the node's IP addresses based on kubelet configuration and system information.
+func getNodeIPs(kubeletArgs []string, nodeName string, network Network) ([]net.IP, error) {
 	// Follows algorithm used by kubelet to assign nodeIP
 	// Implementation adapted for hybrid nodes
 	// 1) Use nodeIP if set (and not "0.0.0.0"/"::")
@@ -150,24 +176,32 @@ func getNodeIP(kubeletArgs []string, nodeName string, network Network) (net.IP,
 	// 4) Try to get the IP from the network interface used as default gateway
 	// Source: https://github.com/kubernetes/kubernetes/blob/master/pkg/kubelet/nodestatus/setters.go#L206
 
-	nodeIP, err := extractNodeIPFromFlags(kubeletArgs)
+	nodeIPs, err := extractNodeIPsFromFlags(kubeletArgs)
 	if err != nil {
 		return nil, err
 	}
+	if len(nodeIPs) == 2 {
+		return nodeIPs, nil
+	}
 
-	var ipAddr net.IP
+	var nodeIP, ipAddr net.IP
+	if len(nodeIPs) == 1 {
+		nodeIP = nodeIPs[0]
+	}
 
-	nodeIPSpecified := nodeIP != nil && nodeIP.To4() != nil && !nodeIP.IsUnspecified()
+	nodeIPSpecified := nodeIP != nil && !nodeIP.IsUnspecified()
 
 	if nodeIPSpecified {
 		ipAddr = nodeIP
 	} else {
+		// Like kubelet, "::" prefers IPv6 addresses and anything else IPv4 ones
+		preferIPv6 := nodeIP != nil && nodeIP.To4() == nil
 		// If using SSM, the node name will be set at initialization to the SSM instance ID,
 		// so it won't resolve to anything via DNS, hence we're only checking in the case of IAM-RA
 		if nodeName != "" {
 			addrs, _ := network.LookupIP(nodeName)
 			for _, addr := range addrs {
-				if err = validateNodeIP(addr, network.InterfaceAddrs); addr.To4() != nil && err == nil {
+				if err = validateNodeIP(addr, network.InterfaceAddrs); (addr.To4() == nil) == preferIPv6 && err == nil {
 					ipAddr = addr
 					break
 				}
@@ -185,7 +219,7 @@ func getNodeIP(kubeletArgs []string, nodeName string, network Network) (net.IP,
 
 	}
 
-	return ipAddr, nil
+	return []net.IP{ipAddr}, nil
 }
 
 func validateIPInRemoteNodeNetwork(ipAddr net.IP, remoteNodeNetwork []types.RemoteNodeNetwork) error {
@@ -212,14 +246,14 @@ func (hnp *HybridNodeProvider) ValidateNodeIP() error {
 
 		// Only check flags set by user in the config file to help determine IP:
 		// - node-ip and hostname-override are only available as flags and cannot be set via spec.kubelet.config
-		// - Hybrid nodes does not set --node-ip
+		// - Hybrid nodes sets --node-ip to the IP selected on enrich, if any
 		// - Hybrid nodes sets --hostname-override to either the IAM-RA Node name or the SSM instance ID, which is checked separately for DNS
-		kubeletArgs := hnp.nodeConfig.Spec.Kubelet.Flags
+		kubeletArgs := withNodeIPFlag(hnp.nodeConfig.Spec.Kubelet.Flags, hnp.nodeConfig.Status.Hybrid.NodeIP)
 		var iamNodeName string
 		if hnp.nodeConfig.IsIAMRolesAnywhere() {
 			iamNodeName = hnp.nodeConfig.Status.Hybrid.NodeName
 		}
-		nodeIp, err := getNodeIP(kubeletArgs, iamNodeName, hnp.network)
+		nodeIps, err := getNodeIPs(kubeletArgs, iamNodeName, hnp.network)
 		if err != nil {
 			return err
 		}
@@ -229,8 +263,10 @@ func (hnp *HybridNodeProvider) ValidateNodeIP() error {
 			return err
 		}
 
-		if err = validateIPInRemoteNodeNetwork(nodeIp, cluster.RemoteNetworkConfig.RemoteNodeNetworks); err != nil {
-			return err
+		for _, nodeIp := range nodeIps {
+			if err = validateIPInRemoteNodeNetwork(nodeIp, cluster.RemoteNetworkConfig.RemoteNodeNetworks); err != nil {
+				return err
+			}
 		}
 	}
 
@@ -206,7 +206,7 @@ func TestHybridNodeProvider_ValidateNodeIP(t *testing.T) {
 					},
 				},
 			},
-			expectedErr: "invalid ip invalid-ip in --node-ip flag. only 1 IPv4 address is allowed",
+			expectedErr: "invalid ip invalid-ip in --node-ip flag. only 1 address, or 1 IPv4 and 1 IPv6 address for dual-stack, are allowed",
 		},
 		{
 			name: "node ip found via DNS within remote node network",
@@ -487,6 +487,9 @@ type mockNetwork struct {
 	// For interface addresses
 	NetworkInterfaces []net.Addr
 	InterfacesErr     error
+
+	// For interface addresses by name
+	NamedInterfaces map[string][]net.Addr
 }
 
 func (m *mockNetwork) LookupIP(host string) ([]net.IP, error) {
@@ -507,3 +510,7 @@ func (m *mockNetwork) ResolveBindAddress(bindAddress net.IP) (net.IP, error) {
 func (m *mockNetwork) InterfaceAddrs() ([]net.Addr, error) {
 	return m.NetworkInterfaces, m.InterfacesErr
 }
+
+func (m *mockNetwork) InterfaceAddrsByName() (map[string][]net.Addr, error) {
+	return m.NamedInterfaces, m.InterfacesErr
+}
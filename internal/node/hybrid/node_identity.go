@@ -0,0 +1,170 @@
+package hybrid
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/aws/eks-hybrid/internal/kubelet"
+	"github.com/aws/eks-hybrid/internal/nodeprovider"
+)
+
+// machineIDAnnotation records the machine ID of the host that inited the node.
+const machineIDAnnotation = "node.eks.aws/machine-id"
+
+var (
+	machineIDPath = "/etc/machine-id"
+
+	recordNodeIdentityInterval = 2 * time.Second
+	recordNodeIdentityTimeout  = 30 * time.Second
+)
+
+var _ nodeprovider.NodeIdentityChecker = &HybridNodeProvider{}
+
+// CheckNodeIdentity fails when a Node with the name of this node exists and
+// looks like a different machine, so two hosts with the same node name don't
+// take turns on the same Node object. The API server can't be reached before
+// the node credentials work, so failures to read the Node skip the check.
+func (hnp *HybridNodeProvider) CheckNodeIdentity(ctx context.Context, force bool) error {
+	nodeName := hnp.nodeConfig.Status.Hybrid.NodeName
+	client, err := hnp.kubernetesClient()
+	if err != nil {
+		hnp.logger.Warn("Skipping node identity check, can't build a Kubernetes client", zap.Error(err))
+		return nil
+	}
+	node, err := client.CoreV1().Nodes().Get(ctx, nodeName, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil
+	} else if err != nil {
+		hnp.logger.Warn("Skipping node identity check, can't read the node", zap.String("node", nodeName), zap.Error(err))
+		return nil
+	}
+
+	differences, err := hnp.nodeIdentityDifferences(node)
+	if err != nil {
+		return err
+	}
+	if len(differences) == 0 {
+		return nil
+	}
+	if force {
+		hnp.logger.Warn("Taking over the node of a different machine", zap.String("node", nodeName), zap.Strings("differences", differences))
+		return nil
+	}
+	return fmt.Errorf("node %s already exists and belongs to a different machine: %s. Use a unique node name, delete the node from the cluster, or run with --force to take it over",
+		nodeName, strings.Join(differences, ", "))
+}
+
+// nodeIdentityDifferences compares the Node with this host. The machine ID
+// annotation recognizes re-inits of the same host, even when its addresses
+// changed. Otherwise the Node is compared with what kubelet registers for this
+// host.
+func (hnp *HybridNodeProvider) nodeIdentityDifferences(node *corev1.Node) ([]string, error) {
+	machineID, err := readMachineID()
+	if err != nil {
+		return nil, err
+	}
+
+	if nodeMachineID, ok := node.Annotations[machineIDAnnotation]; ok && machineID != "" {
+		if nodeMachineID != machineID {
+			return []string{fmt.Sprintf("machine ID %s instead of %s", nodeMachineID, machineID)}, nil
+		}
+		if addresses := hnp.missingNodeAddresses(node); len(addresses) > 0 {
+			hnp.logger.Warn("The node addresses are not found on this host, the host addresses may have changed",
+				zap.String("node", node.Name), zap.Strings("addresses", addresses))
+		}
+		return nil, nil
+	}
+
+	var differences []string
+	if providerID := kubelet.HybridProviderID(hnp.nodeConfig); node.Spec.ProviderID != "" && node.Spec.ProviderID != providerID {
+		differences = append(differences, fmt.Sprintf("providerID %s instead of %s", node.Spec.ProviderID, providerID))
+	}
+	nodeType := string(hnp.nodeConfig.GetNodeType())
+	if provider, ok := node.Labels[kubelet.CredentialProviderLabelKey]; ok && provider != nodeType {
+		differences = append(differences, fmt.Sprintf("credential provider %s instead of %s", provider, nodeType))
+	}
+	if addresses := hnp.missingNodeAddresses(node); len(addresses) > 0 {
+		differences = append(differences, fmt.Sprintf("addresses %s not found on this host", addresses))
+	}
+	return differences, nil
+}
+
+// missingNodeAddresses returns the internal IPs of the Node when none of them
+// is on this host.
+func (hnp *HybridNodeProvider) missingNodeAddresses(node *corev1.Node) []string {
+	var nodeIPs []string
+	for _, address := range node.Status.Addresses {
+		if address.Type != corev1.NodeInternalIP {
+			continue
+		}
+		nodeIPs = append(nodeIPs, address.Address)
+		if ip := net.ParseIP(address.Address); ip != nil && validateNodeIP(ip, hnp.network.InterfaceAddrs) == nil {
+			// one of the node IPs is on this host
+			return nil
+		}
+	}
+	return nodeIPs
+}
+
+// RecordNodeIdentity annotates the Node with the machine ID of this host once
+// kubelet registers it. It's best effort, the node identity check falls back
+// to the providerID, labels and addresses of Nodes without it.
+func (hnp *HybridNodeProvider) RecordNodeIdentity(ctx context.Context) error {
+	machineID, err := readMachineID()
+	if err != nil || machineID == "" {
+		return err
+	}
+	client, err := hnp.kubernetesClient()
+	if err != nil {
+		hnp.logger.Warn("Can't record the machine ID on the node", zap.Error(err))
+		return nil
+	}
+
+	nodeName := hnp.nodeConfig.Status.Hybrid.NodeName
+	patch := fmt.Sprintf(`{"metadata":{"annotations":{%q:%q}}}`, machineIDAnnotation, machineID)
+	err = wait.PollUntilContextTimeout(ctx, recordNodeIdentityInterval, recordNodeIdentityTimeout, true, func(ctx context.Context) (bool, error) {
+		_, err := client.CoreV1().Nodes().Patch(ctx, nodeName, types.MergePatchType, []byte(patch), metav1.PatchOptions{})
+		if apierrors.IsNotFound(err) {
+			// kubelet hasn't registered the node yet
+			return false, nil
+		}
+		return err == nil, err
+	})
+	if err != nil {
+		hnp.logger.Warn("Can't record the machine ID on the node", zap.String("node", nodeName), zap.Error(err))
+		return nil
+	}
+	hnp.logger.Info("Recorded machine ID on the node", zap.String("node", nodeName), zap.String("machineID", machineID))
+	return nil
+}
+
+func (hnp *HybridNodeProvider) kubernetesClient() (kubernetes.Interface, error) {
+	if hnp.kubeClient != nil {
+		return hnp.kubeClient, nil
+	}
+	return kubelet.GetKubeClientFromKubeConfig()
+}
+
+// readMachineID returns the machine ID of the host, or an empty string if it
+// doesn't have one.
+func readMachineID() (string, error) {
+	data, err := os.ReadFile(machineIDPath)
+	if os.IsNotExist(err) {
+		return "", nil
+	} else if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(data)), nil
+}
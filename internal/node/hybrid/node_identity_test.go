@@ -0,0 +1,162 @@
+package hybrid
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/aws/eks-hybrid/internal/api"
+)
+
+const testMachineID = "0123456789abcdef0123456789abcdef"
+
+func useTempMachineID(t *testing.T, machineID string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "machine-id")
+	if machineID != "" {
+		if err := os.WriteFile(path, []byte(machineID+"\n"), 0o444); err != nil {
+			t.Fatal(err)
+		}
+	}
+	original := machineIDPath
+	machineIDPath = path
+	t.Cleanup(func() { machineIDPath = original })
+}
+
+func identityNodeConfig() *api.NodeConfig {
+	return &api.NodeConfig{
+		Spec: api.NodeConfigSpec{
+			Cluster: api.ClusterDetails{Name: "test-cluster", Region: "us-west-2"},
+			Hybrid: &api.HybridOptions{
+				IAMRolesAnywhere: &api.IAMRolesAnywhere{NodeName: "node-1"},
+			},
+		},
+		Status: api.NodeConfigStatus{
+			Hybrid: api.HybridDetails{NodeName: "node-1"},
+		},
+	}
+}
+
+func identityNode(modify func(*corev1.Node)) *corev1.Node {
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   "node-1",
+			Labels: map[string]string{"eks.amazonaws.com/hybrid-credential-provider": "iam-ra"},
+		},
+		Spec: corev1.NodeSpec{ProviderID: "eks-hybrid:///us-west-2/test-cluster/node-1"},
+		Status: corev1.NodeStatus{
+			Addresses: []corev1.NodeAddress{{Type: corev1.NodeInternalIP, Address: "10.0.0.5"}},
+		},
+	}
+	if modify != nil {
+		modify(node)
+	}
+	return node
+}
+
+func TestCheckNodeIdentity(t *testing.T) {
+	testCases := []struct {
+		name      string
+		machineID string
+		node      *corev1.Node
+		force     bool
+		wantErr   string
+	}{
+		{
+			name:      "no node",
+			machineID: testMachineID,
+		},
+		{
+			name:      "same machine ID",
+			machineID: testMachineID,
+			node: identityNode(func(n *corev1.Node) {
+				n.Annotations = map[string]string{machineIDAnnotation: testMachineID}
+			}),
+		},
+		{
+			name:      "same machine ID and different addresses",
+			machineID: testMachineID,
+			node: identityNode(func(n *corev1.Node) {
+				n.Annotations = map[string]string{machineIDAnnotation: testMachineID}
+				n.Spec.ProviderID = "eks-hybrid:///us-west-2/test-cluster/other"
+				n.Status.Addresses[0].Address = "192.168.1.1"
+			}),
+		},
+		{
+			name:      "different machine ID",
+			machineID: testMachineID,
+			node: identityNode(func(n *corev1.Node) {
+				n.Annotations = map[string]string{machineIDAnnotation: "fedcba9876543210fedcba9876543210"}
+			}),
+			wantErr: "node node-1 already exists and belongs to a different machine: machine ID fedcba9876543210fedcba9876543210 instead of 0123456789abcdef0123456789abcdef. Use a unique node name, delete the node from the cluster, or run with --force to take it over",
+		},
+		{
+			name:      "different machine ID with force",
+			machineID: testMachineID,
+			node: identityNode(func(n *corev1.Node) {
+				n.Annotations = map[string]string{machineIDAnnotation: "fedcba9876543210fedcba9876543210"}
+			}),
+			force: true,
+		},
+		{
+			name:      "no annotation and matching node",
+			machineID: testMachineID,
+			node:      identityNode(nil),
+		},
+		{
+			name: "no machine ID and different node",
+			node: identityNode(func(n *corev1.Node) {
+				n.Labels["eks.amazonaws.com/hybrid-credential-provider"] = "ssm"
+				n.Spec.ProviderID = "eks-hybrid:///us-west-2/other-cluster/node-1"
+				n.Status.Addresses[0].Address = "192.168.1.1"
+			}),
+			wantErr: "node node-1 already exists and belongs to a different machine: providerID eks-hybrid:///us-west-2/other-cluster/node-1 instead of eks-hybrid:///us-west-2/test-cluster/node-1, credential provider ssm instead of iam-ra, addresses [192.168.1.1] not found on this host. Use a unique node name, delete the node from the cluster, or run with --force to take it over",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			useTempMachineID(t, tc.machineID)
+			client := fake.NewSimpleClientset()
+			if tc.node != nil {
+				client = fake.NewSimpleClientset(tc.node)
+			}
+			hnp := &HybridNodeProvider{
+				nodeConfig: identityNodeConfig(),
+				logger:     zap.NewNop(),
+				network:    interfacesNetwork{"eth0": {ipNet("10.0.0.5/24")}},
+				kubeClient: client,
+			}
+			err := hnp.CheckNodeIdentity(context.Background(), tc.force)
+			if tc.wantErr != "" {
+				g.Expect(err).To(MatchError(tc.wantErr))
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+		})
+	}
+}
+
+func TestRecordNodeIdentity(t *testing.T) {
+	g := NewWithT(t)
+	useTempMachineID(t, testMachineID)
+	client := fake.NewSimpleClientset(identityNode(nil))
+	hnp := &HybridNodeProvider{
+		nodeConfig: identityNodeConfig(),
+		logger:     zap.NewNop(),
+		network:    interfacesNetwork{"eth0": {ipNet("10.0.0.5/24")}},
+		kubeClient: client,
+	}
+
+	g.Expect(hnp.RecordNodeIdentity(context.Background())).To(Succeed())
+	node, err := client.CoreV1().Nodes().Get(context.Background(), "node-1", metav1.GetOptions{})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(node.Annotations).To(HaveKeyWithValue(machineIDAnnotation, testMachineID))
+}
@@ -0,0 +1,162 @@
+package hybrid
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"slices"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"github.com/aws/eks-hybrid/internal/api"
+)
+
+// nodeIPCandidate is an address of a host interface that can be the node IP.
+type nodeIPCandidate struct {
+	ip    net.IP
+	iface string
+}
+
+func (c nodeIPCandidate) String() string {
+	return fmt.Sprintf("%s (%s)", c.ip, c.iface)
+}
+
+// ensureNodeIP selects the node IP for the kubelet when the --node-ip flag is
+// not set: the address of spec.kubelet.nodeIPInterface, or the address in the
+// cluster's remote node networks. With IPv4 and IPv6 addresses in the remote
+// node networks, the node is dual-stack and gets one of each. Without a match,
+// kubelet picks the node IP.
+func (hnp *HybridNodeProvider) ensureNodeIP(ctx context.Context) error {
+	if extractFlagValue(hnp.nodeConfig.Spec.Kubelet.Flags, nodeIPFlag) != "" {
+		return nil
+	}
+	iface := hnp.nodeConfig.Spec.Kubelet.NodeIPInterface
+	if iface == "" && hnp.cluster == nil {
+		// the cluster isn't described when its details are all in the config
+		if _, err := hnp.getCluster(ctx); err != nil {
+			hnp.logger.Warn("Can't read the cluster's remote node networks, leaving the node IP to kubelet", zap.Error(err))
+			return nil
+		}
+	}
+	var cidrs []string
+	if hnp.cluster != nil && validateClusterRemoteNetworkConfig(hnp.cluster) == nil {
+		cidrs = extractCIDRsFromNodeNetworks(hnp.cluster.RemoteNetworkConfig.RemoteNodeNetworks)
+	}
+	if iface == "" && len(cidrs) == 0 {
+		return nil
+	}
+
+	candidates, err := nodeIPCandidates(hnp.network, iface, cidrs)
+	if err != nil {
+		return err
+	}
+	if len(cidrs) == 0 {
+		// without remote node networks, only the family of the cluster is used
+		ipv6 := isIPv6CIDR(hnp.nodeConfig.Spec.Cluster.CIDR)
+		candidates = slices.DeleteFunc(candidates, func(c nodeIPCandidate) bool { return (c.ip.To4() == nil) != ipv6 })
+	}
+	if len(candidates) == 0 {
+		if iface != "" && len(cidrs) > 0 {
+			return fmt.Errorf("interface %s in spec.kubelet.nodeIPInterface has no address in the remote node networks: %s", iface, cidrs)
+		} else if iface != "" {
+			return fmt.Errorf("interface %s in spec.kubelet.nodeIPInterface has no address in the cluster IP family", iface)
+		}
+		hnp.logger.Warn("No host address in the remote node networks, leaving the node IP to kubelet", zap.Strings("remoteNodeNetworks", cidrs))
+		return nil
+	}
+
+	selected := selectNodeIPs(candidates)
+	var nodeIPs []string
+	for _, candidate := range selected {
+		nodeIPs = append(nodeIPs, candidate.ip.String())
+	}
+	if len(candidates) > len(selected) {
+		hnp.logger.Warn("Several host addresses can be the node IP, using the first one of each IP family. Set spec.kubelet.nodeIPInterface or the --node-ip kubelet flag to choose another",
+			zap.Stringers("candidates", candidates), zap.Stringers("nodeIP", selected))
+	} else {
+		hnp.logger.Info("Selected node IP", zap.Stringers("nodeIP", selected))
+	}
+	hnp.nodeConfig.Status.Hybrid.NodeIP = strings.Join(nodeIPs, ",")
+	return nil
+}
+
+// selectNodeIPs returns the first candidate of each IP family, IPv4 first as
+// kubelet uses the first node IP as the primary one.
+func selectNodeIPs(candidates []nodeIPCandidate) []nodeIPCandidate {
+	var ipv4, ipv6 []nodeIPCandidate
+	for _, candidate := range candidates {
+		if candidate.ip.To4() != nil && len(ipv4) == 0 {
+			ipv4 = append(ipv4, candidate)
+		} else if candidate.ip.To4() == nil && len(ipv6) == 0 {
+			ipv6 = append(ipv6, candidate)
+		}
+	}
+	return append(ipv4, ipv6...)
+}
+
+func isIPv6CIDR(cidr string) bool {
+	family, err := api.GetCIDRIpFamily(cidr)
+	return err == nil && family == api.IPFamilyIPv6
+}
+
+// nodeIPCandidates returns the addresses of the host interfaces that can be
+// the node IP, sorted by interface name. They are limited to the interface
+// when set and to the CIDRs when there are some.
+func nodeIPCandidates(network Network, iface string, cidrs []string) ([]nodeIPCandidate, error) {
+	addrsByName, err := network.InterfaceAddrsByName()
+	if err != nil {
+		return nil, err
+	}
+	if iface != "" {
+		if _, ok := addrsByName[iface]; !ok {
+			return nil, fmt.Errorf("interface %s in spec.kubelet.nodeIPInterface not found on the host", iface)
+		}
+	}
+	names := make([]string, 0, len(addrsByName))
+	for name := range addrsByName {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	var candidates []nodeIPCandidate
+	for _, name := range names {
+		if iface != "" && name != iface {
+			continue
+		}
+		for _, addr := range addrsByName[name] {
+			ip := addrIP(addr)
+			if ip == nil || ip.IsLoopback() || ip.IsLinkLocalUnicast() || ip.IsMulticast() || ip.IsUnspecified() {
+				continue
+			}
+			if len(cidrs) > 0 {
+				if inNetwork, err := isIPInCIDRs(ip, cidrs); err != nil {
+					return nil, err
+				} else if !inNetwork {
+					continue
+				}
+			}
+			candidates = append(candidates, nodeIPCandidate{ip: ip, iface: name})
+		}
+	}
+	return candidates, nil
+}
+
+func addrIP(addr net.Addr) net.IP {
+	switch v := addr.(type) {
+	case *net.IPNet:
+		return v.IP
+	case *net.IPAddr:
+		return v.IP
+	}
+	return nil
+}
+
+// withNodeIPFlag adds the selected node IP to the kubelet flags, for the
+// validations to check the IP kubelet will use.
+func withNodeIPFlag(flags []string, nodeIP string) []string {
+	if nodeIP == "" {
+		return flags
+	}
+	return append(slices.Clone(flags), fmt.Sprintf("--%s=%s", nodeIPFlag, nodeIP))
+}
@@ -0,0 +1,203 @@
+package hybrid
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/eks/types"
+	. "github.com/onsi/gomega"
+	"go.uber.org/zap"
+
+	"github.com/aws/eks-hybrid/internal/api"
+)
+
+// interfacesNetwork only knows the host interfaces.
+type interfacesNetwork map[string][]net.Addr
+
+func (n interfacesNetwork) LookupIP(string) ([]net.IP, error) { return nil, nil }
+
+func (n interfacesNetwork) ResolveBindAddress(net.IP) (net.IP, error) { return nil, nil }
+
+func (n interfacesNetwork) InterfaceAddrs() ([]net.Addr, error) {
+	var addrs []net.Addr
+	for _, ifaceAddrs := range n {
+		addrs = append(addrs, ifaceAddrs...)
+	}
+	return addrs, nil
+}
+
+func (n interfacesNetwork) InterfaceAddrsByName() (map[string][]net.Addr, error) { return n, nil }
+
+func ipNet(cidr string) *net.IPNet {
+	ip, ipnet, _ := net.ParseCIDR(cidr)
+	ipnet.IP = ip
+	return ipnet
+}
+
+func TestEnsureNodeIP(t *testing.T) {
+	network := interfacesNetwork{
+		"lo":    {ipNet("127.0.0.1/8")},
+		"eth0":  {ipNet("192.168.1.10/24"), ipNet("fe80::1/64")},
+		"eth1":  {ipNet("10.0.0.5/24"), ipNet("2001:db8::5/64")},
+		"bond0": {ipNet("10.0.1.7/24")},
+	}
+	cluster := &types.Cluster{
+		Name: aws.String("test-cluster"),
+		RemoteNetworkConfig: &types.RemoteNetworkConfigResponse{
+			RemoteNodeNetworks: []types.RemoteNodeNetwork{{Cidrs: []string{"10.0.0.0/16"}}},
+		},
+	}
+	testCases := []struct {
+		name        string
+		kubelet     api.KubeletOptions
+		clusterCIDR string
+		cluster     *types.Cluster
+		want        string
+		wantErr     string
+	}{
+		{
+			name: "single match",
+			cluster: &types.Cluster{
+				Name: aws.String("test-cluster"),
+				RemoteNetworkConfig: &types.RemoteNetworkConfigResponse{
+					RemoteNodeNetworks: []types.RemoteNodeNetwork{{Cidrs: []string{"10.0.0.0/24"}}},
+				},
+			},
+			want: "10.0.0.5",
+		},
+		{
+			name:    "several matches use the first interface by name",
+			cluster: cluster,
+			want:    "10.0.1.7",
+		},
+		{
+			name:    "interface",
+			kubelet: api.KubeletOptions{NodeIPInterface: "eth1"},
+			cluster: cluster,
+			want:    "10.0.0.5",
+		},
+		{
+			name:    "interface without cluster",
+			kubelet: api.KubeletOptions{NodeIPInterface: "eth0"},
+			want:    "192.168.1.10",
+		},
+		{
+			name:        "interface without cluster in IPv6 cluster",
+			kubelet:     api.KubeletOptions{NodeIPInterface: "eth1"},
+			clusterCIDR: "fd00::/108",
+			want:        "2001:db8::5",
+		},
+		{
+			name:        "interface without cluster address in the cluster IP family",
+			kubelet:     api.KubeletOptions{NodeIPInterface: "eth0"},
+			clusterCIDR: "fd00::/108",
+			wantErr:     "interface eth0 in spec.kubelet.nodeIPInterface has no address in the cluster IP family",
+		},
+		{
+			name: "dual-stack remote node networks",
+			cluster: &types.Cluster{
+				Name: aws.String("test-cluster"),
+				RemoteNetworkConfig: &types.RemoteNetworkConfigResponse{
+					RemoteNodeNetworks: []types.RemoteNodeNetwork{{Cidrs: []string{"2001:db8::/64", "10.0.0.0/16"}}},
+				},
+			},
+			want: "10.0.1.7,2001:db8::5",
+		},
+		{
+			name:    "interface outside remote node networks",
+			kubelet: api.KubeletOptions{NodeIPInterface: "eth0"},
+			cluster: cluster,
+			wantErr: "interface eth0 in spec.kubelet.nodeIPInterface has no address in the remote node networks: [10.0.0.0/16]",
+		},
+		{
+			name:    "missing interface",
+			kubelet: api.KubeletOptions{NodeIPInterface: "eth9"},
+			wantErr: "interface eth9 in spec.kubelet.nodeIPInterface not found on the host",
+		},
+		{
+			name:    "node-ip flag",
+			kubelet: api.KubeletOptions{Flags: []string{"--node-ip=10.0.1.7"}, NodeIPInterface: "eth1"},
+			cluster: cluster,
+		},
+		{
+			name: "no match",
+			cluster: &types.Cluster{
+				Name: aws.String("test-cluster"),
+				RemoteNetworkConfig: &types.RemoteNetworkConfigResponse{
+					RemoteNodeNetworks: []types.RemoteNodeNetwork{{Cidrs: []string{"172.16.0.0/16"}}},
+				},
+			},
+		},
+		{
+			name: "no cluster",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			hnp := &HybridNodeProvider{
+				nodeConfig: &api.NodeConfig{Spec: api.NodeConfigSpec{
+					Cluster: api.ClusterDetails{CIDR: tc.clusterCIDR},
+					Kubelet: tc.kubelet,
+				}},
+				logger:  zap.NewNop(),
+				cluster: tc.cluster,
+				network: network,
+			}
+			err := hnp.ensureNodeIP(context.Background())
+			if tc.wantErr != "" {
+				g.Expect(err).To(MatchError(tc.wantErr))
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(hnp.nodeConfig.Status.Hybrid.NodeIP).To(Equal(tc.want))
+		})
+	}
+}
+
+func TestExtractNodeIPsFromFlags(t *testing.T) {
+	testCases := []struct {
+		name    string
+		flags   []string
+		want    []net.IP
+		wantErr string
+	}{
+		{
+			name: "not set",
+		},
+		{
+			name:  "IPv6",
+			flags: []string{"--node-ip=2001:db8::5"},
+			want:  []net.IP{net.ParseIP("2001:db8::5")},
+		},
+		{
+			name:  "dual-stack",
+			flags: []string{"--node-ip=10.0.0.5,2001:db8::5"},
+			want:  []net.IP{net.ParseIP("10.0.0.5"), net.ParseIP("2001:db8::5")},
+		},
+		{
+			name:    "two IPv4",
+			flags:   []string{"--node-ip=10.0.0.5,10.0.0.6"},
+			wantErr: "invalid ips 10.0.0.5,10.0.0.6 in --node-ip flag. dual-stack nodes need 1 IPv4 and 1 IPv6 address",
+		},
+		{
+			name:    "invalid",
+			flags:   []string{"--node-ip=10.0.0.5,invalid"},
+			wantErr: "invalid ip 10.0.0.5,invalid in --node-ip flag. only 1 address, or 1 IPv4 and 1 IPv6 address for dual-stack, are allowed",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			ips, err := extractNodeIPsFromFlags(tc.flags)
+			if tc.wantErr != "" {
+				g.Expect(err).To(MatchError(tc.wantErr))
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(ips).To(Equal(tc.want))
+		})
+	}
+}
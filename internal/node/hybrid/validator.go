@@ -5,6 +5,9 @@ import (
 	"strings"
 
 	"github.com/aws/eks-hybrid/internal/api"
+	"github.com/aws/eks-hybrid/internal/containerd"
+	"github.com/aws/eks-hybrid/internal/localdisk"
+	"github.com/aws/eks-hybrid/internal/system"
 	"github.com/aws/eks-hybrid/internal/util/file"
 )
 
@@ -52,6 +55,21 @@ func (hnp *HybridNodeProvider) withHybridValidators() {
 				return fmt.Errorf("ActivationID is missing in hybrid ssm configuration")
 			}
 		}
+		if err := containerd.Validate(cfg); err != nil {
+			return err
+		}
+		if err := localdisk.Validate(cfg); err != nil {
+			return err
+		}
+		if err := system.ValidateDataDirs(cfg); err != nil {
+			return err
+		}
+		if err := system.ValidateKernelSettings(cfg); err != nil {
+			return err
+		}
+		if err := system.ValidateCNI(cfg); err != nil {
+			return err
+		}
 		return nil
 	}
 }
@@ -3,10 +3,13 @@ package node
 import (
 	"go.uber.org/zap"
 
+	"github.com/aws/eks-hybrid/internal/api"
 	"github.com/aws/eks-hybrid/internal/configprovider"
 	"github.com/aws/eks-hybrid/internal/node/ec2"
 	"github.com/aws/eks-hybrid/internal/node/hybrid"
 	"github.com/aws/eks-hybrid/internal/nodeprovider"
+	"github.com/aws/eks-hybrid/internal/proxy"
+	"github.com/aws/eks-hybrid/internal/trust"
 )
 
 func NewNodeProvider(configSource string, skipPhases []string, logger *zap.Logger) (nodeprovider.NodeProvider, error) {
@@ -19,6 +22,24 @@ func NewNodeProvider(configSource string, skipPhases []string, logger *zap.Logge
 	if err != nil {
 		return nil, err
 	}
+	if len(nodeConfig.Spec.Trust.AdditionalCABundles) > 0 {
+		logger.Info("Trusting additional CA bundles..")
+		if err := trust.Configure(nodeConfig); err != nil {
+			return nil, err
+		}
+	}
+	if nodeConfig.IsProxyEnabled() {
+		logger.Info("Configuring HTTP proxy..", zap.Reflect("proxy", nodeConfig.Spec.Proxy))
+		if err := proxy.Configure(nodeConfig); err != nil {
+			return nil, err
+		}
+	}
+	return NewNodeProviderFromConfig(nodeConfig, skipPhases, logger)
+}
+
+// NewNodeProviderFromConfig returns the node provider of a node config that
+// was already loaded.
+func NewNodeProviderFromConfig(nodeConfig *api.NodeConfig, skipPhases []string, logger *zap.Logger) (nodeprovider.NodeProvider, error) {
 	if nodeConfig.IsHybridNode() {
 		logger.Info("Setting up hybrid node provider...")
 		return hybrid.NewHybridNodeProvider(nodeConfig, skipPhases, logger)
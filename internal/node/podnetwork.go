@@ -0,0 +1,418 @@
+package node
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/aws/eks-hybrid/internal/api"
+	"github.com/aws/eks-hybrid/internal/aws/ec2"
+	"github.com/aws/eks-hybrid/internal/aws/eks"
+	"github.com/aws/eks-hybrid/internal/logger"
+	"github.com/aws/eks-hybrid/internal/validation"
+)
+
+// cniConfDir is where the CNI plugins write their network config.
+var cniConfDir = "/etc/cni/net.d"
+
+// PodNetworkValidator validates the pod networking of the node against the
+// cluster's RemoteNetworkConfig.
+type PodNetworkValidator struct {
+	aws     aws.Config
+	kubelet Kubelet
+}
+
+func NewPodNetworkValidator(config aws.Config, kubelet Kubelet) PodNetworkValidator {
+	return PodNetworkValidator{
+		aws:     config,
+		kubelet: kubelet,
+	}
+}
+
+// podNetwork holds the cluster and node networks the validations compare.
+type podNetwork struct {
+	remoteNodeCIDRs []string
+	remotePodCIDRs  []string
+	serviceCIDR     string
+	vpcID           string
+	vpcCIDRs        []string
+	cniSubnets      []cniSubnet
+	nodePodCIDRs    []string
+	nodeIPs         []net.IP
+}
+
+// cniSubnet is a pod CIDR from the IPAM config of a CNI network config file.
+type cniSubnet struct {
+	file   string
+	subnet string
+}
+
+func (s cniSubnet) String() string {
+	return fmt.Sprintf("%s (%s)", s.subnet, s.file)
+}
+
+// Run reads the cluster with DescribeCluster and validates the CNI pod CIDRs,
+// the node pod CIDRs and the node IP against its remote networks.
+func (v PodNetworkValidator) Run(ctx context.Context, informer validation.Informer, node *api.NodeConfig) error {
+	network, err := v.readClusterNetwork(ctx, informer, node)
+	if err != nil {
+		return err
+	}
+	network.vpcCIDRs = v.vpcCIDRs(ctx, network.vpcID)
+	if k8sNode := v.readNode(ctx); k8sNode != nil {
+		network.nodePodCIDRs = nodePodCIDRs(k8sNode)
+		network.nodeIPs = nodeInternalIPs(k8sNode)
+	}
+	if len(network.nodeIPs) == 0 {
+		network.nodeIPs = nodeIPFlags(node.Spec.Kubelet.Flags)
+	}
+
+	var errs []error
+	run := func(name, message string, check func() error) {
+		informer.Starting(ctx, name, message)
+		err := check()
+		informer.Done(ctx, name, err)
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	run("cni-pod-cidrs", "Validating CNI pod CIDRs are in the remote pod networks", func() error {
+		subnets, err := readCNISubnets(cniConfDir)
+		if err != nil {
+			return err
+		}
+		network.cniSubnets = subnets
+		return network.checkCNISubnets()
+	})
+	if len(network.nodePodCIDRs) > 0 {
+		run("node-pod-cidrs", "Validating node pod CIDRs are in the remote pod networks", network.checkNodePodCIDRs)
+	}
+	if len(network.nodeIPs) > 0 {
+		run("node-ip-pod-network", "Validating node IP is outside the pod and service CIDRs", network.checkNodeIPs)
+	}
+	run("remote-network-overlap", "Validating remote networks don't overlap the VPC or service CIDR", network.checkOverlaps)
+
+	return errors.Join(errs...)
+}
+
+func (v PodNetworkValidator) readClusterNetwork(ctx context.Context, informer validation.Informer, node *api.NodeConfig) (podNetwork, error) {
+	name := "remote-network-config"
+	var err error
+	informer.Starting(ctx, name, "Validating cluster remote network config")
+	defer func() {
+		informer.Done(ctx, name, err)
+	}()
+
+	cluster, err := eks.DescribeCluster(ctx, v.aws, node.Spec.Cluster.Name)
+	if err != nil {
+		err = validation.WithRemediation(err, "Ensure the node has access and permissions to call DescribeCluster EKS API.")
+		return podNetwork{}, err
+	}
+	if cluster.RemoteNetworkConfig == nil || len(cluster.RemoteNetworkConfig.RemoteNodeNetworks) == 0 {
+		err = validation.WithRemediation(
+			fmt.Errorf("eks cluster %s has no remote node networks", node.Spec.Cluster.Name),
+			"Configure the remote node and pod networks of the cluster in its RemoteNetworkConfig.",
+		)
+		return podNetwork{}, err
+	}
+
+	network := podNetwork{
+		serviceCIDR: eks.ServiceCIDR(cluster),
+	}
+	for _, nodeNetwork := range cluster.RemoteNetworkConfig.RemoteNodeNetworks {
+		network.remoteNodeCIDRs = append(network.remoteNodeCIDRs, nodeNetwork.Cidrs...)
+	}
+	for _, podNetwork := range cluster.RemoteNetworkConfig.RemotePodNetworks {
+		network.remotePodCIDRs = append(network.remotePodCIDRs, podNetwork.Cidrs...)
+	}
+	if cluster.ResourcesVpcConfig != nil {
+		network.vpcID = aws.ToString(cluster.ResourcesVpcConfig.VpcId)
+	}
+	return network, nil
+}
+
+// vpcCIDRs returns the CIDRs of the cluster VPC. Hybrid node roles don't
+// usually allow DescribeVpcs, so the VPC is skipped when it can't be read.
+func (v PodNetworkValidator) vpcCIDRs(ctx context.Context, vpcID string) []string {
+	if vpcID == "" {
+		return nil
+	}
+	cidrs, err := ec2.VPCCIDRs(ctx, v.aws, vpcID)
+	if err != nil {
+		logger.FromContext(ctx).Info("Can't read the cluster VPC CIDRs, skipping the VPC overlap validation", zap.Error(err))
+		return nil
+	}
+	return cidrs
+}
+
+// readNode returns the node object, or nil when the node isn't registered yet.
+func (v PodNetworkValidator) readNode(ctx context.Context) *corev1.Node {
+	name, err := v.kubelet.NodeName()
+	if err != nil {
+		return nil
+	}
+	client, err := v.kubelet.BuildClient()
+	if err != nil {
+		return nil
+	}
+	node, err := client.CoreV1().Nodes().Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil
+	}
+	return node
+}
+
+func nodePodCIDRs(node *corev1.Node) []string {
+	if len(node.Spec.PodCIDRs) > 0 {
+		return node.Spec.PodCIDRs
+	}
+	if node.Spec.PodCIDR != "" {
+		return []string{node.Spec.PodCIDR}
+	}
+	return nil
+}
+
+func nodeInternalIPs(node *corev1.Node) []net.IP {
+	var ips []net.IP
+	for _, address := range node.Status.Addresses {
+		if address.Type != corev1.NodeInternalIP {
+			continue
+		}
+		if ip := net.ParseIP(address.Address); ip != nil {
+			ips = append(ips, ip)
+		}
+	}
+	return ips
+}
+
+func nodeIPFlags(flags []string) []net.IP {
+	var ips []net.IP
+	for _, flag := range flags {
+		value, ok := strings.CutPrefix(flag, "--node-ip=")
+		if !ok {
+			continue
+		}
+		for _, nodeIP := range strings.Split(value, ",") {
+			if ip := net.ParseIP(strings.TrimSpace(nodeIP)); ip != nil && !ip.IsUnspecified() {
+				ips = append(ips, ip)
+			}
+		}
+	}
+	return ips
+}
+
+// cniConfig is the part of a CNI network config, or config list, with the
+// IPAM pod CIDRs.
+type cniConfig struct {
+	IPAM    *cniIPAM    `json:"ipam,omitempty"`
+	Plugins []cniConfig `json:"plugins,omitempty"`
+}
+
+// cniIPAM holds the pod CIDRs of the host-local and calico-ipam plugins.
+type cniIPAM struct {
+	Subnet string `json:"subnet,omitempty"`
+	Ranges [][]struct {
+		Subnet string `json:"subnet,omitempty"`
+	} `json:"ranges,omitempty"`
+	IPv4Pools []string `json:"ipv4_pools,omitempty"`
+	IPv6Pools []string `json:"ipv6_pools,omitempty"`
+}
+
+func (c cniConfig) subnets() []string {
+	var subnets []string
+	if c.IPAM != nil {
+		candidates := []string{c.IPAM.Subnet}
+		for _, rangeSet := range c.IPAM.Ranges {
+			for _, r := range rangeSet {
+				candidates = append(candidates, r.Subnet)
+			}
+		}
+		candidates = append(candidates, c.IPAM.IPv4Pools...)
+		candidates = append(candidates, c.IPAM.IPv6Pools...)
+		for _, candidate := range candidates {
+			// skip placeholders like usePodCidr and Calico pool names
+			if _, _, err := net.ParseCIDR(candidate); err == nil {
+				subnets = append(subnets, candidate)
+			}
+		}
+	}
+	for _, plugin := range c.Plugins {
+		subnets = append(subnets, plugin.subnets()...)
+	}
+	return subnets
+}
+
+// readCNISubnets returns the IPAM pod CIDRs of the CNI network configs in dir.
+// CNIs with IPAM outside the config, like Cilium, don't have any.
+func readCNISubnets(dir string) ([]cniSubnet, error) {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	var subnets []cniSubnet
+	for _, entry := range entries {
+		switch filepath.Ext(entry.Name()) {
+		case ".conf", ".conflist", ".json":
+		default:
+			continue
+		}
+		path := filepath.Join(dir, entry.Name())
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		var config cniConfig
+		if err := json.Unmarshal(data, &config); err != nil {
+			return nil, fmt.Errorf("parsing CNI config %s: %w", path, err)
+		}
+		for _, subnet := range config.subnets() {
+			subnets = append(subnets, cniSubnet{file: path, subnet: subnet})
+		}
+	}
+	return subnets, nil
+}
+
+// checkCNISubnets is skipped without remote pod networks, they are optional
+// and only needed for webhooks to reach pods on the node.
+func (n podNetwork) checkCNISubnets() error {
+	if len(n.remotePodCIDRs) == 0 {
+		return nil
+	}
+	var outside []string
+	for _, subnet := range n.cniSubnets {
+		if !cidrInAny(subnet.subnet, n.remotePodCIDRs) {
+			outside = append(outside, subnet.String())
+		}
+	}
+	if len(outside) == 0 {
+		return nil
+	}
+	return validation.WithRemediation(
+		fmt.Errorf("CNI pod CIDRs %s are not in the cluster's remote pod networks %s", strings.Join(outside, ", "), n.remotePodCIDRs),
+		"Configure the CNI IPAM with pod CIDRs inside the remote pod networks, or add the pod CIDRs to the remote pod networks in the cluster's RemoteNetworkConfig.",
+	)
+}
+
+// checkNodePodCIDRs is skipped without remote pod networks, like
+// checkCNISubnets.
+func (n podNetwork) checkNodePodCIDRs() error {
+	if len(n.remotePodCIDRs) == 0 {
+		return nil
+	}
+	var outside []string
+	for _, cidr := range n.nodePodCIDRs {
+		if !cidrInAny(cidr, n.remotePodCIDRs) {
+			outside = append(outside, cidr)
+		}
+	}
+	if len(outside) == 0 {
+		return nil
+	}
+	return validation.WithRemediation(
+		fmt.Errorf("node spec.podCIDRs %s are not in the cluster's remote pod networks %s", outside, n.remotePodCIDRs),
+		"The node pod CIDRs are allocated from the cluster CIDR when the node registers. Add them to the remote pod networks in the cluster's RemoteNetworkConfig, or delete the node object for it to register with a new pod CIDR.",
+	)
+}
+
+func (n podNetwork) checkNodeIPs() error {
+	podCIDRs := append(append([]string{}, n.remotePodCIDRs...), n.nodePodCIDRs...)
+	for _, subnet := range n.cniSubnets {
+		podCIDRs = append(podCIDRs, subnet.subnet)
+	}
+
+	var problems []string
+	for _, ip := range n.nodeIPs {
+		for _, cidr := range podCIDRs {
+			if cidrContains(cidr, ip) {
+				problems = append(problems, fmt.Sprintf("node IP %s is in the pod CIDR %s", ip, cidr))
+				break
+			}
+		}
+		if cidrContains(n.serviceCIDR, ip) {
+			problems = append(problems, fmt.Sprintf("node IP %s is in the service CIDR %s", ip, n.serviceCIDR))
+		}
+	}
+	if len(problems) == 0 {
+		return nil
+	}
+	return validation.WithRemediation(
+		errors.New(strings.Join(problems, ", ")),
+		"The node IP must be in the remote node networks, outside the pod and service CIDRs. Set the node IP with spec.kubelet.nodeIPInterface or the --node-ip kubelet flag, or move the pod CIDRs to a range without host addresses.",
+	)
+}
+
+func (n podNetwork) checkOverlaps() error {
+	var problems []string
+	check := func(kind string, cidrs []string) {
+		for _, cidr := range cidrs {
+			for _, vpcCIDR := range n.vpcCIDRs {
+				if cidrsOverlap(cidr, vpcCIDR) {
+					problems = append(problems, fmt.Sprintf("remote %s network %s overlaps the VPC CIDR %s", kind, cidr, vpcCIDR))
+				}
+			}
+			if cidrsOverlap(cidr, n.serviceCIDR) {
+				problems = append(problems, fmt.Sprintf("remote %s network %s overlaps the service CIDR %s", kind, cidr, n.serviceCIDR))
+			}
+		}
+	}
+	check("node", n.remoteNodeCIDRs)
+	check("pod", n.remotePodCIDRs)
+	if len(problems) == 0 {
+		return nil
+	}
+	return validation.WithRemediation(
+		errors.New(strings.Join(problems, ", ")),
+		"The remote node and pod networks must not overlap the cluster VPC CIDRs or the service CIDR. Use separate ranges for the on-premises networks and update the cluster's RemoteNetworkConfig.",
+	)
+}
+
+func cidrContains(cidr string, ip net.IP) bool {
+	_, ipNet, err := net.ParseCIDR(cidr)
+	return err == nil && ipNet.Contains(ip)
+}
+
+// cidrInAny returns true when inner is fully inside one of the CIDRs.
+func cidrInAny(inner string, cidrs []string) bool {
+	_, innerNet, err := net.ParseCIDR(inner)
+	if err != nil {
+		return false
+	}
+	innerOnes, innerBits := innerNet.Mask.Size()
+	for _, cidr := range cidrs {
+		_, outerNet, err := net.ParseCIDR(cidr)
+		if err != nil {
+			continue
+		}
+		outerOnes, outerBits := outerNet.Mask.Size()
+		if innerBits == outerBits && outerOnes <= innerOnes && outerNet.Contains(innerNet.IP) {
+			return true
+		}
+	}
+	return false
+}
+
+func cidrsOverlap(a, b string) bool {
+	_, aNet, err := net.ParseCIDR(a)
+	if err != nil {
+		return false
+	}
+	_, bNet, err := net.ParseCIDR(b)
+	if err != nil {
+		return false
+	}
+	return aNet.Contains(bNet.IP) || bNet.Contains(aNet.IP)
+}
@@ -0,0 +1,163 @@
+package node
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/aws/eks-hybrid/internal/validation"
+)
+
+func TestReadCNISubnets(t *testing.T) {
+	g := NewWithT(t)
+	dir := t.TempDir()
+	files := map[string]string{
+		"10-calico.conflist": `{
+  "name": "k8s-pod-network",
+  "plugins": [
+    {"type": "calico", "ipam": {"type": "calico-ipam", "ipv4_pools": ["10.85.0.0/16", "default-ipv4-ippool"]}},
+    {"type": "portmap"}
+  ]
+}`,
+		"20-bridge.conf":     `{"type": "bridge", "ipam": {"type": "host-local", "ranges": [[{"subnet": "10.86.0.0/24"}], [{"subnet": "usePodCidr"}]]}}`,
+		"05-cilium.conflist": `{"name": "cilium", "plugins": [{"type": "cilium-cni"}]}`,
+		"README":             "not a CNI config",
+	}
+	for name, content := range files {
+		g.Expect(os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644)).To(Succeed())
+	}
+
+	subnets, err := readCNISubnets(dir)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(subnets).To(Equal([]cniSubnet{
+		{file: filepath.Join(dir, "10-calico.conflist"), subnet: "10.85.0.0/16"},
+		{file: filepath.Join(dir, "20-bridge.conf"), subnet: "10.86.0.0/24"},
+	}))
+
+	subnets, err = readCNISubnets(filepath.Join(dir, "missing"))
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(subnets).To(BeEmpty())
+}
+
+func TestPodNetworkChecks(t *testing.T) {
+	valid := podNetwork{
+		remoteNodeCIDRs: []string{"10.80.0.0/16"},
+		remotePodCIDRs:  []string{"10.85.0.0/16"},
+		serviceCIDR:     "172.16.0.0/16",
+		vpcCIDRs:        []string{"10.0.0.0/16"},
+		cniSubnets:      []cniSubnet{{file: "/etc/cni/net.d/10-calico.conflist", subnet: "10.85.0.0/16"}},
+		nodePodCIDRs:    []string{"10.85.1.0/24"},
+		nodeIPs:         []net.IP{net.ParseIP("10.80.0.5")},
+	}
+	testCases := []struct {
+		name            string
+		modify          func(*podNetwork)
+		check           func(podNetwork) error
+		wantErr         string
+		wantRemediation string
+	}{
+		{
+			name:  "cni subnets in remote pod networks",
+			check: podNetwork.checkCNISubnets,
+		},
+		{
+			name: "cni subnet outside remote pod networks",
+			modify: func(n *podNetwork) {
+				n.cniSubnets = []cniSubnet{{file: "/etc/cni/net.d/10-calico.conflist", subnet: "192.168.0.0/16"}}
+			},
+			check:           podNetwork.checkCNISubnets,
+			wantErr:         "CNI pod CIDRs 192.168.0.0/16 (/etc/cni/net.d/10-calico.conflist) are not in the cluster's remote pod networks [10.85.0.0/16]",
+			wantRemediation: "Configure the CNI IPAM with pod CIDRs inside the remote pod networks, or add the pod CIDRs to the remote pod networks in the cluster's RemoteNetworkConfig.",
+		},
+		{
+			name: "cni subnet larger than remote pod networks",
+			modify: func(n *podNetwork) {
+				n.cniSubnets = []cniSubnet{{file: "/etc/cni/net.d/10-calico.conflist", subnet: "10.0.0.0/8"}}
+			},
+			check:   podNetwork.checkCNISubnets,
+			wantErr: "CNI pod CIDRs 10.0.0.0/8 (/etc/cni/net.d/10-calico.conflist) are not in the cluster's remote pod networks [10.85.0.0/16]",
+		},
+		{
+			name: "cni subnets without remote pod networks",
+			modify: func(n *podNetwork) {
+				n.remotePodCIDRs = nil
+			},
+			check: podNetwork.checkCNISubnets,
+		},
+		{
+			name:  "node pod CIDRs in remote pod networks",
+			check: podNetwork.checkNodePodCIDRs,
+		},
+		{
+			name: "node pod CIDRs outside remote pod networks",
+			modify: func(n *podNetwork) {
+				n.nodePodCIDRs = []string{"10.85.1.0/24", "192.168.1.0/24"}
+			},
+			check:   podNetwork.checkNodePodCIDRs,
+			wantErr: "node spec.podCIDRs [192.168.1.0/24] are not in the cluster's remote pod networks [10.85.0.0/16]",
+		},
+		{
+			name: "node pod CIDRs without remote pod networks",
+			modify: func(n *podNetwork) {
+				n.remotePodCIDRs = nil
+			},
+			check: podNetwork.checkNodePodCIDRs,
+		},
+		{
+			name:  "node IP outside pod and service CIDRs",
+			check: podNetwork.checkNodeIPs,
+		},
+		{
+			name: "node IP in pod CIDR",
+			modify: func(n *podNetwork) {
+				n.nodeIPs = []net.IP{net.ParseIP("10.85.3.4")}
+			},
+			check:           podNetwork.checkNodeIPs,
+			wantErr:         "node IP 10.85.3.4 is in the pod CIDR 10.85.0.0/16",
+			wantRemediation: "The node IP must be in the remote node networks, outside the pod and service CIDRs. Set the node IP with spec.kubelet.nodeIPInterface or the --node-ip kubelet flag, or move the pod CIDRs to a range without host addresses.",
+		},
+		{
+			name: "node IP in service CIDR",
+			modify: func(n *podNetwork) {
+				n.nodeIPs = []net.IP{net.ParseIP("172.16.0.10")}
+			},
+			check:   podNetwork.checkNodeIPs,
+			wantErr: "node IP 172.16.0.10 is in the service CIDR 172.16.0.0/16",
+		},
+		{
+			name:  "no overlaps",
+			check: podNetwork.checkOverlaps,
+		},
+		{
+			name: "remote networks overlap VPC and service CIDR",
+			modify: func(n *podNetwork) {
+				n.remoteNodeCIDRs = []string{"10.0.128.0/20"}
+				n.remotePodCIDRs = []string{"172.16.0.0/12"}
+			},
+			check:           podNetwork.checkOverlaps,
+			wantErr:         "remote node network 10.0.128.0/20 overlaps the VPC CIDR 10.0.0.0/16, remote pod network 172.16.0.0/12 overlaps the service CIDR 172.16.0.0/16",
+			wantRemediation: "The remote node and pod networks must not overlap the cluster VPC CIDRs or the service CIDR. Use separate ranges for the on-premises networks and update the cluster's RemoteNetworkConfig.",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			network := valid
+			if tc.modify != nil {
+				tc.modify(&network)
+			}
+			err := tc.check(network)
+			if tc.wantErr == "" {
+				g.Expect(err).NotTo(HaveOccurred())
+				return
+			}
+			g.Expect(err).To(MatchError(tc.wantErr))
+			if tc.wantRemediation != "" {
+				g.Expect(validation.Remediation(err)).To(Equal(tc.wantRemediation))
+			}
+		})
+	}
+}
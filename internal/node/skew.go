@@ -0,0 +1,149 @@
+package node
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"go.uber.org/zap"
+	"golang.org/x/mod/semver"
+
+	"github.com/aws/eks-hybrid/internal/api"
+	"github.com/aws/eks-hybrid/internal/aws/eks"
+	"github.com/aws/eks-hybrid/internal/logger"
+)
+
+// MaxKubeletMinorVersionSkew is the maximum number of minor versions the kubelet
+// can be older than the control plane, following the upstream version skew policy.
+// https://kubernetes.io/releases/version-skew-policy/#kubelet
+const MaxKubeletMinorVersionSkew = 3
+
+// ErrControlPlaneVersionUnknown is returned when there is neither a kubelet
+// kubeconfig nor a cluster and AWS config to query the control plane version from.
+var ErrControlPlaneVersionUnknown = errors.New("control plane version could not be determined")
+
+// ControlPlaneVersion returns the Kubernetes version of the control plane. It queries
+// the API server /version endpoint with the kubelet kubeconfig when it exists and falls
+// back to EKS DescribeCluster with the AWS config of the node provider if cluster and
+// awsConfig are set.
+func ControlPlaneVersion(ctx context.Context, kubelet Kubelet, cluster *api.ClusterDetails, awsConfig *aws.Config) (string, error) {
+	log := logger.FromContext(ctx)
+	canDescribe := cluster != nil && cluster.Name != "" && awsConfig != nil
+
+	if _, err := os.Stat(kubelet.KubeconfigPath()); err == nil {
+		version, err := apiServerVersion(kubelet)
+		if err == nil {
+			return version, nil
+		}
+		if !canDescribe {
+			return "", fmt.Errorf("reading version from the API server: %w", err)
+		}
+		log.Info("Failed to read version from the API server, falling back to EKS DescribeCluster", zap.Error(err))
+	}
+
+	if !canDescribe {
+		return "", ErrControlPlaneVersionUnknown
+	}
+
+	describeConfig := awsConfig.Copy()
+	if describeConfig.Region == "" {
+		// the node provider config isn't enriched with the instance region yet
+		describeConfig.Region = cluster.Region
+	}
+	eksCluster, err := eks.DescribeCluster(ctx, describeConfig, cluster.Name)
+	if err != nil {
+		return "", fmt.Errorf("reading version of eks cluster %s: %w", cluster.Name, err)
+	}
+	if eksCluster.Version == nil {
+		return "", fmt.Errorf("eks cluster %s has no version", cluster.Name)
+	}
+	return *eksCluster.Version, nil
+}
+
+func apiServerVersion(kubelet Kubelet) (string, error) {
+	client, err := kubelet.BuildClient()
+	if err != nil {
+		return "", err
+	}
+	info, err := client.Discovery().ServerVersion()
+	if err != nil {
+		return "", err
+	}
+	return info.GitVersion, nil
+}
+
+// ValidateKubeletVersionSkew validates that installing the target kubelet version
+// follows the kubelet version skew policy: it can't be newer than the control plane,
+// it can be at most MaxKubeletMinorVersionSkew minor versions older and, when
+// currentVersion is set, it can only move forward one minor version at a time and
+// can't be older than currentVersion.
+func ValidateKubeletVersionSkew(controlPlaneVersion, targetVersion, currentVersion string) error {
+	controlPlaneMajor, controlPlaneMinor, err := parseMajorMinor(controlPlaneVersion)
+	if err != nil {
+		return fmt.Errorf("parsing control plane version: %w", err)
+	}
+	targetMajor, targetMinor, err := parseMajorMinor(targetVersion)
+	if err != nil {
+		return fmt.Errorf("parsing kubelet version: %w", err)
+	}
+
+	if targetMajor != controlPlaneMajor {
+		return fmt.Errorf("kubelet version %s and control plane version %s have different major versions", targetVersion, controlPlaneVersion)
+	}
+	if targetMinor > controlPlaneMinor {
+		return fmt.Errorf("kubelet version %s is newer than the control plane version %s", targetVersion, controlPlaneVersion)
+	}
+	if controlPlaneMinor-targetMinor > MaxKubeletMinorVersionSkew {
+		return fmt.Errorf("kubelet version %s is more than %d minor versions older than the control plane version %s",
+			targetVersion, MaxKubeletMinorVersionSkew, controlPlaneVersion)
+	}
+
+	if currentVersion == "" {
+		return nil
+	}
+	currentMajor, currentMinor, err := parseMajorMinor(currentVersion)
+	if err != nil {
+		return fmt.Errorf("parsing installed kubelet version: %w", err)
+	}
+	if currentMajor != targetMajor || targetMinor-currentMinor > 1 {
+		return fmt.Errorf("upgrading kubelet from %s to %s skips minor versions, kubelet must be upgraded one minor version at a time",
+			currentVersion, targetVersion)
+	}
+	if semver.Compare(releaseVersion(targetVersion), releaseVersion(currentVersion)) < 0 {
+		return fmt.Errorf("kubelet version %s is older than the installed version %s, kubelet can't be downgraded", targetVersion, currentVersion)
+	}
+	return nil
+}
+
+// releaseVersion returns the semver of the version without the prerelease and
+// build suffixes, so v1.31.2-eks-1234567 is the same as 1.31.2.
+func releaseVersion(version string) string {
+	v, _, _ := strings.Cut(strings.TrimPrefix(version, "v"), "-")
+	v, _, _ = strings.Cut(v, "+")
+	return "v" + v
+}
+
+// parseMajorMinor parses versions like 1.31, v1.31.2 or v1.31.2-eks-1234567.
+func parseMajorMinor(version string) (int, int, error) {
+	v := version
+	if !strings.HasPrefix(v, "v") {
+		v = "v" + v
+	}
+	if !semver.IsValid(v) {
+		return 0, 0, fmt.Errorf("invalid version %q", version)
+	}
+	major, minor, _ := strings.Cut(strings.TrimPrefix(semver.MajorMinor(v), "v"), ".")
+	majorNumber, err := strconv.Atoi(major)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid major version in %q: %w", version, err)
+	}
+	minorNumber, err := strconv.Atoi(minor)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid minor version in %q: %w", version, err)
+	}
+	return majorNumber, minorNumber, nil
+}
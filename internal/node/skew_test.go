@@ -0,0 +1,129 @@
+package node_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/version"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/aws/eks-hybrid/internal/node"
+)
+
+func TestValidateKubeletVersionSkew(t *testing.T) {
+	testCases := []struct {
+		name                string
+		controlPlaneVersion string
+		targetVersion       string
+		currentVersion      string
+		wantErr             string
+	}{
+		{
+			name:                "same version",
+			controlPlaneVersion: "v1.31.2-eks-7f9249a",
+			targetVersion:       "1.31.2",
+		},
+		{
+			name:                "three minors older",
+			controlPlaneVersion: "1.31",
+			targetVersion:       "1.28.15",
+		},
+		{
+			name:                "newer than control plane",
+			controlPlaneVersion: "1.30",
+			targetVersion:       "1.31.2",
+			wantErr:             "kubelet version 1.31.2 is newer than the control plane version 1.30",
+		},
+		{
+			name:                "four minors older",
+			controlPlaneVersion: "1.31",
+			targetVersion:       "1.27.16",
+			wantErr:             "kubelet version 1.27.16 is more than 3 minor versions older than the control plane version 1.31",
+		},
+		{
+			name:                "one minor upgrade",
+			controlPlaneVersion: "1.31",
+			targetVersion:       "1.31.2",
+			currentVersion:      "v1.30.6",
+		},
+		{
+			name:                "patch upgrade",
+			controlPlaneVersion: "1.31",
+			targetVersion:       "1.30.8",
+			currentVersion:      "v1.30.6",
+		},
+		{
+			name:                "upgrade skipping minors",
+			controlPlaneVersion: "1.31",
+			targetVersion:       "1.31.2",
+			currentVersion:      "v1.29.10",
+			wantErr:             "upgrading kubelet from v1.29.10 to 1.31.2 skips minor versions",
+		},
+		{
+			name:                "same version installed",
+			controlPlaneVersion: "1.31",
+			targetVersion:       "1.31.2",
+			currentVersion:      "v1.31.2-eks-7f9249a",
+		},
+		{
+			name:                "patch downgrade",
+			controlPlaneVersion: "1.31",
+			targetVersion:       "1.31.1",
+			currentVersion:      "v1.31.2",
+			wantErr:             "kubelet version 1.31.1 is older than the installed version v1.31.2, kubelet can't be downgraded",
+		},
+		{
+			name:                "minor downgrade",
+			controlPlaneVersion: "1.31",
+			targetVersion:       "1.30.8",
+			currentVersion:      "v1.31.2",
+			wantErr:             "kubelet version 1.30.8 is older than the installed version v1.31.2, kubelet can't be downgraded",
+		},
+		{
+			name:                "invalid control plane version",
+			controlPlaneVersion: "latest",
+			targetVersion:       "1.31.2",
+			wantErr:             "parsing control plane version",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			err := node.ValidateKubeletVersionSkew(tc.controlPlaneVersion, tc.targetVersion, tc.currentVersion)
+			if tc.wantErr != "" {
+				g.Expect(err).To(MatchError(ContainSubstring(tc.wantErr)))
+			} else {
+				g.Expect(err).NotTo(HaveOccurred())
+			}
+		})
+	}
+}
+
+func TestControlPlaneVersionFromAPIServer(t *testing.T) {
+	g := NewWithT(t)
+	kubeconfig := filepath.Join(t.TempDir(), "kubeconfig")
+	g.Expect(os.WriteFile(kubeconfig, []byte{}, 0o644)).To(Succeed())
+
+	client := fake.NewSimpleClientset()
+	client.Discovery().(*fakediscovery.FakeDiscovery).FakedServerVersion = &version.Info{GitVersion: "v1.31.2-eks-7f9249a"}
+	kubelet := newMockKubelet(client, "v1.31.0")
+	kubelet.kubeconfigPath = kubeconfig
+
+	got, err := node.ControlPlaneVersion(context.Background(), kubelet, nil, nil)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(got).To(Equal("v1.31.2-eks-7f9249a"))
+}
+
+func TestControlPlaneVersionUnknown(t *testing.T) {
+	g := NewWithT(t)
+	kubelet := newMockKubelet(nil, "v1.31.0")
+	kubelet.kubeconfigPath = filepath.Join(t.TempDir(), "kubeconfig")
+
+	_, err := node.ControlPlaneVersion(context.Background(), kubelet, nil, nil)
+	g.Expect(err).To(MatchError(node.ErrControlPlaneVersionUnknown))
+}
@@ -26,6 +26,8 @@ type Kubelet interface {
 	KubeconfigPath() string
 	// Version returns the current kubelet version
 	Version() (string, error)
+	// NodeName returns the name of the node from the kubelet config
+	NodeName() (string, error)
 }
 
 type APIServerValidator struct {
@@ -360,6 +360,7 @@ type mockKubelet struct {
 	versionError   error
 	clientError    error
 	kubeconfigPath string
+	nodeName       string
 }
 
 func newMockKubelet(client kubernetes.Interface, version string) *mockKubelet {
@@ -381,6 +382,13 @@ func (m *mockKubelet) KubeconfigPath() string {
 	return m.kubeconfigPath
 }
 
+func (m *mockKubelet) NodeName() (string, error) {
+	if m.nodeName == "" {
+		return "", errors.New("no node name")
+	}
+	return m.nodeName, nil
+}
+
 func (m *mockKubelet) Version() (string, error) {
 	if m.versionError != nil {
 		return "", m.versionError
@@ -47,3 +47,25 @@ type NodeProvider interface {
 	configenricher.ConfigEnricher
 	aws.Config
 }
+
+// PreProcessDaemonsGetter is implemented by node providers whose AWS
+// credentials are kept up by daemons, so an inited node can reconfigure them
+// without setting up its credentials again.
+type PreProcessDaemonsGetter interface {
+	// GetPreProcessDaemons returns the daemons set up by ConfigureAws and
+	// PreProcessDaemon. Configuring them doesn't register the node again.
+	GetPreProcessDaemons() ([]daemon.Daemon, error)
+}
+
+// NodeIdentityChecker is implemented by node providers whose nodes pick their
+// name, so a node doesn't take over the Node object of another machine.
+type NodeIdentityChecker interface {
+	// CheckNodeIdentity fails when the Node with the name of this node belongs
+	// to another machine, unless force is set. Requires the daemons to be
+	// configured and runs before they start.
+	CheckNodeIdentity(ctx context.Context, force bool) error
+
+	// RecordNodeIdentity annotates the Node with the identity of this machine
+	// once kubelet registers it.
+	RecordNodeIdentity(ctx context.Context) error
+}
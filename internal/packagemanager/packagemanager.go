@@ -41,6 +41,7 @@ const (
 	containerdDistroPkgName = "containerd"
 	containerdDockerPkgName = "containerd.io"
 	runcPkgName             = "runc"
+	crioPkgName             = "cri-o"
 
 	caCertsPkgName  = "ca-certificates"
 	iptablesPkgName = "iptables"
@@ -240,6 +241,16 @@ func (pm *DistroPackageManager) GetContainerd(version string) artifact.Package {
 	)
 }
 
+// GetCrio gets the CRI-O package from the repos configured on the host
+// Satisfies the cri-o source interface
+func (pm *DistroPackageManager) GetCrio() artifact.Package {
+	return artifact.NewPackageSource(
+		artifact.NewCmd(pm.manager, pm.installVerb, crioPkgName, "-y"),
+		artifact.NewCmd(pm.manager, pm.deleteVerb, crioPkgName, "-y"),
+		artifact.NewCmd(pm.manager, pm.updateVerb, crioPkgName, "-y"),
+	)
+}
+
 // GetIptables satisfies the getiptables source interface
 func (pm *DistroPackageManager) GetIptables() artifact.Package {
 	return artifact.NewPackageSource(
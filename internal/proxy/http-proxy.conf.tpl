@@ -0,0 +1,4 @@
+[Service]
+{{- range . }}
+Environment="{{ . }}"
+{{- end }}
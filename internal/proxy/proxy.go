@@ -0,0 +1,190 @@
+package proxy
+
+import (
+	"bytes"
+	_ "embed"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"text/template"
+
+	"golang.org/x/net/http/httpproxy"
+
+	"github.com/aws/eks-hybrid/internal/api"
+)
+
+const (
+	systemdUnitDir = "/etc/systemd/system"
+	dropInFileName = "http-proxy.conf"
+	dropInPerm     = 0o644
+)
+
+var (
+	//go:embed http-proxy.conf.tpl
+	rawDropInTemplate string
+	dropInTemplate    = template.Must(template.New(dropInFileName).Parse(rawDropInTemplate))
+
+	// systemdEscaper escapes values inside a double quoted systemd Environment= assignment,
+	// including specifiers so that URL encoded credentials are preserved.
+	systemdEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, `%`, `%%`)
+)
+
+// Validate checks that the configured proxies are valid http or https URLs.
+func Validate(cfg *api.NodeConfig) error {
+	proxies := []struct{ field, proxy string }{
+		{"httpProxy", cfg.Spec.Proxy.HTTPProxy},
+		{"httpsProxy", cfg.Spec.Proxy.HTTPSProxy},
+	}
+	for _, p := range proxies {
+		field, proxy := p.field, p.proxy
+		if proxy == "" {
+			continue
+		}
+		proxyURL, err := url.Parse(proxy)
+		if err != nil {
+			return fmt.Errorf("invalid proxy %s %q: %w", field, proxy, err)
+		}
+		if (proxyURL.Scheme != "http" && proxyURL.Scheme != "https") || proxyURL.Host == "" {
+			return fmt.Errorf("invalid proxy %s %q: must be an http or https URL", field, proxy)
+		}
+	}
+	return nil
+}
+
+// NoProxy returns the destinations that must be reached without the proxy: the
+// loopback addresses, the cluster's API server host, service CIDR and remote pod
+// networks, followed by the user-provided entries.
+func NoProxy(cfg *api.NodeConfig) []string {
+	entries := []string{"localhost", "127.0.0.1"}
+	if endpoint, err := url.Parse(cfg.Spec.Cluster.APIServerEndpoint); err == nil && endpoint.Hostname() != "" {
+		entries = append(entries, endpoint.Hostname())
+	}
+	if cfg.Spec.Cluster.CIDR != "" {
+		entries = append(entries, cfg.Spec.Cluster.CIDR)
+	}
+	entries = append(entries, cfg.Status.Hybrid.RemotePodNetworks...)
+	entries = append(entries, cfg.Spec.Proxy.NoProxy...)
+
+	seen := make(map[string]bool, len(entries))
+	noProxy := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if entry == "" || seen[entry] {
+			continue
+		}
+		seen[entry] = true
+		noProxy = append(noProxy, entry)
+	}
+	return noProxy
+}
+
+// Environment returns the proxy environment variables, as KEY=VALUE pairs, for the
+// node's proxy configuration. Both upper and lower case variables are set since tools
+// disagree on which one they read. It returns nil if no proxy is configured.
+func Environment(cfg *api.NodeConfig) []string {
+	if !cfg.IsProxyEnabled() {
+		return nil
+	}
+	var env []string
+	for name, value := range map[string]string{
+		"HTTP_PROXY":  cfg.Spec.Proxy.HTTPProxy,
+		"HTTPS_PROXY": cfg.Spec.Proxy.HTTPSProxy,
+		"NO_PROXY":    strings.Join(NoProxy(cfg), ","),
+	} {
+		if value == "" {
+			continue
+		}
+		env = append(env, name+"="+value, strings.ToLower(name)+"="+value)
+	}
+	// keep the rendered drop-ins stable across runs so that changes can be detected
+	sort.Strings(env)
+	return env
+}
+
+// Configure makes nodeadm, and any process it executes, use the node's proxy.
+// It must be called before nodeadm makes any request since the AWS SDK reads
+// the proxy environment only once.
+func Configure(cfg *api.NodeConfig) error {
+	if !cfg.IsProxyEnabled() {
+		return nil
+	}
+	if err := Validate(cfg); err != nil {
+		return err
+	}
+	for _, env := range Environment(cfg) {
+		name, value, _ := strings.Cut(env, "=")
+		if err := os.Setenv(name, value); err != nil {
+			return fmt.Errorf("setting %s: %w", name, err)
+		}
+	}
+	if transport, ok := http.DefaultTransport.(*http.Transport); ok {
+		proxyFunc := (&httpproxy.Config{
+			HTTPProxy:  cfg.Spec.Proxy.HTTPProxy,
+			HTTPSProxy: cfg.Spec.Proxy.HTTPSProxy,
+			NoProxy:    strings.Join(NoProxy(cfg), ","),
+		}).ProxyFunc()
+		transport.Proxy = func(req *http.Request) (*url.URL, error) {
+			return proxyFunc(req.URL)
+		}
+	}
+	return nil
+}
+
+// DropInPath returns the path of the systemd drop-in that sets the proxy for unit.
+func DropInPath(unit string) string {
+	return path.Join(systemdUnitDir, unit+".service.d", dropInFileName)
+}
+
+// DropIn renders a systemd drop-in that sets the proxy environment variables
+// for a service.
+func DropIn(cfg *api.NodeConfig) ([]byte, error) {
+	env := Environment(cfg)
+	for i := range env {
+		env[i] = systemdEscaper.Replace(env[i])
+	}
+	var buf bytes.Buffer
+	if err := dropInTemplate.Execute(&buf, env); err != nil {
+		return nil, fmt.Errorf("executing proxy drop-in template: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// RenderDropIn returns the content WriteDropIn writes to the drop-in, nil if
+// it removes the drop-in.
+func RenderDropIn(cfg *api.NodeConfig) ([]byte, error) {
+	if !cfg.IsProxyEnabled() {
+		return nil, nil
+	}
+	return DropIn(cfg)
+}
+
+// WriteDropIn writes the proxy drop-in for unit. If no proxy is configured, any
+// existing drop-in is removed instead. The caller is responsible for reloading systemd.
+func WriteDropIn(unit string, cfg *api.NodeConfig) error {
+	if !cfg.IsProxyEnabled() {
+		return RemoveDropIn(unit)
+	}
+	dropIn, err := DropIn(cfg)
+	if err != nil {
+		return err
+	}
+	dropInPath := DropInPath(unit)
+	if err := os.MkdirAll(path.Dir(dropInPath), 0o755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(dropInPath, dropIn, dropInPerm); err != nil {
+		return fmt.Errorf("writing proxy drop-in for %s: %w", unit, err)
+	}
+	return nil
+}
+
+// RemoveDropIn removes the proxy drop-in for unit, if present.
+func RemoveDropIn(unit string) error {
+	if err := os.Remove(DropInPath(unit)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("removing proxy drop-in for %s: %w", unit, err)
+	}
+	return nil
+}
@@ -0,0 +1,141 @@
+package selfupdate
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"go.uber.org/zap"
+	"golang.org/x/mod/semver"
+
+	"github.com/aws/eks-hybrid/internal/artifact"
+)
+
+// signingKey is the base64 encoded armored PGP public key used to verify nodeadm
+// releases. Set at build time.
+var signingKey string
+
+// ErrNoSigningKey is returned when nodeadm was built without a signing key and
+// the signature of a release can't be verified.
+var ErrNoSigningKey = errors.New("this nodeadm build has no signing key to verify releases")
+
+// Source represents a source that serves a nodeadm binary and its signature.
+type Source interface {
+	GetNodeadm(context.Context) (artifact.Source, error)
+	GetNodeadmSignature(context.Context) (io.ReadCloser, error)
+}
+
+// PublicKey returns the armored PGP public key used to verify nodeadm releases.
+func PublicKey() (string, error) {
+	if signingKey == "" {
+		return "", ErrNoSigningKey
+	}
+	key, err := base64.StdEncoding.DecodeString(signingKey)
+	if err != nil {
+		return "", fmt.Errorf("decoding signing key: %w", err)
+	}
+	return string(key), nil
+}
+
+// IsUpdateAvailable returns true if releaseVersion should replace currentVersion.
+// A pinned release is always installed unless it's the current version, which
+// allows downgrades. Otherwise, only newer releases are installed.
+func IsUpdateAvailable(currentVersion, releaseVersion string, pinned bool) bool {
+	current, release := artifact.NormalizeVersion(currentVersion), artifact.NormalizeVersion(releaseVersion)
+	if pinned {
+		return current != release
+	}
+	// development builds don't follow semver, always consider them outdated
+	if !semver.IsValid(current) {
+		return true
+	}
+	return semver.Compare(current, release) < 0
+}
+
+// ReplaceOptions contains options to replace the nodeadm executable.
+type ReplaceOptions struct {
+	Source Source
+	// ExecutablePath is the path to the nodeadm executable to replace.
+	ExecutablePath string
+	// PublicKey is the armored PGP public key to verify the release signature.
+	PublicKey string
+	Logger    *zap.Logger
+}
+
+// Replace downloads nodeadm from the source, verifies its checksum and signature and
+// atomically replaces the executable. The new binary is staged next to the executable
+// so the final rename doesn't cross filesystems.
+func Replace(ctx context.Context, opts ReplaceOptions) error {
+	info, err := os.Stat(opts.ExecutablePath)
+	if err != nil {
+		return fmt.Errorf("reading nodeadm executable: %w", err)
+	}
+
+	staged, err := os.CreateTemp(filepath.Dir(opts.ExecutablePath), ".nodeadm-update-*")
+	if err != nil {
+		return fmt.Errorf("creating staging file: %w", err)
+	}
+	// no-op once the staged file has been renamed
+	defer os.Remove(staged.Name())
+	defer staged.Close()
+
+	opts.Logger.Info("Downloading nodeadm...")
+	if err := download(ctx, opts.Source, staged); err != nil {
+		return err
+	}
+
+	opts.Logger.Info("Verifying nodeadm signature...")
+	if err := verifySignature(ctx, opts.Source, staged, opts.PublicKey); err != nil {
+		return err
+	}
+
+	if err := staged.Chmod(info.Mode().Perm()); err != nil {
+		return err
+	}
+	if err := staged.Sync(); err != nil {
+		return err
+	}
+	if err := staged.Close(); err != nil {
+		return err
+	}
+
+	opts.Logger.Info("Replacing nodeadm executable", zap.String("path", opts.ExecutablePath))
+	return os.Rename(staged.Name(), opts.ExecutablePath)
+}
+
+func download(ctx context.Context, source Source, dst io.Writer) error {
+	nodeadm, err := source.GetNodeadm(ctx)
+	if err != nil {
+		return fmt.Errorf("getting nodeadm source: %w", err)
+	}
+	defer nodeadm.Close()
+
+	if _, err := io.Copy(dst, nodeadm); err != nil {
+		return fmt.Errorf("downloading nodeadm: %w", err)
+	}
+
+	if !nodeadm.VerifyChecksum() {
+		return fmt.Errorf("nodeadm checksum mismatch: %v", artifact.NewChecksumError(nodeadm))
+	}
+	return nil
+}
+
+func verifySignature(ctx context.Context, source Source, staged *os.File, publicKey string) error {
+	signature, err := source.GetNodeadmSignature(ctx)
+	if err != nil {
+		return fmt.Errorf("getting nodeadm signature: %w", err)
+	}
+	defer signature.Close()
+
+	if _, err := staged.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	if err := artifact.VerifySignature(staged, signature, publicKey); err != nil {
+		return fmt.Errorf("validating nodeadm signature: %w", err)
+	}
+	return nil
+}
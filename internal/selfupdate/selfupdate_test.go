@@ -0,0 +1,158 @@
+package selfupdate_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ProtonMail/gopenpgp/v3/crypto"
+	. "github.com/onsi/gomega"
+	"go.uber.org/zap"
+
+	"github.com/aws/eks-hybrid/internal/artifact"
+	"github.com/aws/eks-hybrid/internal/selfupdate"
+)
+
+type fakeSource struct {
+	binary    []byte
+	checksum  []byte
+	signature []byte
+}
+
+func (f fakeSource) GetNodeadm(context.Context) (artifact.Source, error) {
+	return artifact.WithChecksum(io.NopCloser(bytes.NewReader(f.binary)), sha256.New(), f.checksum)
+}
+
+func (f fakeSource) GetNodeadmSignature(context.Context) (io.ReadCloser, error) {
+	return io.NopCloser(bytes.NewReader(f.signature)), nil
+}
+
+func checksum(data []byte) []byte {
+	return []byte(fmt.Sprintf("%x  nodeadm", sha256.Sum256(data)))
+}
+
+func generateKeyPair(t *testing.T) (string, *crypto.Key) {
+	g := NewGomegaWithT(t)
+
+	key, err := crypto.PGP().KeyGeneration().
+		AddUserId("test", "test@example.com").
+		New().GenerateKey()
+	g.Expect(err).NotTo(HaveOccurred())
+
+	armoredPublicKey, err := key.GetArmoredPublicKey()
+	g.Expect(err).NotTo(HaveOccurred())
+
+	return armoredPublicKey, key
+}
+
+func generateSignature(t *testing.T, key *crypto.Key, data []byte) []byte {
+	g := NewGomegaWithT(t)
+
+	signer, err := crypto.PGP().Sign().SigningKey(key).Detached().New()
+	g.Expect(err).NotTo(HaveOccurred())
+
+	signature, err := signer.Sign(data, crypto.Bytes)
+	g.Expect(err).NotTo(HaveOccurred())
+	return signature
+}
+
+func TestReplace(t *testing.T) {
+	publicKey, privateKey := generateKeyPair(t)
+	newBinary := []byte("new nodeadm binary")
+
+	testCases := []struct {
+		name    string
+		source  fakeSource
+		wantErr string
+	}{
+		{
+			name: "valid release",
+			source: fakeSource{
+				binary:    newBinary,
+				checksum:  checksum(newBinary),
+				signature: generateSignature(t, privateKey, newBinary),
+			},
+		},
+		{
+			name: "checksum mismatch",
+			source: fakeSource{
+				binary:    newBinary,
+				checksum:  checksum([]byte("other binary")),
+				signature: generateSignature(t, privateKey, newBinary),
+			},
+			wantErr: "nodeadm checksum mismatch",
+		},
+		{
+			name: "invalid signature",
+			source: fakeSource{
+				binary:    newBinary,
+				checksum:  checksum(newBinary),
+				signature: generateSignature(t, privateKey, []byte("other binary")),
+			},
+			wantErr: "validating nodeadm signature",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			dir := t.TempDir()
+			executable := filepath.Join(dir, "nodeadm")
+			g.Expect(os.WriteFile(executable, []byte("old nodeadm binary"), 0o755)).To(Succeed())
+
+			err := selfupdate.Replace(context.Background(), selfupdate.ReplaceOptions{
+				Source:         tc.source,
+				ExecutablePath: executable,
+				PublicKey:      publicKey,
+				Logger:         zap.NewNop(),
+			})
+
+			content, readErr := os.ReadFile(executable)
+			g.Expect(readErr).NotTo(HaveOccurred())
+			if tc.wantErr != "" {
+				g.Expect(err).To(MatchError(ContainSubstring(tc.wantErr)))
+				g.Expect(string(content)).To(Equal("old nodeadm binary"))
+			} else {
+				g.Expect(err).NotTo(HaveOccurred())
+				g.Expect(content).To(Equal(newBinary))
+				info, statErr := os.Stat(executable)
+				g.Expect(statErr).NotTo(HaveOccurred())
+				g.Expect(info.Mode().Perm()).To(Equal(os.FileMode(0o755)))
+			}
+
+			// the staged file is always cleaned up
+			entries, readDirErr := os.ReadDir(dir)
+			g.Expect(readDirErr).NotTo(HaveOccurred())
+			g.Expect(entries).To(HaveLen(1))
+		})
+	}
+}
+
+func TestIsUpdateAvailable(t *testing.T) {
+	testCases := []struct {
+		name           string
+		currentVersion string
+		releaseVersion string
+		pinned         bool
+		want           bool
+	}{
+		{name: "newer release", currentVersion: "v1.0.5", releaseVersion: "v1.0.6", want: true},
+		{name: "same release", currentVersion: "v1.0.6", releaseVersion: "1.0.6", want: false},
+		{name: "older release", currentVersion: "v1.0.6", releaseVersion: "v1.0.5", want: false},
+		{name: "pinned older release", currentVersion: "v1.0.6", releaseVersion: "v1.0.5", pinned: true, want: true},
+		{name: "pinned same release", currentVersion: "v1.0.6", releaseVersion: "v1.0.6", pinned: true, want: false},
+		{name: "development build", currentVersion: "", releaseVersion: "v1.0.6", want: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			g.Expect(selfupdate.IsUpdateAvailable(tc.currentVersion, tc.releaseVersion, tc.pinned)).To(Equal(tc.want))
+		})
+	}
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/aws/aws-sdk-go-v2/feature/ec2/imds"
 
 	"github.com/aws/eks-hybrid/internal/api"
+	"github.com/aws/eks-hybrid/internal/trust"
 	"github.com/aws/eks-hybrid/internal/util/file"
 )
 
@@ -27,6 +28,7 @@ func WaitForAWSConfig(ctx context.Context, nodeConfig *api.NodeConfig, backoff t
 	}
 
 	return config.LoadDefaultConfig(ctx,
+		trust.WithRootCAs,
 		config.WithRegion(nodeConfig.Spec.Cluster.Region),
 		config.WithSharedCredentialsFiles([]string{credsFile}),
 		// important to pass empty slice instead of nil to stop
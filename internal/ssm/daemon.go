@@ -11,12 +11,15 @@ import (
 
 	"github.com/aws/eks-hybrid/internal/api"
 	"github.com/aws/eks-hybrid/internal/daemon"
+	"github.com/aws/eks-hybrid/internal/proxy"
 	"github.com/aws/eks-hybrid/internal/system"
 )
 
 var (
-	_             daemon.Daemon = &ssm{}
-	SsmDaemonName               = "amazon-ssm-agent"
+	_             daemon.Daemon            = &ssm{}
+	_             daemon.ConfigFilesLister = &ssm{}
+	_             daemon.ConfigRenderer    = &ssm{}
+	SsmDaemonName                          = "amazon-ssm-agent"
 
 	activationExpiredRegex = regexp.MustCompile(`.*ActivationExpired*`)
 	invalidActivationRegex = regexp.MustCompile(`.*InvalidActivation*`)
@@ -33,6 +36,8 @@ type ssm struct {
 	daemonManager daemon.DaemonManager
 	nodeConfig    *api.NodeConfig
 	logger        *zap.Logger
+	// registered skips the machine registration in Configure
+	registered bool
 }
 
 func NewSsmDaemon(daemonManager daemon.DaemonManager, cfg *api.NodeConfig, logger *zap.Logger) daemon.Daemon {
@@ -44,7 +49,25 @@ func NewSsmDaemon(daemonManager daemon.DaemonManager, cfg *api.NodeConfig, logge
 	}
 }
 
+// NewRegisteredSsmDaemon returns the SSM daemon of a node that is already
+// registered, whose Configure only writes the agent configuration.
+func NewRegisteredSsmDaemon(daemonManager daemon.DaemonManager, cfg *api.NodeConfig, logger *zap.Logger) daemon.Daemon {
+	setDaemonName()
+	return &ssm{
+		daemonManager: daemonManager,
+		nodeConfig:    cfg,
+		logger:        logger,
+		registered:    true,
+	}
+}
+
 func (s *ssm) Configure() error {
+	if err := proxy.WriteDropIn(SsmDaemonName, s.nodeConfig); err != nil {
+		return err
+	}
+	if s.registered {
+		return nil
+	}
 	if err := s.registerMachine(s.nodeConfig); err != nil {
 		if match := activationExpiredRegex.MatchString(err.Error()); match {
 			return fmt.Errorf("SSM activation expired. Please use a valid activation")
@@ -56,7 +79,22 @@ func (s *ssm) Configure() error {
 	return nil
 }
 
+func (s *ssm) RenderConfig() (map[string][]byte, error) {
+	dropIn, err := proxy.RenderDropIn(s.nodeConfig)
+	if err != nil {
+		return nil, err
+	}
+	return map[string][]byte{proxy.DropInPath(SsmDaemonName): dropIn}, nil
+}
+
+func (s *ssm) ConfigFiles() []string {
+	return []string{proxy.DropInPath(SsmDaemonName)}
+}
+
 func (s *ssm) EnsureRunning(ctx context.Context) error {
+	if err := s.daemonManager.DaemonReload(); err != nil {
+		return err
+	}
 	err := s.daemonManager.EnableDaemon(SsmDaemonName)
 	if err != nil {
 		return err
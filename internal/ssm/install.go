@@ -11,11 +11,11 @@ import (
 	"path/filepath"
 	"time"
 
-	"github.com/ProtonMail/gopenpgp/v3/crypto"
 	"github.com/pkg/errors"
 	"go.uber.org/zap"
 
 	"github.com/aws/eks-hybrid/internal/artifact"
+	"github.com/aws/eks-hybrid/internal/proxy"
 	"github.com/aws/eks-hybrid/internal/tracker"
 	"github.com/aws/eks-hybrid/internal/util"
 	"github.com/aws/eks-hybrid/internal/util/cmd"
@@ -116,7 +116,7 @@ func downloadFileTo(ctx context.Context, source Source, installerPath string) er
 	var installerBuffer bytes.Buffer
 	installerTee := io.TeeReader(installer, &installerBuffer)
 
-	if err := validateSetupSignature(installerTee, signature, source.PublicKey()); err != nil {
+	if err := artifact.VerifySignature(installerTee, signature, source.PublicKey()); err != nil {
 		return fmt.Errorf("validating ssm-setup-cli signature: %w", err)
 	}
 
@@ -127,31 +127,6 @@ func downloadFileTo(ctx context.Context, source Source, installerPath string) er
 	return nil
 }
 
-func validateSetupSignature(installer, signature io.Reader, publicKey string) error {
-	verificationKey, err := crypto.NewKeyFromArmored(publicKey)
-	if err != nil {
-		return err
-	}
-
-	pgp := crypto.PGP()
-	verifier, _ := pgp.Verify().
-		VerificationKey(verificationKey).
-		New()
-
-	verifyDataReader, err := verifier.VerifyingReader(installer, signature, crypto.Bytes)
-	if err != nil {
-		return err
-	}
-	verifyResult, err := verifyDataReader.ReadAllAndVerifySignature()
-	if err != nil {
-		return err
-	}
-	if err := verifyResult.SignatureError(); err != nil {
-		return err
-	}
-	return nil
-}
-
 type UninstallOptions struct {
 	Logger *zap.Logger
 	// InstallRoot is optionally the root directory of the installation
@@ -180,6 +155,9 @@ func Uninstall(ctx context.Context, opts UninstallOptions) error {
 		func() error {
 			return removeFileOrDir(filepath.Join(opts.InstallRoot, configRoot), "uninstalling ssm config files")
 		},
+		func() error {
+			return removeFileOrDir(filepath.Join(opts.InstallRoot, proxy.DropInPath(SsmDaemonName)), "uninstalling ssm proxy drop-in")
+		},
 		func() error {
 			return removeFileOrDir(filepath.Join(opts.InstallRoot, symlinkedAWSConfigPath), "uninstalling ssm aws config symlink")
 		},
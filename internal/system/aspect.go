@@ -1,6 +1,32 @@
 package system
 
+import (
+	"go.uber.org/zap"
+
+	"github.com/aws/eks-hybrid/internal/api"
+)
+
 type SystemAspect interface {
 	Name() string
 	Setup() error
+	// Teardown reverts what Setup changed on the host. It only relies on the
+	// state Setup saved, since the node config is not available on uninstall.
+	Teardown() error
+}
+
+// AllAspects returns every aspect, for uninstall to tear them down. The
+// aspects are built without a node config, so they can't be set up.
+func AllAspects(logger *zap.Logger) []SystemAspect {
+	cfg := &api.NodeConfig{}
+	return []SystemAspect{
+		NewTrustAspect(cfg, logger),
+		NewSysctlAspect(cfg),
+		NewSwapAspect(cfg, logger),
+		NewIptablesAspect(cfg, logger),
+		NewPortsAspect(cfg, logger),
+		NewLocalDiskAspect(cfg, logger),
+		NewDataDirsAspect(cfg, logger),
+		NewNetworkingAspect(cfg),
+		NewCNIPrerequisitesAspect(cfg, logger),
+	}
 }
@@ -0,0 +1,107 @@
+package system
+
+import (
+	"fmt"
+
+	"github.com/aws/eks-hybrid/internal/api"
+	"github.com/aws/eks-hybrid/internal/firewall"
+)
+
+const (
+	ciliumVXLANPort  = "8472"
+	ciliumHealthPort = "4240"
+	hubblePort       = "4244"
+	calicoTyphaPort  = "5473"
+	// vxlanPort is the IANA VXLAN port, used by Calico
+	vxlanPort  = "4789"
+	genevePort = "6081"
+	bgpPort    = "179"
+)
+
+// cniPorts returns the ports the CNI plugin needs open on the node.
+func cniPorts(cni api.CNIOptions) []nodePort {
+	if cni.Plugin == "" {
+		return nil
+	}
+	var ports []nodePort
+	switch cni.Plugin {
+	case api.CNIPluginCilium:
+		ports = append(ports,
+			nodePort{name: "cilium-health", start: ciliumHealthPort, protocol: protocolTCP},
+			nodePort{name: "hubble", start: hubblePort, protocol: protocolTCP},
+		)
+	case api.CNIPluginCalico:
+		ports = append(ports, nodePort{name: "calico-typha", start: calicoTyphaPort, protocol: protocolTCP})
+	}
+	switch cni.Encapsulation {
+	case "", api.CNIEncapsulationVXLAN:
+		port := vxlanPort
+		if cni.Plugin == api.CNIPluginCilium {
+			port = ciliumVXLANPort
+		}
+		ports = append(ports, nodePort{name: "vxlan", start: port, protocol: protocolUDP})
+	case api.CNIEncapsulationGeneve:
+		ports = append(ports, nodePort{name: "geneve", start: genevePort, protocol: protocolUDP})
+	}
+	if cni.BGP {
+		ports = append(ports, nodePort{name: "bgp", start: bgpPort, protocol: protocolTCP})
+	}
+	return ports
+}
+
+// ValidateCNI checks the CNI options of the node config.
+func ValidateCNI(cfg *api.NodeConfig) error {
+	cni := cfg.Spec.CNI
+	if cni.Plugin == "" {
+		if cni.Encapsulation != "" || cni.BGP {
+			return fmt.Errorf("spec.cni.plugin is required when spec.cni.encapsulation or spec.cni.bgp are set")
+		}
+		return nil
+	}
+	switch cni.Plugin {
+	case api.CNIPluginCilium, api.CNIPluginCalico, api.CNIPluginOther:
+	default:
+		return fmt.Errorf("unknown CNI plugin %q in spec.cni.plugin", cni.Plugin)
+	}
+	switch cni.Encapsulation {
+	case "", api.CNIEncapsulationVXLAN, api.CNIEncapsulationNone:
+	case api.CNIEncapsulationGeneve:
+		if cni.Plugin == api.CNIPluginCalico {
+			return fmt.Errorf("calico doesn't support the geneve encapsulation in spec.cni.encapsulation")
+		}
+	default:
+		return fmt.Errorf("unknown encapsulation %q in spec.cni.encapsulation", cni.Encapsulation)
+	}
+	return nil
+}
+
+// ValidateCNIPorts checks the host firewall lets the CNI traffic in. Without
+// spec.cni.plugin, either the Cilium or the Calico VXLAN port must be open.
+// Otherwise the ports aspect opens the CNI ports, there is nothing to check.
+func ValidateCNIPorts(cfg *api.NodeConfig, firewallManager firewall.Manager) error {
+	if cfg.Spec.CNI.Plugin != "" {
+		return nil
+	}
+	enabled, err := firewallManager.IsEnabled()
+	if err != nil {
+		return err
+	}
+	if !enabled {
+		return nil
+	}
+	if err := firewallManager.FlushRules(); err != nil {
+		return err
+	}
+	ciliumVXLANPortOpen, err := firewallManager.IsPortOpen(ciliumVXLANPort, protocolUDP)
+	if err != nil {
+		return err
+	}
+	calicoVXLANPortOpen, err := firewallManager.IsPortOpen(vxlanPort, protocolUDP)
+	if err != nil {
+		return err
+	}
+	if !ciliumVXLANPortOpen && !calicoVXLANPortOpen {
+		return fmt.Errorf("Cilium (%s/%s) or Calico (%s/%s) VxLan ports are not open on the host", ciliumVXLANPort, protocolUDP, vxlanPort, protocolUDP)
+	}
+	return nil
+}
@@ -0,0 +1,559 @@
+package system
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"github.com/aws/eks-hybrid/internal/api"
+	"github.com/aws/eks-hybrid/internal/validation"
+)
+
+const (
+	cniPrerequisitesAspectName = "cni-prerequisites"
+
+	bpfMountPoint    = "/sys/fs/bpf"
+	bpfMountUnit     = "sys-fs-bpf.mount"
+	cgroupMountPoint = "/sys/fs/cgroup"
+
+	// networkManagerConfFile is the NetworkManager config nodeadm writes to
+	// keep NetworkManager away from the CNI interfaces.
+	networkManagerConfFile = "99-nodeadm-cni.conf"
+
+	cniConfigFilePerms = 0o644
+)
+
+var (
+	mountInfoPath     = "/proc/self/mountinfo"
+	sysModuleDir      = "/sys/module"
+	systemdUnitDir    = "/etc/systemd/system"
+	networkManagerDir = "/etc/NetworkManager"
+
+	bpfMountUnitData = fmt.Sprintf(`[Unit]
+Description=BPF filesystem for Cilium
+DefaultDependencies=no
+Before=local-fs.target umount.target
+After=swap.target
+
+[Mount]
+What=bpffs
+Where=%s
+Type=bpf
+Options=rw,nosuid,nodev,noexec,relatime,mode=700
+
+[Install]
+WantedBy=multi-user.target
+`, bpfMountPoint)
+
+	// ciliumMinKernelVersion is the oldest kernel Cilium supports, apart from
+	// the RHEL 8 kernels that backport the BPF features it needs.
+	ciliumMinKernelVersion = [2]int{5, 4}
+
+	// ciliumKernelConfigs are the kernel configs Cilium requires, built in or
+	// as modules.
+	ciliumKernelConfigs = []string{
+		"CONFIG_BPF",
+		"CONFIG_BPF_SYSCALL",
+		"CONFIG_BPF_JIT",
+		"CONFIG_NET_CLS_BPF",
+		"CONFIG_NET_CLS_ACT",
+		"CONFIG_NET_SCH_INGRESS",
+		"CONFIG_CRYPTO_SHA1",
+		"CONFIG_CRYPTO_USER_API_HASH",
+		"CONFIG_CGROUPS",
+		"CONFIG_CGROUP_BPF",
+		"CONFIG_PERF_EVENTS",
+		"CONFIG_SCHEDSTATS",
+	}
+
+	calicoKernelModules = []string{"ip_set", "xt_set"}
+
+	// cniUnmanagedInterfaces are the NetworkManager device specs of the
+	// interfaces the CNI plugins create.
+	cniUnmanagedInterfaces = map[api.CNIPlugin][]string{
+		api.CNIPluginCilium: {"interface-name:cilium_*", "interface-name:lxc*"},
+		api.CNIPluginCalico: {
+			"interface-name:cali*",
+			"interface-name:tunl*",
+			"interface-name:vxlan.calico",
+			"interface-name:vxlan-v6.calico",
+			"interface-name:wireguard.cali",
+		},
+	}
+)
+
+// cniPrerequisitesState records what nodeadm changed on the host for the CNI.
+type cniPrerequisitesState struct {
+	MountedBPF              bool `json:"mountedBPF,omitempty"`
+	WroteNetworkManagerConf bool `json:"wroteNetworkManagerConf,omitempty"`
+}
+
+type cniPrerequisitesAspect struct {
+	nodeConfig *api.NodeConfig
+	logger     *zap.Logger
+}
+
+var _ SystemAspect = &cniPrerequisitesAspect{}
+
+// NewCNIPrerequisitesAspect prepares the host for the CNI plugin of
+// spec.cni.plugin.
+func NewCNIPrerequisitesAspect(cfg *api.NodeConfig, logger *zap.Logger) SystemAspect {
+	return &cniPrerequisitesAspect{nodeConfig: cfg, logger: logger}
+}
+
+func (a *cniPrerequisitesAspect) Name() string {
+	return cniPrerequisitesAspectName
+}
+
+// Setup mounts the BPF filesystem for Cilium, loads the ipset modules for
+// Calico and keeps NetworkManager away from the CNI interfaces. The kernel
+// checks are left to nodeadm debug, since nodeadm can't fix them.
+func (a *cniPrerequisitesAspect) Setup() error {
+	plugin := a.nodeConfig.Spec.CNI.Plugin
+	if plugin != api.CNIPluginCilium && plugin != api.CNIPluginCalico {
+		return nil
+	}
+	var state cniPrerequisitesState
+	if err := loadAspectState(cniPrerequisitesAspectName, &state); err != nil {
+		return err
+	}
+	err := a.setup(plugin, &state)
+	// saved even when setup failed, so teardown reverts what was done
+	if saveErr := saveAspectState(cniPrerequisitesAspectName, state); saveErr != nil {
+		return saveErr
+	}
+	return err
+}
+
+func (a *cniPrerequisitesAspect) setup(plugin api.CNIPlugin, state *cniPrerequisitesState) error {
+	switch plugin {
+	case api.CNIPluginCilium:
+		mounted, err := isMounted(bpfMountPoint, "bpf")
+		if err != nil {
+			return err
+		}
+		if !mounted {
+			a.logger.Info("Mounting BPF filesystem", zap.String("path", bpfMountPoint))
+			if err := enableBPFMount(); err != nil {
+				return err
+			}
+			state.MountedBPF = true
+		}
+	case api.CNIPluginCalico:
+		a.logger.Info("Loading kernel modules for Calico", zap.Strings("modules", calicoKernelModules))
+		if err := loadKernelModules(calicoKernelModules); err != nil {
+			return err
+		}
+	}
+
+	if _, err := os.Stat(networkManagerDir); errors.Is(err, fs.ErrNotExist) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	missing, err := missingUnmanagedInterfaces(plugin)
+	if err != nil || len(missing) == 0 {
+		return err
+	}
+	a.logger.Info("Configuring NetworkManager to not manage CNI interfaces", zap.Strings("interfaces", missing))
+	if err := os.MkdirAll(filepath.Join(networkManagerDir, "conf.d"), networkConfDropInDirPerms); err != nil {
+		return err
+	}
+	if err := os.WriteFile(networkManagerConfPath(), networkManagerConf(cniUnmanagedInterfaces[plugin]), cniConfigFilePerms); err != nil {
+		return err
+	}
+	state.WroteNetworkManagerConf = true
+	return reloadNetworkManager()
+}
+
+// Teardown unmounts the BPF filesystem and removes the NetworkManager config
+// if nodeadm added them. The kernel modules are left loaded.
+func (a *cniPrerequisitesAspect) Teardown() error {
+	var state cniPrerequisitesState
+	if err := loadAspectState(cniPrerequisitesAspectName, &state); err != nil {
+		return err
+	}
+	if state.MountedBPF {
+		a.logger.Info("Unmounting BPF filesystem", zap.String("path", bpfMountPoint))
+		if out, err := exec.Command("systemctl", "disable", "--now", bpfMountUnit).CombinedOutput(); err != nil {
+			return fmt.Errorf("disabling %s: %s, error: %v", bpfMountUnit, out, err)
+		}
+		if err := os.RemoveAll(filepath.Join(systemdUnitDir, bpfMountUnit)); err != nil {
+			return err
+		}
+		if out, err := exec.Command("systemctl", "daemon-reload").CombinedOutput(); err != nil {
+			return fmt.Errorf("reloading systemd: %s, error: %v", out, err)
+		}
+	}
+	if state.WroteNetworkManagerConf {
+		a.logger.Info("Removing NetworkManager config for CNI interfaces")
+		if err := os.RemoveAll(networkManagerConfPath()); err != nil {
+			return err
+		}
+		if err := reloadNetworkManager(); err != nil {
+			return err
+		}
+	}
+	return removeAspectState(cniPrerequisitesAspectName)
+}
+
+// CNIValidations returns the nodeadm debug validations of the host
+// prerequisites of spec.cni.plugin.
+func CNIValidations(cfg *api.NodeConfig) []validation.Validation[*api.NodeConfig] {
+	switch cfg.Spec.CNI.Plugin {
+	case api.CNIPluginCilium:
+		return []validation.Validation[*api.NodeConfig]{
+			cniValidation("cilium-bpffs", "Validating the BPF filesystem is mounted", validateBPFMount),
+			cniValidation("cilium-kernel", "Validating the kernel supports Cilium", validateCiliumKernel),
+			cniValidation("cilium-cgroup-v2", "Validating cgroup v2 is mounted", validateCgroupV2),
+			cniValidation("cilium-network-manager", "Validating NetworkManager doesn't manage Cilium interfaces", func() error {
+				return validateUnmanagedInterfaces(api.CNIPluginCilium)
+			}),
+		}
+	case api.CNIPluginCalico:
+		return []validation.Validation[*api.NodeConfig]{
+			cniValidation("calico-kernel-modules", "Validating the kernel modules for Calico are loaded", validateCalicoKernelModules),
+			cniValidation("calico-rp-filter", "Validating the reverse path filter setting for Calico", validateCalicoRPFilter),
+			cniValidation("calico-network-manager", "Validating NetworkManager doesn't manage Calico interfaces", func() error {
+				return validateUnmanagedInterfaces(api.CNIPluginCalico)
+			}),
+		}
+	}
+	return nil
+}
+
+func cniValidation(name, description string, check func() error) validation.Validation[*api.NodeConfig] {
+	return validation.New(name, func(ctx context.Context, informer validation.Informer, _ *api.NodeConfig) error {
+		var err error
+		informer.Starting(ctx, name, description)
+		defer func() {
+			informer.Done(ctx, name, err)
+		}()
+		err = check()
+		return err
+	})
+}
+
+func validateBPFMount() error {
+	mounted, err := isMounted(bpfMountPoint, "bpf")
+	if err != nil {
+		return err
+	}
+	if !mounted {
+		return validation.WithRemediation(
+			fmt.Errorf("BPF filesystem is not mounted at %s", bpfMountPoint),
+			"Run nodeadm init with spec.cni.plugin set to cilium to mount it, or mount it with `mount -t bpf bpffs /sys/fs/bpf`.",
+		)
+	}
+	return nil
+}
+
+func validateCgroupV2() error {
+	mounted, err := isMounted(cgroupMountPoint, "cgroup2")
+	if err != nil {
+		return err
+	}
+	if !mounted {
+		return validation.WithRemediation(
+			fmt.Errorf("cgroup v2 is not mounted at %s", cgroupMountPoint),
+			"Boot the host with the unified cgroup hierarchy, for example with the systemd.unified_cgroup_hierarchy=1 kernel parameter.",
+		)
+	}
+	return nil
+}
+
+func validateCiliumKernel() error {
+	release, err := os.ReadFile(filepath.Join(procSysDir, "kernel", "osrelease"))
+	if err != nil {
+		return err
+	}
+	if err := checkCiliumKernelVersion(strings.TrimSpace(string(release))); err != nil {
+		return validation.WithRemediation(err, "Upgrade the kernel of the host to a version supported by Cilium.")
+	}
+	config, err := readKernelConfig(strings.TrimSpace(string(release)))
+	if err != nil || config == nil {
+		// the kernel config is not always shipped, the version check has to do
+		return err
+	}
+	if missing := missingKernelConfigs(config, ciliumKernelConfigs); len(missing) > 0 {
+		return validation.WithRemediation(
+			fmt.Errorf("kernel is missing configs required by Cilium: %s", strings.Join(missing, ", ")),
+			"Use a kernel built with the BPF features Cilium requires.",
+		)
+	}
+	return nil
+}
+
+// checkCiliumKernelVersion checks a kernel release, like 5.15.0-1051-aws, is
+// supported by Cilium.
+func checkCiliumKernelVersion(release string) error {
+	var version [2]int
+	parts := strings.SplitN(release, ".", 3)
+	if len(parts) < 2 {
+		return fmt.Errorf("invalid kernel release %q", release)
+	}
+	for i := range version {
+		v, err := strconv.Atoi(strings.TrimFunc(parts[i], func(r rune) bool { return r < '0' || r > '9' }))
+		if err != nil {
+			return fmt.Errorf("invalid kernel release %q", release)
+		}
+		version[i] = v
+	}
+	if version[0] > ciliumMinKernelVersion[0] || (version[0] == ciliumMinKernelVersion[0] && version[1] >= ciliumMinKernelVersion[1]) {
+		return nil
+	}
+	if version == [2]int{4, 18} && strings.Contains(release, ".el8") {
+		return nil
+	}
+	return fmt.Errorf("kernel %s is older than %d.%d, the oldest kernel supported by Cilium", release, ciliumMinKernelVersion[0], ciliumMinKernelVersion[1])
+}
+
+func kernelConfigPaths(release string) []string {
+	return []string{
+		"/boot/config-" + release,
+		"/lib/modules/" + release + "/config",
+		"/proc/config.gz",
+	}
+}
+
+// readKernelConfig returns the config the kernel was built with, or nil if
+// the host doesn't ship it.
+func readKernelConfig(release string) ([]byte, error) {
+	for _, path := range kernelConfigPaths(release) {
+		data, err := os.ReadFile(path)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		if filepath.Ext(path) != ".gz" {
+			return data, nil
+		}
+		reader, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("reading %s: %w", path, err)
+		}
+		return io.ReadAll(reader)
+	}
+	return nil, nil
+}
+
+// missingKernelConfigs returns the configs neither built in nor built as
+// modules.
+func missingKernelConfigs(config []byte, required []string) []string {
+	enabled := map[string]bool{}
+	scanner := bufio.NewScanner(bytes.NewReader(config))
+	for scanner.Scan() {
+		name, value, ok := strings.Cut(strings.TrimSpace(scanner.Text()), "=")
+		if ok && (value == "y" || value == "m") {
+			enabled[name] = true
+		}
+	}
+	var missing []string
+	for _, name := range required {
+		if !enabled[name] {
+			missing = append(missing, name)
+		}
+	}
+	return missing
+}
+
+func validateCalicoKernelModules() error {
+	var missing []string
+	for _, module := range calicoKernelModules {
+		// built in modules are listed too
+		if _, err := os.Stat(filepath.Join(sysModuleDir, module)); errors.Is(err, fs.ErrNotExist) {
+			missing = append(missing, module)
+		} else if err != nil {
+			return err
+		}
+	}
+	if len(missing) > 0 {
+		return validation.WithRemediation(
+			fmt.Errorf("kernel modules required by Calico are not loaded: %s", strings.Join(missing, ", ")),
+			"Run nodeadm init with spec.cni.plugin set to calico to load them, or load them with modprobe.",
+		)
+	}
+	return nil
+}
+
+// validateCalicoRPFilter checks the reverse path filter is not in loose mode,
+// which Calico refuses since it would let pods spoof their IP address.
+func validateCalicoRPFilter() error {
+	key := "net.ipv4.conf.all.rp_filter"
+	value, err := os.ReadFile(sysctlPath(key))
+	if err != nil {
+		return err
+	}
+	if rpFilter := strings.TrimSpace(string(value)); rpFilter != "0" && rpFilter != "1" {
+		return validation.WithRemediation(
+			fmt.Errorf("%s is %s, Calico requires 0 or 1", key, rpFilter),
+			"Set net.ipv4.conf.all.rp_filter to 1 in spec.instance.sysctls.",
+		)
+	}
+	return nil
+}
+
+func validateUnmanagedInterfaces(plugin api.CNIPlugin) error {
+	if _, err := os.Stat(networkManagerDir); errors.Is(err, fs.ErrNotExist) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	missing, err := missingUnmanagedInterfaces(plugin)
+	if err != nil {
+		return err
+	}
+	if len(missing) > 0 {
+		return validation.WithRemediation(
+			fmt.Errorf("NetworkManager manages CNI interfaces: %s", strings.Join(missing, ", ")),
+			fmt.Sprintf("Run nodeadm init with spec.cni.plugin set to %s, or add the interfaces to unmanaged-devices in the NetworkManager [keyfile] config.", plugin),
+		)
+	}
+	return nil
+}
+
+// missingUnmanagedInterfaces returns the interfaces of the plugin not in the
+// unmanaged-devices of the NetworkManager config.
+func missingUnmanagedInterfaces(plugin api.CNIPlugin) ([]string, error) {
+	paths, err := filepath.Glob(filepath.Join(networkManagerDir, "conf.d", "*.conf"))
+	if err != nil {
+		return nil, err
+	}
+	paths = append([]string{filepath.Join(networkManagerDir, "NetworkManager.conf")}, paths...)
+	var unmanaged []string
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		unmanaged = parseUnmanagedDevices(string(data), unmanaged)
+	}
+	var missing []string
+	for _, iface := range cniUnmanagedInterfaces[plugin] {
+		if !slices.Contains(unmanaged, iface) {
+			missing = append(missing, iface)
+		}
+	}
+	return missing, nil
+}
+
+// parseUnmanagedDevices applies the unmanaged-devices keys in the [keyfile]
+// section of a NetworkManager config to the device specs of the files read
+// before it. Like NetworkManager, unmanaged-devices= replaces the list and
+// unmanaged-devices+= and unmanaged-devices-= add and remove device specs.
+func parseUnmanagedDevices(conf string, devices []string) []string {
+	var section string
+	scanner := bufio.NewScanner(strings.NewReader(conf))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			section = strings.Trim(line, "[]")
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !ok || section != "keyfile" {
+			continue
+		}
+		var specs []string
+		for _, device := range strings.Split(value, ";") {
+			if device = strings.TrimSpace(device); device != "" {
+				specs = append(specs, device)
+			}
+		}
+		switch strings.TrimSpace(key) {
+		case "unmanaged-devices":
+			devices = specs
+		case "unmanaged-devices+":
+			for _, device := range specs {
+				if !slices.Contains(devices, device) {
+					devices = append(devices, device)
+				}
+			}
+		case "unmanaged-devices-":
+			devices = slices.DeleteFunc(devices, func(device string) bool {
+				return slices.Contains(specs, device)
+			})
+		}
+	}
+	return devices
+}
+
+func networkManagerConf(interfaces []string) []byte {
+	// append to the unmanaged devices of the host instead of replacing them
+	return []byte(fmt.Sprintf("[keyfile]\nunmanaged-devices+=%s\n", strings.Join(interfaces, ";")))
+}
+
+func networkManagerConfPath() string {
+	return filepath.Join(networkManagerDir, "conf.d", networkManagerConfFile)
+}
+
+// reloadNetworkManager makes a running NetworkManager read its config again.
+func reloadNetworkManager() error {
+	if err := exec.Command("systemctl", "is-active", "--quiet", "NetworkManager").Run(); err != nil {
+		return nil
+	}
+	if out, err := exec.Command("systemctl", "reload", "NetworkManager").CombinedOutput(); err != nil {
+		return fmt.Errorf("reloading NetworkManager: %s, error: %v", out, err)
+	}
+	return nil
+}
+
+func enableBPFMount() error {
+	if err := os.WriteFile(filepath.Join(systemdUnitDir, bpfMountUnit), []byte(bpfMountUnitData), cniConfigFilePerms); err != nil {
+		return err
+	}
+	if out, err := exec.Command("systemctl", "daemon-reload").CombinedOutput(); err != nil {
+		return fmt.Errorf("reloading systemd: %s, error: %v", out, err)
+	}
+	if out, err := exec.Command("systemctl", "enable", "--now", bpfMountUnit).CombinedOutput(); err != nil {
+		return fmt.Errorf("enabling %s: %s, error: %v", bpfMountUnit, out, err)
+	}
+	return nil
+}
+
+// isMounted checks the last mount on the mount point, the one in use, has
+// the filesystem type.
+func isMounted(mountPoint, fsType string) (bool, error) {
+	data, err := os.ReadFile(mountInfoPath)
+	if err != nil {
+		return false, err
+	}
+	return mountFSType(string(data), mountPoint) == fsType, nil
+}
+
+// mountFSType returns the filesystem type of the last mount on the mount
+// point in a /proc/self/mountinfo file.
+func mountFSType(mountInfo, mountPoint string) string {
+	var fsType string
+	scanner := bufio.NewScanner(strings.NewReader(mountInfo))
+	for scanner.Scan() {
+		mount, super, ok := strings.Cut(scanner.Text(), " - ")
+		if !ok {
+			continue
+		}
+		mountFields, superFields := strings.Fields(mount), strings.Fields(super)
+		if len(mountFields) < 5 || len(superFields) < 1 {
+			continue
+		}
+		if mountFields[4] == mountPoint {
+			fsType = superFields[0]
+		}
+	}
+	return fsType
+}
@@ -0,0 +1,146 @@
+package system
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/aws/eks-hybrid/internal/api"
+)
+
+func TestMountFSType(t *testing.T) {
+	mountInfo := `22 1 259:1 / / rw,relatime shared:1 - ext4 /dev/nvme0n1p1 rw
+25 22 0:23 / /sys rw,nosuid,nodev,noexec,relatime shared:7 - sysfs sysfs rw
+28 25 0:26 / /sys/fs/cgroup rw,nosuid,nodev,noexec,relatime shared:4 - cgroup2 cgroup2 rw,nsdelegate
+30 25 0:28 / /sys/fs/bpf rw,nosuid,nodev,noexec,relatime shared:9 - bpf none rw,mode=700
+41 30 0:40 / /sys/fs/bpf rw,relatime shared:20 - tmpfs tmpfs rw
+`
+	g := NewWithT(t)
+	g.Expect(mountFSType(mountInfo, "/sys/fs/cgroup")).To(Equal("cgroup2"))
+	// the last mount hides the ones under it
+	g.Expect(mountFSType(mountInfo, "/sys/fs/bpf")).To(Equal("tmpfs"))
+	g.Expect(mountFSType(mountInfo, "/mnt")).To(BeEmpty())
+}
+
+func TestCheckCiliumKernelVersion(t *testing.T) {
+	testCases := []struct {
+		release string
+		wantErr string
+	}{
+		{release: "5.15.0-1051-aws"},
+		{release: "6.1.102-111.182.amzn2023.x86_64"},
+		{release: "5.4.0-187-generic"},
+		{release: "4.18.0-513.24.1.el8_9.x86_64"},
+		{release: "4.19.0-26-amd64", wantErr: "kernel 4.19.0-26-amd64 is older than 5.4, the oldest kernel supported by Cilium"},
+		{release: "5", wantErr: `invalid kernel release "5"`},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.release, func(t *testing.T) {
+			g := NewWithT(t)
+			err := checkCiliumKernelVersion(tc.release)
+			if tc.wantErr == "" {
+				g.Expect(err).NotTo(HaveOccurred())
+			} else {
+				g.Expect(err).To(MatchError(tc.wantErr))
+			}
+		})
+	}
+}
+
+func TestMissingKernelConfigs(t *testing.T) {
+	g := NewWithT(t)
+	config := []byte(`CONFIG_BPF=y
+CONFIG_NET_SCH_INGRESS=m
+# CONFIG_SCHEDSTATS is not set
+`)
+	g.Expect(missingKernelConfigs(config, []string{"CONFIG_BPF", "CONFIG_NET_SCH_INGRESS", "CONFIG_SCHEDSTATS"})).To(Equal([]string{"CONFIG_SCHEDSTATS"}))
+}
+
+func TestMissingUnmanagedInterfaces(t *testing.T) {
+	g := NewWithT(t)
+	originalNetworkManagerDir := networkManagerDir
+	networkManagerDir = t.TempDir()
+	t.Cleanup(func() { networkManagerDir = originalNetworkManagerDir })
+	g.Expect(os.WriteFile(filepath.Join(networkManagerDir, "NetworkManager.conf"), []byte(`[main]
+plugins=keyfile
+
+[keyfile]
+unmanaged-devices=interface-name:cali*;interface-name:tunl*
+`), 0o644)).To(Succeed())
+
+	missing, err := missingUnmanagedInterfaces(api.CNIPluginCalico)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(missing).To(Equal([]string{"interface-name:vxlan.calico", "interface-name:vxlan-v6.calico", "interface-name:wireguard.cali"}))
+
+	g.Expect(os.MkdirAll(filepath.Join(networkManagerDir, "conf.d"), 0o755)).To(Succeed())
+	g.Expect(os.WriteFile(networkManagerConfPath(), networkManagerConf(cniUnmanagedInterfaces[api.CNIPluginCalico]), 0o644)).To(Succeed())
+	missing, err = missingUnmanagedInterfaces(api.CNIPluginCalico)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(missing).To(BeEmpty())
+	g.Expect(os.WriteFile(filepath.Join(networkManagerDir, "conf.d", "zz-user.conf"), []byte("[keyfile]\nunmanaged-devices=interface-name:eth1\n"), 0o644)).To(Succeed())
+	missing, err = missingUnmanagedInterfaces(api.CNIPluginCalico)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(missing).To(HaveLen(len(cniUnmanagedInterfaces[api.CNIPluginCalico])))
+	g.Expect(validateUnmanagedInterfaces(api.CNIPluginCilium)).To(MatchError("NetworkManager manages CNI interfaces: interface-name:cilium_*, interface-name:lxc*"))
+}
+
+func TestParseUnmanagedDevices(t *testing.T) {
+	testCases := []struct {
+		name    string
+		conf    string
+		devices []string
+		want    []string
+	}{
+		{
+			name: "set",
+			conf: "[keyfile]\nunmanaged-devices=interface-name:cali*;interface-name:tunl*\n",
+			want: []string{"interface-name:cali*", "interface-name:tunl*"},
+		},
+		{
+			name:    "later file replaces the list",
+			conf:    "[keyfile]\nunmanaged-devices=interface-name:eth1\n",
+			devices: []string{"interface-name:cali*"},
+			want:    []string{"interface-name:eth1"},
+		},
+		{
+			name:    "append",
+			conf:    "[keyfile]\nunmanaged-devices+=interface-name:cali*;interface-name:tunl*\n",
+			devices: []string{"interface-name:eth1", "interface-name:cali*"},
+			want:    []string{"interface-name:eth1", "interface-name:cali*", "interface-name:tunl*"},
+		},
+		{
+			name:    "remove",
+			conf:    "[keyfile]\nunmanaged-devices-=interface-name:cali*\n",
+			devices: []string{"interface-name:eth1", "interface-name:cali*"},
+			want:    []string{"interface-name:eth1"},
+		},
+		{
+			name:    "other section",
+			conf:    "[main]\nunmanaged-devices=interface-name:cali*\n",
+			devices: []string{"interface-name:eth1"},
+			want:    []string{"interface-name:eth1"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			g.Expect(parseUnmanagedDevices(tc.conf, tc.devices)).To(Equal(tc.want))
+		})
+	}
+}
+
+func TestValidateCalicoRPFilter(t *testing.T) {
+	g := NewWithT(t)
+	originalProcSysDir := procSysDir
+	procSysDir = t.TempDir()
+	t.Cleanup(func() { procSysDir = originalProcSysDir })
+	g.Expect(os.MkdirAll(filepath.Join(procSysDir, "net/ipv4/conf/all"), 0o755)).To(Succeed())
+
+	g.Expect(os.WriteFile(filepath.Join(procSysDir, "net/ipv4/conf/all/rp_filter"), []byte("2\n"), 0o644)).To(Succeed())
+	g.Expect(validateCalicoRPFilter()).To(MatchError("net.ipv4.conf.all.rp_filter is 2, Calico requires 0 or 1"))
+
+	g.Expect(os.WriteFile(filepath.Join(procSysDir, "net/ipv4/conf/all/rp_filter"), []byte("1\n"), 0o644)).To(Succeed())
+	g.Expect(validateCalicoRPFilter()).To(Succeed())
+}
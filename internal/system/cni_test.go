@@ -0,0 +1,118 @@
+package system
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/aws/eks-hybrid/internal/api"
+)
+
+func TestCNIPorts(t *testing.T) {
+	testCases := []struct {
+		name string
+		cni  api.CNIOptions
+		want []string
+	}{
+		{
+			name: "no plugin",
+		},
+		{
+			name: "cilium default encapsulation",
+			cni:  api.CNIOptions{Plugin: api.CNIPluginCilium},
+			want: []string{"4240/tcp", "4244/tcp", "8472/udp"},
+		},
+		{
+			name: "cilium geneve with bgp",
+			cni:  api.CNIOptions{Plugin: api.CNIPluginCilium, Encapsulation: api.CNIEncapsulationGeneve, BGP: true},
+			want: []string{"4240/tcp", "4244/tcp", "6081/udp", "179/tcp"},
+		},
+		{
+			name: "calico vxlan",
+			cni:  api.CNIOptions{Plugin: api.CNIPluginCalico, Encapsulation: api.CNIEncapsulationVXLAN},
+			want: []string{"5473/tcp", "4789/udp"},
+		},
+		{
+			name: "calico bgp without encapsulation",
+			cni:  api.CNIOptions{Plugin: api.CNIPluginCalico, Encapsulation: api.CNIEncapsulationNone, BGP: true},
+			want: []string{"5473/tcp", "179/tcp"},
+		},
+		{
+			name: "other geneve",
+			cni:  api.CNIOptions{Plugin: api.CNIPluginOther, Encapsulation: api.CNIEncapsulationGeneve},
+			want: []string{"6081/udp"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			var got []string
+			for _, port := range cniPorts(tc.cni) {
+				got = append(got, port.String())
+			}
+			g.Expect(got).To(Equal(tc.want))
+		})
+	}
+}
+
+func TestValidateCNI(t *testing.T) {
+	testCases := []struct {
+		name    string
+		cni     api.CNIOptions
+		wantErr string
+	}{
+		{
+			name: "empty",
+		},
+		{
+			name: "cilium geneve",
+			cni:  api.CNIOptions{Plugin: api.CNIPluginCilium, Encapsulation: api.CNIEncapsulationGeneve, BGP: true},
+		},
+		{
+			name:    "unknown plugin",
+			cni:     api.CNIOptions{Plugin: "flannel"},
+			wantErr: `unknown CNI plugin "flannel" in spec.cni.plugin`,
+		},
+		{
+			name:    "unknown encapsulation",
+			cni:     api.CNIOptions{Plugin: api.CNIPluginOther, Encapsulation: "ipip"},
+			wantErr: `unknown encapsulation "ipip" in spec.cni.encapsulation`,
+		},
+		{
+			name:    "calico geneve",
+			cni:     api.CNIOptions{Plugin: api.CNIPluginCalico, Encapsulation: api.CNIEncapsulationGeneve},
+			wantErr: "calico doesn't support the geneve encapsulation in spec.cni.encapsulation",
+		},
+		{
+			name:    "bgp without plugin",
+			cni:     api.CNIOptions{BGP: true},
+			wantErr: "spec.cni.plugin is required when spec.cni.encapsulation or spec.cni.bgp are set",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			err := ValidateCNI(&api.NodeConfig{Spec: api.NodeConfigSpec{CNI: tc.cni}})
+			if tc.wantErr == "" {
+				g.Expect(err).NotTo(HaveOccurred())
+			} else {
+				g.Expect(err).To(MatchError(tc.wantErr))
+			}
+		})
+	}
+}
+
+func TestValidateCNIPorts(t *testing.T) {
+	g := NewWithT(t)
+	fakeFw := &fakeFirewall{open: map[string]bool{}}
+	cfg := &api.NodeConfig{}
+
+	g.Expect(ValidateCNIPorts(cfg, fakeFw)).To(MatchError("Cilium (8472/udp) or Calico (4789/udp) VxLan ports are not open on the host"))
+
+	fakeFw.open["4789/udp"] = true
+	g.Expect(ValidateCNIPorts(cfg, fakeFw)).To(Succeed())
+
+	// the ports aspect opens the CNI ports
+	cfg.Spec.CNI.Plugin = api.CNIPluginCilium
+	g.Expect(ValidateCNIPorts(cfg, &fakeFirewall{open: map[string]bool{}})).To(Succeed())
+}
@@ -0,0 +1,184 @@
+package system
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"syscall"
+
+	"go.uber.org/zap"
+
+	"github.com/aws/eks-hybrid/internal/api"
+	"github.com/aws/eks-hybrid/internal/tracker"
+)
+
+const (
+	dataDirsAspectName = "data-dirs"
+	dataDirPerms       = 0o755
+
+	gib = 1024 * 1024 * 1024
+	mib = 1024 * 1024
+)
+
+type dataDir struct {
+	// field is the node config field the directory is set in
+	field string
+	path  string
+	// recommendedFreeBytes is the free space below which init warns about the
+	// directory's filesystem
+	recommendedFreeBytes uint64
+}
+
+type dataDirsAspect struct {
+	nodeConfig *api.NodeConfig
+	logger     *zap.Logger
+}
+
+var _ SystemAspect = &dataDirsAspect{}
+
+// NewDataDirsAspect creates the relocated data directories of containerd and
+// kubelet, warning when they aren't on a mounted filesystem with enough free
+// space.
+func NewDataDirsAspect(cfg *api.NodeConfig, logger *zap.Logger) SystemAspect {
+	return &dataDirsAspect{nodeConfig: cfg, logger: logger}
+}
+
+func (a *dataDirsAspect) Name() string {
+	return dataDirsAspectName
+}
+
+func (a *dataDirsAspect) Setup() error {
+	dirs := configuredDataDirs(a.nodeConfig.Spec.Instance.DataDirs)
+	if len(dirs) == 0 {
+		return nil
+	}
+	rootDevice, err := deviceOf("/")
+	if err != nil {
+		return err
+	}
+	var paths []string
+	for _, dir := range dirs {
+		a.logger.Info("Setting up data directory", zap.String("field", dir.field), zap.String("path", dir.path))
+		warnings, err := checkDataDir(dir, rootDevice)
+		if err != nil {
+			return err
+		}
+		for _, warning := range warnings {
+			a.logger.Warn(warning, zap.String("field", dir.field), zap.String("path", dir.path))
+		}
+		if err := os.MkdirAll(dir.path, dataDirPerms); err != nil {
+			return fmt.Errorf("creating %s: %w", dir.path, err)
+		}
+		paths = append(paths, dir.path)
+	}
+	return trackDataDirs(paths)
+}
+
+// Teardown does nothing, the directories may hold data on disks mounted by
+// the user. Uninstall with --force empties them.
+func (a *dataDirsAspect) Teardown() error {
+	return nil
+}
+
+// ValidateDataDirs checks the data directories of the node config are
+// absolute and distinct.
+func ValidateDataDirs(cfg *api.NodeConfig) error {
+	seen := map[string]string{}
+	for _, dir := range configuredDataDirs(cfg.Spec.Instance.DataDirs) {
+		if !filepath.IsAbs(dir.path) {
+			return fmt.Errorf("spec.instance.dataDirs.%s must be an absolute path, got %q", dir.field, dir.path)
+		}
+		cleanPath := filepath.Clean(dir.path)
+		if field, ok := seen[cleanPath]; ok {
+			return fmt.Errorf("spec.instance.dataDirs.%s and spec.instance.dataDirs.%s must be different directories", field, dir.field)
+		}
+		seen[cleanPath] = dir.field
+	}
+	return nil
+}
+
+func configuredDataDirs(opts api.DataDirOptions) []dataDir {
+	var dirs []dataDir
+	for _, dir := range []dataDir{
+		{field: "containerdRoot", path: opts.ContainerdRoot, recommendedFreeBytes: 10 * gib},
+		{field: "containerdState", path: opts.ContainerdState, recommendedFreeBytes: 64 * mib},
+		{field: "kubeletRoot", path: opts.KubeletRoot, recommendedFreeBytes: 10 * gib},
+		{field: "podLogs", path: opts.PodLogs, recommendedFreeBytes: 1 * gib},
+	} {
+		if dir.path != "" {
+			dirs = append(dirs, dir)
+		}
+	}
+	return dirs
+}
+
+// checkDataDir returns warnings about the filesystem the directory is or will
+// be created in. Data directories are usually moved off the root filesystem,
+// so a directory on it may mean its disk is not mounted yet, but a larger root
+// filesystem or a directory on the same disk is valid too.
+func checkDataDir(dir dataDir, rootDevice uint64) ([]string, error) {
+	existing, err := nearestExistingDir(dir.path)
+	if err != nil {
+		return nil, err
+	}
+	device, err := deviceOf(existing)
+	if err != nil {
+		return nil, err
+	}
+	var warnings []string
+	if device == rootDevice {
+		warnings = append(warnings, fmt.Sprintf("spec.instance.dataDirs.%s %s is on the root filesystem, check its disk is mounted", dir.field, dir.path))
+	}
+	var stat syscall.Statfs_t
+	if err := syscall.Statfs(existing, &stat); err != nil {
+		return nil, fmt.Errorf("getting free space of %s: %w", existing, err)
+	}
+	if free := stat.Bavail * uint64(stat.Bsize); free < dir.recommendedFreeBytes {
+		warnings = append(warnings, fmt.Sprintf("spec.instance.dataDirs.%s %s has %d MiB free, at least %d MiB are recommended", dir.field, dir.path, free/mib, dir.recommendedFreeBytes/mib))
+	}
+	return warnings, nil
+}
+
+// nearestExistingDir returns path or its closest ancestor that exists.
+func nearestExistingDir(path string) (string, error) {
+	for {
+		info, err := os.Stat(path)
+		if err == nil {
+			if !info.IsDir() {
+				return "", fmt.Errorf("%s is not a directory", path)
+			}
+			return path, nil
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			return "", err
+		}
+		parent := filepath.Dir(path)
+		if parent == path {
+			return "", err
+		}
+		path = parent
+	}
+}
+
+func deviceOf(path string) (uint64, error) {
+	var stat syscall.Stat_t
+	if err := syscall.Stat(path, &stat); err != nil {
+		return 0, fmt.Errorf("getting filesystem of %s: %w", path, err)
+	}
+	return uint64(stat.Dev), nil
+}
+
+// trackDataDirs records the directories so uninstall can clean them up. Nodes
+// where nodeadm didn't install the components have nothing to record them in.
+func trackDataDirs(paths []string) error {
+	installed, err := tracker.GetInstalledArtifacts()
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	installed.MarkDataDirs(paths)
+	return installed.Save()
+}
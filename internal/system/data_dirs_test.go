@@ -0,0 +1,92 @@
+package system
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/aws/eks-hybrid/internal/api"
+)
+
+func TestValidateDataDirs(t *testing.T) {
+	testCases := []struct {
+		name     string
+		dataDirs api.DataDirOptions
+		wantErr  string
+	}{
+		{
+			name: "empty",
+		},
+		{
+			name: "valid",
+			dataDirs: api.DataDirOptions{
+				ContainerdRoot:  "/mnt/data/containerd",
+				ContainerdState: "/run/containerd",
+				KubeletRoot:     "/mnt/data/kubelet",
+				PodLogs:         "/mnt/logs/pods",
+			},
+		},
+		{
+			name:     "relative path",
+			dataDirs: api.DataDirOptions{KubeletRoot: "data/kubelet"},
+			wantErr:  `spec.instance.dataDirs.kubeletRoot must be an absolute path, got "data/kubelet"`,
+		},
+		{
+			name: "same directory",
+			dataDirs: api.DataDirOptions{
+				ContainerdRoot: "/mnt/data",
+				KubeletRoot:    "/mnt/data/",
+			},
+			wantErr: "spec.instance.dataDirs.containerdRoot and spec.instance.dataDirs.kubeletRoot must be different directories",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			err := ValidateDataDirs(&api.NodeConfig{
+				Spec: api.NodeConfigSpec{
+					Instance: api.InstanceOptions{DataDirs: tc.dataDirs},
+				},
+			})
+			if tc.wantErr == "" {
+				g.Expect(err).NotTo(HaveOccurred())
+			} else {
+				g.Expect(err).To(MatchError(tc.wantErr))
+			}
+		})
+	}
+}
+
+func TestNearestExistingDir(t *testing.T) {
+	g := NewWithT(t)
+	tmp := t.TempDir()
+	g.Expect(os.MkdirAll(filepath.Join(tmp, "mnt"), 0o755)).To(Succeed())
+	g.Expect(os.WriteFile(filepath.Join(tmp, "file"), []byte("test"), 0o644)).To(Succeed())
+
+	dir, err := nearestExistingDir(filepath.Join(tmp, "mnt", "data", "kubelet"))
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(dir).To(Equal(filepath.Join(tmp, "mnt")))
+
+	dir, err = nearestExistingDir(tmp)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(dir).To(Equal(tmp))
+
+	_, err = nearestExistingDir(filepath.Join(tmp, "file"))
+	g.Expect(err).To(MatchError(ContainSubstring("is not a directory")))
+}
+
+func TestCheckDataDir(t *testing.T) {
+	g := NewWithT(t)
+	tmp := t.TempDir()
+	device, err := deviceOf(tmp)
+	g.Expect(err).NotTo(HaveOccurred())
+	dir := dataDir{field: "kubeletRoot", path: filepath.Join(tmp, "kubelet")}
+
+	g.Expect(checkDataDir(dir, device)).To(ConsistOf(ContainSubstring("is on the root filesystem")))
+	g.Expect(checkDataDir(dir, device+1)).To(BeEmpty())
+
+	dir.recommendedFreeBytes = 1 << 62
+	g.Expect(checkDataDir(dir, device+1)).To(ConsistOf(ContainSubstring("MiB are recommended")))
+}
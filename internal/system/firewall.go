@@ -0,0 +1,61 @@
+package system
+
+import (
+	"fmt"
+
+	"github.com/aws/eks-hybrid/internal/api"
+	"github.com/aws/eks-hybrid/internal/firewall"
+)
+
+// detectedFirewallBackends are checked in order. firewalld and ufw manage
+// nftables and iptables rules themselves, so they come first, and iptables
+// before nftables since iptables-nft rules are also nftables rules.
+var detectedFirewallBackends = []api.FirewallBackend{
+	api.FirewallBackendFirewalld,
+	api.FirewallBackendUFW,
+	api.FirewallBackendIptables,
+	api.FirewallBackendNftables,
+}
+
+// NewFirewallManager returns the manager of the firewall backend, detected
+// from the host when empty.
+func NewFirewallManager(backend api.FirewallBackend) (firewall.Manager, error) {
+	return newFirewallManager(firewallBackend(backend))
+}
+
+// firewallBackend returns backend, or when empty the first one enabled on the
+// host. Hosts without any get the operating system's default firewall, which
+// reports it's not enabled.
+func firewallBackend(backend api.FirewallBackend) api.FirewallBackend {
+	if backend != "" {
+		return backend
+	}
+	for _, detected := range detectedFirewallBackends {
+		manager, err := newFirewallManager(detected)
+		if err != nil {
+			continue
+		}
+		if enabled, err := manager.IsEnabled(); err == nil && enabled {
+			return detected
+		}
+	}
+	if GetOsName() == UbuntuOsName {
+		return api.FirewallBackendUFW
+	}
+	return api.FirewallBackendFirewalld
+}
+
+func newFirewallManager(backend api.FirewallBackend) (firewall.Manager, error) {
+	switch backend {
+	case api.FirewallBackendFirewalld:
+		return firewall.NewFirewalld(), nil
+	case api.FirewallBackendUFW:
+		return firewall.NewUncomplicatedFirewall(), nil
+	case api.FirewallBackendNftables:
+		return firewall.NewNftables(), nil
+	case api.FirewallBackendIptables:
+		return firewall.NewIptables(), nil
+	default:
+		return nil, fmt.Errorf("unknown firewall backend %q", backend)
+	}
+}
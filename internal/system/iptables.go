@@ -0,0 +1,100 @@
+package system
+
+import (
+	"os/exec"
+
+	"go.uber.org/zap"
+
+	"github.com/aws/eks-hybrid/internal/api"
+	"github.com/aws/eks-hybrid/internal/iptables"
+)
+
+const iptablesAspectName = "iptables"
+
+// iptablesState records the iptables alternatives from before nodeadm
+// switched them.
+type iptablesState struct {
+	Alternatives map[string]string `json:"alternatives,omitempty"`
+}
+
+type iptablesAspect struct {
+	nodeConfig *api.NodeConfig
+	logger     *zap.Logger
+}
+
+var _ SystemAspect = &iptablesAspect{}
+
+// NewIptablesAspect switches the iptables command to the backend with the
+// rules of the host, for kube-proxy and the CNI to use the same one.
+func NewIptablesAspect(cfg *api.NodeConfig, logger *zap.Logger) SystemAspect {
+	return &iptablesAspect{nodeConfig: cfg, logger: logger}
+}
+
+func (a *iptablesAspect) Name() string {
+	return iptablesAspectName
+}
+
+func (a *iptablesAspect) Setup() error {
+	if _, err := exec.LookPath("iptables"); err != nil {
+		return nil
+	}
+	status, err := iptables.DetectBackend()
+	if err != nil {
+		return err
+	}
+	if status.Mixed() {
+		a.logger.Warn("Both iptables backends have rules, traffic may be dropped by the rules of the backend kube-proxy and the CNI don't use",
+			zap.Int("legacy", status.Rules[iptables.BackendLegacy]), zap.Int("nft", status.Rules[iptables.BackendNft]))
+	}
+	preferred := status.Preferred()
+	if preferred == status.Active {
+		a.logger.Info("Using iptables backend", zap.String("backend", string(status.Active)))
+		return nil
+	}
+	current, err := iptables.Alternatives()
+	if err != nil {
+		return err
+	}
+	if current == nil {
+		a.logger.Warn("Can't switch iptables backend without alternatives",
+			zap.String("backend", string(status.Active)), zap.String("rules", string(preferred)))
+		return nil
+	}
+	paths, err := iptables.BackendAlternatives(preferred)
+	if err != nil {
+		return err
+	}
+	var state iptablesState
+	if err := loadAspectState(iptablesAspectName, &state); err != nil {
+		return err
+	}
+	// keep the alternatives from before the first init
+	if state.Alternatives == nil {
+		state.Alternatives = current
+		if err := saveAspectState(iptablesAspectName, state); err != nil {
+			return err
+		}
+	}
+	a.logger.Info("Switching iptables backend", zap.String("from", string(status.Active)), zap.String("to", string(preferred)))
+	return iptables.SetAlternatives(paths)
+}
+
+// Teardown points the iptables alternatives back to the commands from before
+// init, unless uninstall removed the iptables package along with them.
+func (a *iptablesAspect) Teardown() error {
+	var state iptablesState
+	if err := loadAspectState(iptablesAspectName, &state); err != nil {
+		return err
+	}
+	current, err := iptables.Alternatives()
+	if err != nil {
+		return err
+	}
+	if state.Alternatives != nil && current != nil {
+		a.logger.Info("Restoring iptables alternatives", zap.Any("alternatives", state.Alternatives))
+		if err := iptables.SetAlternatives(state.Alternatives); err != nil {
+			return err
+		}
+	}
+	return removeAspectState(iptablesAspectName)
+}
@@ -0,0 +1,60 @@
+package system
+
+import (
+	"bufio"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/aws/eks-hybrid/internal/api"
+)
+
+var kernelModuleRegex = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+
+// KernelModulesConfig merges the kernel modules of the node config, and the
+// ones the CNI plugin needs, into the defaults of a modules-load.d config file.
+func KernelModulesConfig(defaults string, cfg *api.NodeConfig) []byte {
+	var modules []string
+	scanner := bufio.NewScanner(strings.NewReader(defaults))
+	for scanner.Scan() {
+		if module := strings.TrimSpace(scanner.Text()); module != "" {
+			modules = append(modules, module)
+		}
+	}
+	for _, module := range append(cniKernelModules(cfg.Spec.CNI), cfg.Spec.Instance.KernelModules...) {
+		if !slices.Contains(modules, module) {
+			modules = append(modules, module)
+		}
+	}
+	return []byte(strings.Join(modules, "\n") + "\n")
+}
+
+func cniKernelModules(cni api.CNIOptions) []string {
+	if cni.Plugin == api.CNIPluginCalico {
+		return calicoKernelModules
+	}
+	return nil
+}
+
+func validateKernelModules(modules []string) error {
+	for _, module := range modules {
+		if !kernelModuleRegex.MatchString(module) {
+			return fmt.Errorf("invalid kernel module name %q in spec.instance.kernelModules", module)
+		}
+	}
+	return nil
+}
+
+// loadKernelModules loads the modules right away, so the settings they add can
+// be applied before the container runtime loads them through modules-load.d.
+func loadKernelModules(modules []string) error {
+	for _, module := range modules {
+		// #nosec G204 Subprocess launched with variable
+		if out, err := exec.Command("modprobe", module).CombinedOutput(); err != nil {
+			return fmt.Errorf("loading kernel module %s: %s, error: %v", module, out, err)
+		}
+	}
+	return nil
+}
@@ -1,23 +1,26 @@
 package system
 
 import (
-	"os"
-	"os/exec"
-	"strings"
-
 	"go.uber.org/zap"
 
 	"github.com/aws/eks-hybrid/internal/api"
+	"github.com/aws/eks-hybrid/internal/localdisk"
 )
 
 const localDiskAspectName = "local-disk"
 
-func NewLocalDiskAspect(cfg *api.NodeConfig) SystemAspect {
-	return &localDiskAspect{nodeConfig: cfg}
+// localDiskState records the mount units nodeadm enabled.
+type localDiskState struct {
+	Units []string `json:"units,omitempty"`
+}
+
+func NewLocalDiskAspect(cfg *api.NodeConfig, logger *zap.Logger) SystemAspect {
+	return &localDiskAspect{nodeConfig: cfg, logger: logger}
 }
 
 type localDiskAspect struct {
 	nodeConfig *api.NodeConfig
+	logger     *zap.Logger
 }
 
 func (a *localDiskAspect) Name() string {
@@ -26,13 +29,33 @@ func (a *localDiskAspect) Name() string {
 
 func (a *localDiskAspect) Setup() error {
 	if a.nodeConfig.Spec.Instance.LocalStorage.Strategy == "" {
-		zap.L().Info("Not configuring local disks!")
+		a.logger.Info("Not configuring local disks!")
 		return nil
 	}
-	strategy := strings.ToLower(string(a.nodeConfig.Spec.Instance.LocalStorage.Strategy))
-	// #nosec G204 Subprocess launched with variable
-	cmd := exec.Command("setup-local-disks", strategy)
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-	return cmd.Run()
+	var state localDiskState
+	if err := loadAspectState(localDiskAspectName, &state); err != nil {
+		return err
+	}
+	setup := localdisk.New(a.nodeConfig, a.logger)
+	err := setup.Run()
+	for _, unit := range setup.Units() {
+		state.Units = appendMissing(state.Units, unit)
+	}
+	// saved even when the setup failed, so teardown removes the mounts done
+	if saveErr := saveAspectState(localDiskAspectName, state); saveErr != nil {
+		return saveErr
+	}
+	return err
+}
+
+// Teardown unmounts the local disks and removes their mount units.
+func (a *localDiskAspect) Teardown() error {
+	var state localDiskState
+	if err := loadAspectState(localDiskAspectName, &state); err != nil {
+		return err
+	}
+	if err := localdisk.New(a.nodeConfig, a.logger).RemoveMounts(state.Units); err != nil {
+		return err
+	}
+	return removeAspectState(localDiskAspectName)
 }
@@ -57,6 +57,38 @@ func (a *networkingAspect) Setup() error {
 	return nil
 }
 
+// networkingState records whether nodeadm wrote the primary ENI drop-in, to
+// leave a drop-in from before init in place.
+type networkingState struct {
+	WroteDropIn bool `json:"wroteDropIn,omitempty"`
+}
+
+// Teardown removes the primary ENI drop-in if nodeadm wrote it.
+func (a *networkingAspect) Teardown() error {
+	var state networkingState
+	if err := loadAspectState(networkingAspectName, &state); err != nil {
+		return err
+	}
+	if state.WroteDropIn {
+		zap.L().Info("removing eks_primary_eni_only network configuration")
+		if err := os.RemoveAll(eksPrimaryENIOnlyConfPath()); err != nil {
+			return fmt.Errorf("failed to remove eks_primary_eni_only network configuration: %w", err)
+		}
+		if err := a.reloadNetworkConfigurations(); err != nil {
+			return fmt.Errorf("failed to reload network configurations: %w", err)
+		}
+	}
+	return removeAspectState(networkingAspectName)
+}
+
+func eksPrimaryENIOnlyConfDropInDir() string {
+	return fmt.Sprintf("%s/%s.d", administrationNetworkDir, ec2NetworkConfigurationName)
+}
+
+func eksPrimaryENIOnlyConfPath() string {
+	return fmt.Sprintf("%s/%s", eksPrimaryENIOnlyConfDropInDir(), eksPrimaryENIOnlyConfName)
+}
+
 // ensureEKSNetworkConfiguration will install eks specific network configuration into system.
 // NOTE: this is a temporary fix for AL2023, where the `80-ec2.network` setup by amazon-ec2-net-utils will cause systemd.network
 // to manage all ENIs on host, and that can potentially result in multiple issues including:
@@ -67,8 +99,8 @@ func (a *networkingAspect) Setup() error {
 // TODO: there are limitations on current solutions as well, and we should figure long term solution for this:
 //  1. the altNames for ENIs(a new feature in AL2023) were setup by amazon-ec2-net-utils via udev rules, but it's disabled by eks.
 func (a *networkingAspect) ensureEKSNetworkConfiguration() error {
-	networkCfgDropInDir := fmt.Sprintf("%s/%s.d", administrationNetworkDir, ec2NetworkConfigurationName)
-	eksPrimaryENIOnlyConfPathName := fmt.Sprintf("%s/%s", networkCfgDropInDir, eksPrimaryENIOnlyConfName)
+	networkCfgDropInDir := eksPrimaryENIOnlyConfDropInDir()
+	eksPrimaryENIOnlyConfPathName := eksPrimaryENIOnlyConfPath()
 	if exists, err := util.IsFilePathExists(eksPrimaryENIOnlyConfPathName); err != nil {
 		return fmt.Errorf("failed to check eks_primary_eni_only network configuration existance: %w", err)
 	} else if exists {
@@ -87,6 +119,9 @@ func (a *networkingAspect) ensureEKSNetworkConfiguration() error {
 	if err := os.WriteFile(eksPrimaryENIOnlyConfPathName, eksPrimaryENIOnlyConfContent, networkConfFilePerms); err != nil {
 		return fmt.Errorf("failed to write eks_primary_eni_only network configuration: %w", err)
 	}
+	if err := saveAspectState(networkingAspectName, networkingState{WroteDropIn: true}); err != nil {
+		return fmt.Errorf("failed to record eks_primary_eni_only network configuration: %w", err)
+	}
 	if err := a.reloadNetworkConfigurations(); err != nil {
 		return fmt.Errorf("failed to reload network configurations: %w", err)
 	}
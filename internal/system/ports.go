@@ -2,6 +2,7 @@ package system
 
 import (
 	"fmt"
+	"strings"
 
 	"go.uber.org/zap"
 
@@ -15,30 +16,92 @@ const (
 	kubeProxyHealthzPort   = "10256"
 	nodePortStartRangePort = "30000"
 	nodePortEndRangePort   = "32767"
+
+	protocolTCP = "tcp"
+	protocolUDP = "udp"
 )
 
+// nodePort is a port or a range of ports the node must accept traffic on.
+type nodePort struct {
+	name  string
+	start string
+	// end is set for ranges, which are only supported for tcp
+	end      string
+	protocol string
+}
+
+var nodePorts = []nodePort{
+	{name: "kubelet-server-port", start: kubeletServePort, protocol: protocolTCP},
+	{name: "kube-proxy-port", start: kubeProxyHealthzPort, protocol: protocolTCP},
+	{name: "node-port-services", start: nodePortStartRangePort, end: nodePortEndRangePort, protocol: protocolTCP},
+}
+
+// ports returns the port, or the range in the start-end form.
+func (p nodePort) ports() string {
+	if p.end == "" {
+		return p.start
+	}
+	return fmt.Sprintf("%s-%s", p.start, p.end)
+}
+
+func (p nodePort) String() string {
+	return fmt.Sprintf("%s/%s", p.ports(), p.protocol)
+}
+
+// parseNodePort parses the port/protocol form returned by String.
+func parseNodePort(port string) (nodePort, error) {
+	ports, protocol, ok := strings.Cut(port, "/")
+	if !ok || (protocol != protocolTCP && protocol != protocolUDP) {
+		return nodePort{}, fmt.Errorf("invalid port %q", port)
+	}
+	start, end, _ := strings.Cut(ports, "-")
+	return nodePort{start: start, end: end, protocol: protocol}, nil
+}
+
+func (p nodePort) allow(firewallManager firewall.Manager) error {
+	switch {
+	case p.protocol == protocolUDP:
+		return firewallManager.AllowUdpPort(p.start)
+	case p.end != "":
+		return firewallManager.AllowTcpPortRange(p.start, p.end)
+	default:
+		return firewallManager.AllowTcpPort(p.start)
+	}
+}
+
+func (p nodePort) remove(firewallManager firewall.Manager) error {
+	switch {
+	case p.protocol == protocolUDP:
+		return firewallManager.RemoveUdpPort(p.start)
+	case p.end != "":
+		return firewallManager.RemoveTcpPortRange(p.start, p.end)
+	default:
+		return firewallManager.RemoveTcpPort(p.start)
+	}
+}
+
+// portsState records the ports nodeadm opened, the ones already open before
+// init are left open on teardown.
+type portsState struct {
+	Backend api.FirewallBackend `json:"backend,omitempty"`
+	// Ports are in the port/protocol form, with start-end for ranges
+	Ports []string `json:"ports,omitempty"`
+}
+
 type portsAspect struct {
-	nodeConfig      *api.NodeConfig
-	logger          *zap.Logger
-	firewallManager firewall.Manager
+	nodeConfig         *api.NodeConfig
+	logger             *zap.Logger
+	newFirewallManager func(api.FirewallBackend) (firewall.Manager, error)
 }
 
 var _ SystemAspect = &portsAspect{}
 
 func NewPortsAspect(cfg *api.NodeConfig, logger *zap.Logger) SystemAspect {
 	return &portsAspect{
-		nodeConfig:      cfg,
-		logger:          logger,
-		firewallManager: NewFirewallManager(),
-	}
-}
-
-func NewFirewallManager() firewall.Manager {
-	osName := GetOsName()
-	if osName == UbuntuOsName {
-		return firewall.NewUncomplicatedFirewall()
+		nodeConfig:         cfg,
+		logger:             logger,
+		newFirewallManager: newFirewallManager,
 	}
-	return firewall.NewFirewalld()
 }
 
 func (s *portsAspect) Name() string {
@@ -46,32 +109,87 @@ func (s *portsAspect) Name() string {
 }
 
 func (s *portsAspect) Setup() error {
-	firewallEnabled, err := s.firewallManager.IsEnabled()
+	backend := firewallBackend(s.nodeConfig.Spec.Instance.Firewall.Backend)
+	firewallManager, err := s.newFirewallManager(backend)
+	if err != nil {
+		return err
+	}
+	firewallEnabled, err := firewallManager.IsEnabled()
 	if err != nil {
 		s.logger.Warn("Failed to get firewall status", zap.Error(err))
 		s.logger.Info("Skip setting firewall rules")
 		return nil
 	}
-	if firewallEnabled {
-		s.logger.Info("Allowing port on firewall", zap.Reflect("kubelet-server-port", kubeletServePort))
-		if err = s.firewallManager.AllowTcpPort(kubeletServePort); err != nil {
+	if !firewallEnabled {
+		s.logger.Info("No firewall enabled on the host. Skipping setting firewall rules...")
+		return nil
+	}
+	var state portsState
+	if err := loadAspectState(portsAspectName, &state); err != nil {
+		return err
+	}
+	if state.Backend != "" && state.Backend != backend {
+		s.logger.Info("Firewall backend changed, removing the rules added to the previous one", zap.String("backend", string(state.Backend)))
+		if err := s.Teardown(); err != nil {
 			return err
 		}
-		s.logger.Info("Allowing port on firewall", zap.Reflect("kube-proxy-port", kubeProxyHealthzPort))
-		if err = s.firewallManager.AllowTcpPort(kubeProxyHealthzPort); err != nil {
+		state = portsState{}
+	}
+	state.Backend = backend
+	for _, port := range append(nodePorts, cniPorts(s.nodeConfig.Spec.CNI)...) {
+		open, err := firewallManager.IsPortOpen(port.ports(), port.protocol)
+		if err != nil {
 			return err
 		}
-		s.logger.Info("Allowing port on firewall", zap.Reflect("node-port-services",
-			fmt.Sprintf("%s-%s", nodePortStartRangePort, nodePortEndRangePort)))
-		if err = s.firewallManager.AllowTcpPortRange(nodePortStartRangePort, nodePortEndRangePort); err != nil {
+		if open {
+			continue
+		}
+		s.logger.Info("Allowing port on firewall", zap.Reflect(port.name, port.String()))
+		if err := port.allow(firewallManager); err != nil {
 			return err
 		}
-		s.logger.Info("Flushing firewall rules")
-		if err = s.firewallManager.FlushRules(); err != nil {
+		state.Ports = appendMissing(state.Ports, port.String())
+	}
+	s.logger.Info("Flushing firewall rules")
+	if err = firewallManager.FlushRules(); err != nil {
+		return err
+	}
+	return saveAspectState(portsAspectName, state)
+}
+
+// Teardown closes the ports nodeadm opened.
+func (s *portsAspect) Teardown() error {
+	var state portsState
+	if err := loadAspectState(portsAspectName, &state); err != nil {
+		return err
+	}
+	if len(state.Ports) == 0 {
+		return removeAspectState(portsAspectName)
+	}
+	firewallManager, err := s.newFirewallManager(firewallBackend(state.Backend))
+	if err != nil {
+		return err
+	}
+	firewallEnabled, err := firewallManager.IsEnabled()
+	if err != nil {
+		return fmt.Errorf("getting firewall status: %w", err)
+	}
+	if !firewallEnabled {
+		s.logger.Info("No firewall enabled on the host. Skipping removing firewall rules...")
+		return removeAspectState(portsAspectName)
+	}
+	for _, recorded := range state.Ports {
+		s.logger.Info("Removing port from firewall", zap.String("port", recorded))
+		port, err := parseNodePort(recorded)
+		if err != nil {
 			return err
 		}
-	} else {
-		s.logger.Info("No firewall enabled on the host. Skipping setting firewall rules...")
+		if err := port.remove(firewallManager); err != nil {
+			return err
+		}
+	}
+	if err := firewallManager.FlushRules(); err != nil {
+		return err
 	}
-	return nil
+	return removeAspectState(portsAspectName)
 }
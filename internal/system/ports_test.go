@@ -0,0 +1,92 @@
+package system
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"go.uber.org/zap"
+
+	"github.com/aws/eks-hybrid/internal/api"
+	"github.com/aws/eks-hybrid/internal/firewall"
+)
+
+// fakeFirewall keeps the open ports in memory in the port/protocol form,
+// ranges in the start-end form.
+type fakeFirewall struct {
+	open    map[string]bool
+	flushes int
+}
+
+func (f *fakeFirewall) IsEnabled() (bool, error) { return true, nil }
+
+func (f *fakeFirewall) AllowTcpPort(port string) error {
+	f.open[port+"/tcp"] = true
+	return nil
+}
+
+func (f *fakeFirewall) AllowTcpPortRange(start, end string) error {
+	return f.AllowTcpPort(start + "-" + end)
+}
+
+func (f *fakeFirewall) AllowUdpPort(port string) error {
+	f.open[port+"/udp"] = true
+	return nil
+}
+
+func (f *fakeFirewall) RemoveTcpPort(port string) error {
+	delete(f.open, port+"/tcp")
+	return nil
+}
+
+func (f *fakeFirewall) RemoveTcpPortRange(start, end string) error {
+	return f.RemoveTcpPort(start + "-" + end)
+}
+
+func (f *fakeFirewall) RemoveUdpPort(port string) error {
+	delete(f.open, port+"/udp")
+	return nil
+}
+
+func (f *fakeFirewall) FlushRules() error {
+	f.flushes++
+	return nil
+}
+
+func (f *fakeFirewall) IsPortOpen(port, protocol string) (bool, error) {
+	return f.open[port+"/"+protocol], nil
+}
+
+func TestPortsAspectTeardown(t *testing.T) {
+	g := NewWithT(t)
+	useTempAspectStateDir(t)
+	fakeFw := &fakeFirewall{open: map[string]bool{"10250/tcp": true}}
+	aspect := &portsAspect{
+		nodeConfig: &api.NodeConfig{Spec: api.NodeConfigSpec{
+			Instance: api.InstanceOptions{
+				Firewall: api.FirewallOptions{Backend: api.FirewallBackendIptables},
+			},
+			CNI: api.CNIOptions{Plugin: api.CNIPluginCalico},
+		}},
+		logger: zap.NewNop(),
+		newFirewallManager: func(backend api.FirewallBackend) (firewall.Manager, error) {
+			return fakeFw, nil
+		},
+	}
+
+	g.Expect(aspect.Setup()).To(Succeed())
+	g.Expect(fakeFw.open).To(Equal(map[string]bool{
+		"10250/tcp": true, "10256/tcp": true, "30000-32767/tcp": true, "5473/tcp": true, "4789/udp": true,
+	}))
+
+	// ports opened by nodeadm are still recorded when init runs again
+	g.Expect(aspect.Setup()).To(Succeed())
+
+	g.Expect(aspect.Teardown()).To(Succeed())
+	g.Expect(fakeFw.open).To(Equal(map[string]bool{"10250/tcp": true}))
+	g.Expect(fakeFw.flushes).To(Equal(3))
+	g.Expect(aspectStatePath(portsAspectName)).NotTo(BeAnExistingFile())
+
+	// nothing left to tear down
+	g.Expect(aspect.Teardown()).To(Succeed())
+	g.Expect(fakeFw.flushes).To(Equal(3))
+}
@@ -0,0 +1,50 @@
+package system
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"sigs.k8s.io/yaml"
+)
+
+// aspectStateDir holds what each aspect changed on the host. It's next to the
+// tracker, so it's removed with it once uninstall is done.
+var aspectStateDir = "/opt/nodeadm/aspects"
+
+const (
+	aspectStateDirPerms  = 0o755
+	aspectStateFilePerms = 0o644
+)
+
+// loadAspectState reads the state saved by the aspect into state. state is
+// left untouched if the aspect didn't save any.
+func loadAspectState(name string, state any) error {
+	data, err := os.ReadFile(aspectStatePath(name))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	return yaml.Unmarshal(data, state)
+}
+
+func saveAspectState(name string, state any) error {
+	data, err := yaml.Marshal(state)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(aspectStateDir, aspectStateDirPerms); err != nil {
+		return err
+	}
+	return os.WriteFile(aspectStatePath(name), data, aspectStateFilePerms)
+}
+
+func removeAspectState(name string) error {
+	return os.RemoveAll(aspectStatePath(name))
+}
+
+func aspectStatePath(name string) string {
+	return filepath.Join(aspectStateDir, name+".yaml")
+}
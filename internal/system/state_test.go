@@ -0,0 +1,31 @@
+package system
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func useTempAspectStateDir(t *testing.T) {
+	original := aspectStateDir
+	aspectStateDir = t.TempDir()
+	t.Cleanup(func() { aspectStateDir = original })
+}
+
+func TestAspectState(t *testing.T) {
+	g := NewWithT(t)
+	useTempAspectStateDir(t)
+
+	var state swapState
+	g.Expect(loadAspectState(swapAspectName, &state)).To(Succeed())
+	g.Expect(state).To(BeZero())
+
+	saved := swapState{MaskedUnits: []string{"dev-sda2.swap"}, Swaps: []string{"/dev/sda2", "/swapfile"}}
+	g.Expect(saveAspectState(swapAspectName, saved)).To(Succeed())
+	g.Expect(loadAspectState(swapAspectName, &state)).To(Succeed())
+	g.Expect(state).To(Equal(saved))
+
+	g.Expect(removeAspectState(swapAspectName)).To(Succeed())
+	g.Expect(aspectStatePath(swapAspectName)).NotTo(BeAnExistingFile())
+	g.Expect(removeAspectState(swapAspectName)).To(Succeed())
+}
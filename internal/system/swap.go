@@ -8,6 +8,7 @@ import (
 	"io/fs"
 	"os"
 	"os/exec"
+	"slices"
 	"strings"
 
 	"go.uber.org/zap"
@@ -21,6 +22,16 @@ const (
 	swapTypeFile      = "file"
 )
 
+var fstabPath = "/etc/fstab"
+
+// swapState records the swaps nodeadm turned off, the swap units it masked and
+// the swap entries it removed from the fstab.
+type swapState struct {
+	MaskedUnits []string `json:"maskedUnits,omitempty"`
+	Swaps       []string `json:"swaps,omitempty"`
+	FstabLines  []string `json:"fstabLines,omitempty"`
+}
+
 type swapAspect struct {
 	nodeConfig *api.NodeConfig
 	logger     *zap.Logger
@@ -37,17 +48,155 @@ func (s *swapAspect) Name() string {
 }
 
 func (s *swapAspect) Setup() error {
+	mode := s.nodeConfig.Spec.Instance.Swap.Mode
+	switch mode {
+	case api.SwapModeLimited:
+		s.logger.Info("Keeping swap on for the kubelet LimitedSwap behavior")
+		return nil
+	case "", api.SwapModeDisable, api.SwapModeDisablePartitions:
+	default:
+		return fmt.Errorf("unknown swap mode %q", mode)
+	}
+	var state swapState
+	if err := loadAspectState(swapAspectName, &state); err != nil {
+		return err
+	}
+	var err error
+	if mode == api.SwapModeDisablePartitions {
+		err = s.disableAllSwap(&state)
+	} else {
+		err = s.disableFileSwap(&state)
+	}
+	if err == nil {
+		var removed []string
+		removed, err = disableSwapOnFstab()
+		for _, line := range removed {
+			state.FstabLines = appendMissing(state.FstabLines, line)
+		}
+	}
+	// saved even when disabling failed, so teardown reverts what was done
+	if saveErr := saveAspectState(swapAspectName, state); saveErr != nil {
+		return saveErr
+	}
+	return err
+}
+
+// Teardown adds the removed swap entries back to the fstab, unmasks the swap
+// units and turns the swaps back on.
+func (s *swapAspect) Teardown() error {
+	var state swapState
+	if err := loadAspectState(swapAspectName, &state); err != nil {
+		return err
+	}
+	s.logger.Info("Restoring /etc/fstab...")
+	if err := restoreFstab(state.FstabLines); err != nil {
+		return err
+	}
+	for _, unit := range state.MaskedUnits {
+		s.logger.Info("Unmasking swap unit...", zap.String("unit", unit))
+		if out, err := exec.Command("systemctl", "unmask", unit).CombinedOutput(); err != nil {
+			return fmt.Errorf("unmasking swap unit %s, command output: %s, %w", unit, out, err)
+		}
+	}
+	active, err := getSwapfilePaths()
+	if err != nil {
+		return err
+	}
+	for _, path := range state.Swaps {
+		if slices.ContainsFunc(active, func(swap *swap) bool { return swap.filePath == path }) {
+			continue
+		}
+		s.logger.Info("Enabling swap...", zap.String("path", path))
+		if out, err := exec.Command("swapon", path).CombinedOutput(); err != nil {
+			return fmt.Errorf("failed to turn on swap on %s, command output: %s, %w", path, out, err)
+		}
+	}
+	return removeAspectState(swapAspectName)
+}
+
+func (s *swapAspect) disableFileSwap(state *swapState) error {
 	hasSwapPartition, err := partitionSwapExists()
 	if err != nil {
 		return err
 	}
 	if hasSwapPartition {
-		return fmt.Errorf("failed to disable swap: partition type swap found on the host")
+		return fmt.Errorf("failed to disable swap: partition type swap found on the host, set spec.instance.swap.mode to %s to turn it off", api.SwapModeDisablePartitions)
+	}
+	return s.swapOff(state)
+}
+
+// disableAllSwap turns off every swap, including partitions, and masks the
+// swap units so systemd doesn't activate them again on boot, even the ones
+// found by generators without an fstab entry.
+func (s *swapAspect) disableAllSwap(state *swapState) error {
+	out, err := exec.Command("systemctl", "list-units", "--type=swap", "--all", "--plain", "--no-legend").Output()
+	if err != nil {
+		return fmt.Errorf("listing swap units: %w", err)
+	}
+	for _, unit := range parseUnitNames(string(out)) {
+		s.logger.Info("Masking swap unit...", zap.String("unit", unit))
+		if out, err := exec.Command("systemctl", "mask", "--now", unit).CombinedOutput(); err != nil {
+			return fmt.Errorf("masking swap unit %s, command output: %s, %w", unit, out, err)
+		}
+		state.MaskedUnits = appendMissing(state.MaskedUnits, unit)
+	}
+	swaps, err := getSwapfilePaths()
+	if err != nil {
+		return err
+	}
+	for _, swap := range swaps {
+		state.Swaps = appendMissing(state.Swaps, swap.filePath)
+	}
+	// swaps enabled outside systemd don't have a unit
+	if out, err := exec.Command("swapoff", "--all").CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to turn off swap, command output: %s, %w", out, err)
+	}
+	return nil
+}
+
+// parseUnitNames returns the unit names, the first column of the output of
+// systemctl list-units --plain --no-legend.
+func parseUnitNames(output string) []string {
+	var units []string
+	for _, line := range strings.Split(output, "\n") {
+		if fields := strings.Fields(line); len(fields) > 0 {
+			units = append(units, fields[0])
+		}
+	}
+	return units
+}
+
+// appendMissing appends value to values unless it's already there.
+func appendMissing(values []string, value string) []string {
+	if slices.Contains(values, value) {
+		return values
 	}
-	if err = s.swapOff(); err != nil {
+	return append(values, value)
+}
+
+// restoreFstab appends the swap entries nodeadm removed back to the fstab,
+// keeping the changes made to it since. Entries already in it are skipped.
+func restoreFstab(lines []string) error {
+	if len(lines) == 0 {
+		return nil
+	}
+	content, err := os.ReadFile(fstabPath)
+	if err != nil {
 		return err
 	}
-	return disableSwapOnFstab()
+	existing := strings.Split(string(content), "\n")
+	var buf bytes.Buffer
+	buf.Write(content)
+	if len(content) > 0 && !bytes.HasSuffix(content, []byte("\n")) {
+		buf.WriteString("\n")
+	}
+	for _, line := range lines {
+		if slices.Contains(existing, line) {
+			continue
+		}
+		buf.WriteString(line + "\n")
+	}
+	return os.WriteFile(fstabPath, buf.Bytes(), 0o644)
 }
 
 // Check if there are swaps of type partition exist on host because currently
@@ -68,7 +217,7 @@ func partitionSwapExists() (bool, error) {
 	return false, nil
 }
 
-func (s *swapAspect) swapOff() error {
+func (s *swapAspect) swapOff(state *swapState) error {
 	swapfiles, err := getSwapfilePaths()
 	if err != nil {
 		return err
@@ -85,6 +234,7 @@ func (s *swapAspect) swapOff() error {
 			if err != nil {
 				return fmt.Errorf("failed to turn of swap on %s, command output: %s, %v", path, out, err)
 			}
+			state.Swaps = appendMissing(state.Swaps, path)
 		} else if errors.Is(err, fs.ErrNotExist) {
 			// path to swapfile does not exist
 			s.logger.Warn("swapfile path does not exists", zap.Reflect("swapfile path", path))
@@ -146,10 +296,11 @@ type mount struct {
 	vfsType string
 }
 
-func disableSwapOnFstab() error {
-	file, err := os.OpenFile("/etc/fstab", os.O_RDWR, 0o644)
+// disableSwapOnFstab removes the swap entries from the fstab and returns them.
+func disableSwapOnFstab() ([]string, error) {
+	file, err := os.OpenFile(fstabPath, os.O_RDWR, 0o644)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	defer file.Close()
 
@@ -157,25 +308,33 @@ func disableSwapOnFstab() error {
 	buf := bytes.NewBuffer(bs)
 	scanner := bufio.NewScanner(file)
 	lineNo := 0
+	var removed []string
 
 	for scanner.Scan() {
 		lineNo++
 		fstabMount, err := parseFstabLine(scanner.Text())
 		if err != nil {
-			return fmt.Errorf("/etc/fstab syntax error at line %d: %s", lineNo, err)
+			return nil, fmt.Errorf("/etc/fstab syntax error at line %d: %s", lineNo, err)
 		}
 		if fstabMount == nil || fstabMount != nil && fstabMount.vfsType != "swap" {
 			buf.WriteString(scanner.Text() + "\n")
+		} else {
+			removed = append(removed, scanner.Text())
 		}
 	}
+	if len(removed) == 0 {
+		return nil, nil
+	}
 	if err := file.Truncate(0); err != nil {
-		return err
+		return nil, err
 	}
 	if _, err := file.Seek(0, 0); err != nil {
-		return err
+		return nil, err
 	}
-	_, err = buf.WriteTo(file)
-	return err
+	if _, err := buf.WriteTo(file); err != nil {
+		return nil, err
+	}
+	return removed, nil
 }
 
 func parseFstabLine(line string) (*mount, error) {
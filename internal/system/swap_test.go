@@ -0,0 +1,52 @@
+package system
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestDisableSwapOnFstabRestore(t *testing.T) {
+	g := NewWithT(t)
+	tmp := t.TempDir()
+	originalFstab := fstabPath
+	fstabPath = filepath.Join(tmp, "fstab")
+	t.Cleanup(func() {
+		fstabPath = originalFstab
+	})
+
+	fstab := "# comment\nUUID=1234 / xfs defaults 0 0\n/swapfile none swap sw 0 0\n"
+	g.Expect(os.WriteFile(fstabPath, []byte(fstab), 0o644)).To(Succeed())
+
+	removed, err := disableSwapOnFstab()
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(removed).To(Equal([]string{"/swapfile none swap sw 0 0"}))
+	content, err := os.ReadFile(fstabPath)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(string(content)).To(Equal("# comment\nUUID=1234 / xfs defaults 0 0\n"))
+
+	// nothing left to remove
+	g.Expect(disableSwapOnFstab()).To(BeEmpty())
+
+	// entries added after init are kept
+	g.Expect(os.WriteFile(fstabPath, []byte("# comment\nUUID=1234 / xfs defaults 0 0\nUUID=5678 /mnt/data xfs defaults 0 0"), 0o644)).To(Succeed())
+	g.Expect(restoreFstab(removed)).To(Succeed())
+	content, err = os.ReadFile(fstabPath)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(string(content)).To(Equal("# comment\nUUID=1234 / xfs defaults 0 0\nUUID=5678 /mnt/data xfs defaults 0 0\n/swapfile none swap sw 0 0\n"))
+
+	// entries already back aren't added twice
+	g.Expect(restoreFstab(removed)).To(Succeed())
+	content, err = os.ReadFile(fstabPath)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(string(content)).To(Equal("# comment\nUUID=1234 / xfs defaults 0 0\nUUID=5678 /mnt/data xfs defaults 0 0\n/swapfile none swap sw 0 0\n"))
+}
+
+func TestParseUnitNames(t *testing.T) {
+	g := NewWithT(t)
+	output := "dev-sda2.swap loaded active active /dev/sda2\nswapfile.swap loaded inactive dead /swapfile\n\n"
+	g.Expect(parseUnitNames(output)).To(Equal([]string{"dev-sda2.swap", "swapfile.swap"}))
+	g.Expect(parseUnitNames("")).To(BeEmpty())
+}
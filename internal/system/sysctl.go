@@ -1,10 +1,18 @@
 package system
 
 import (
+	"bufio"
 	_ "embed"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"os"
 	"os/exec"
 	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
 
 	"github.com/aws/eks-hybrid/internal/api"
 	"github.com/aws/eks-hybrid/internal/util"
@@ -15,14 +23,41 @@ const (
 	sysctlConfDir         = "/etc/sysctl.d"
 	nodeadmSysctlConfFile = "99-nodeadm.conf"
 	nodeadmSysctlFilePerm = 0o644
+	ipv6ForwardingSysctl  = "net.ipv6.conf.all.forwarding=1"
 )
 
 var (
 	//go:embed _assets/99-sysctl.conf
 	sysctlConfFileData    string
 	nodeadmSysctlConfPath = path.Join(sysctlConfDir, nodeadmSysctlConfFile)
+
+	procSysDir = "/proc/sys"
+
+	sysctlKeyRegex = regexp.MustCompile(`^[a-zA-Z0-9_]+([./][a-zA-Z0-9_*-]+)+$`)
+
+	// kubeletProtectedSysctls are the values kubelet requires on start when
+	// protectKernelDefaults is enabled, which nodeadm does by default.
+	kubeletProtectedSysctls = map[string]string{
+		"vm.overcommit_memory":      "1",
+		"vm.panic_on_oom":           "0",
+		"kernel.panic":              "10",
+		"kernel.panic_on_oops":      "1",
+		"kernel.keys.root_maxkeys":  "1000000",
+		"kernel.keys.root_maxbytes": "25000000",
+	}
 )
 
+type sysctlSetting struct {
+	key   string
+	value string
+}
+
+// sysctlState records the values of the settings from before nodeadm first
+// set them, keyed as written in the config.
+type sysctlState struct {
+	Previous map[string]string `json:"previous,omitempty"`
+}
+
 type sysctlAspect struct {
 	nodeConfig *api.NodeConfig
 }
@@ -38,14 +73,160 @@ func (s *sysctlAspect) Name() string {
 }
 
 func (s *sysctlAspect) Setup() error {
-	if err := writeSysctlConfig(); err != nil {
+	settings := sysctlSettings(s.nodeConfig)
+	if err := recordPreviousSysctls(settings); err != nil {
+		return err
+	}
+	if err := writeSysctlConfig(settings); err != nil {
+		return err
+	}
+	if err := loadKernelModules(s.nodeConfig.Spec.Instance.KernelModules); err != nil {
+		return err
+	}
+	if err := reloadSysctl(); err != nil {
+		return err
+	}
+	return verifySysctls(settings)
+}
+
+// Teardown removes the sysctl config and puts back the values from before
+// init. The config files are then applied again, so the host gets the values
+// they set even if they changed since init.
+func (s *sysctlAspect) Teardown() error {
+	var state sysctlState
+	if err := loadAspectState(sysctlAspectName, &state); err != nil {
+		return err
+	}
+	if err := removeSysctlConfig(); err != nil {
+		return err
+	}
+	var errs []error
+	for key, value := range state.Previous {
+		if err := os.WriteFile(sysctlPath(key), []byte(value), nodeadmSysctlFilePerm); err != nil {
+			errs = append(errs, fmt.Errorf("restoring sysctl %s: %w", key, err))
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		return err
+	}
+	if err := reloadSysctl(); err != nil {
 		return err
 	}
-	return reloadSysctl()
+	return removeAspectState(sysctlAspectName)
 }
 
-func writeSysctlConfig() error {
-	return util.WriteFileWithDir(nodeadmSysctlConfPath, []byte(sysctlConfFileData), nodeadmSysctlFilePerm)
+// ValidateKernelSettings checks the sysctls and kernel modules of the node
+// config.
+func ValidateKernelSettings(cfg *api.NodeConfig) error {
+	protectKernelDefaults, err := kubeletProtectsKernelDefaults(cfg)
+	if err != nil {
+		return err
+	}
+	for key, value := range cfg.Spec.Instance.Sysctls {
+		if !sysctlKeyRegex.MatchString(key) {
+			return fmt.Errorf("invalid sysctl key %q in spec.instance.sysctls", key)
+		}
+		if value == "" || strings.ContainsAny(value, "\n\r") {
+			return fmt.Errorf("invalid value %q for sysctl %s in spec.instance.sysctls", value, key)
+		}
+		if required, ok := kubeletProtectedSysctls[sysctlName(key)]; ok && protectKernelDefaults && normalizeSysctlValue(value) != required {
+			return fmt.Errorf("sysctl %s=%s in spec.instance.sysctls conflicts with kubelet protectKernelDefaults, which requires %s", key, value, required)
+		}
+	}
+	return validateKernelModules(cfg.Spec.Instance.KernelModules)
+}
+
+// kubeletProtectsKernelDefaults returns the protectKernelDefaults of the
+// kubelet config, nodeadm enables it unless the user config turns it off.
+func kubeletProtectsKernelDefaults(cfg *api.NodeConfig) (bool, error) {
+	raw, ok := cfg.Spec.Kubelet.Config["protectKernelDefaults"]
+	if !ok {
+		return true, nil
+	}
+	var protect bool
+	if err := json.Unmarshal(raw.Raw, &protect); err != nil {
+		return false, fmt.Errorf("invalid protectKernelDefaults in kubelet config: %w", err)
+	}
+	return protect, nil
+}
+
+// sysctlSettings returns the nodeadm defaults, overridden by the node config
+// sysctls, followed by the other node config sysctls sorted by key.
+func sysctlSettings(cfg *api.NodeConfig) []sysctlSetting {
+	var settings []sysctlSetting
+	userSysctls := map[string]sysctlSetting{}
+	for key, value := range cfg.Spec.Instance.Sysctls {
+		userSysctls[sysctlName(key)] = sysctlSetting{key: strings.TrimSpace(key), value: value}
+	}
+	defaults := sysctlConfFileData
+	if cfg.HasIPv6() {
+		// kube-proxy and the CNI route IPv6 pod traffic like IPv4 traffic
+		defaults += "\n" + ipv6ForwardingSysctl
+	}
+	scanner := bufio.NewScanner(strings.NewReader(defaults))
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(scanner.Text(), "=")
+		if !ok {
+			continue
+		}
+		setting := sysctlSetting{key: strings.TrimSpace(key), value: strings.TrimSpace(value)}
+		if userSetting, ok := userSysctls[sysctlName(key)]; ok {
+			setting = userSetting
+			delete(userSysctls, sysctlName(key))
+		}
+		settings = append(settings, setting)
+	}
+	var names []string
+	for name := range userSysctls {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		settings = append(settings, userSysctls[name])
+	}
+	return settings
+}
+
+func generateSysctlConfig(settings []sysctlSetting) []byte {
+	var b strings.Builder
+	for _, setting := range settings {
+		fmt.Fprintf(&b, "%s=%s\n", setting.key, setting.value)
+	}
+	return []byte(b.String())
+}
+
+func writeSysctlConfig(settings []sysctlSetting) error {
+	return util.WriteFileWithDir(nodeadmSysctlConfPath, generateSysctlConfig(settings), nodeadmSysctlFilePerm)
+}
+
+// recordPreviousSysctls saves the current values of the settings nodeadm
+// didn't set before. Settings that don't exist yet, such as the ones of kernel
+// modules nodeadm loads, have nothing to restore.
+func recordPreviousSysctls(settings []sysctlSetting) error {
+	var state sysctlState
+	if err := loadAspectState(sysctlAspectName, &state); err != nil {
+		return err
+	}
+	if state.Previous == nil {
+		state.Previous = map[string]string{}
+	}
+	for _, setting := range settings {
+		if _, ok := state.Previous[setting.key]; ok || strings.Contains(setting.key, "*") {
+			continue
+		}
+		current, err := os.ReadFile(sysctlPath(setting.key))
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		} else if err != nil {
+			return fmt.Errorf("reading sysctl %s: %w", setting.key, err)
+		}
+		state.Previous[setting.key] = normalizeSysctlValue(string(current))
+	}
+	return saveAspectState(sysctlAspectName, state)
+}
+
+func removeSysctlConfig() error {
+	return os.RemoveAll(nodeadmSysctlConfPath)
 }
 
 func reloadSysctl() error {
@@ -56,3 +237,49 @@ func reloadSysctl() error {
 	}
 	return nil
 }
+
+// verifySysctls checks the kernel has the values of the settings. They can
+// differ when a config file sorted after nodeadm's sets the same key, or when
+// the key belongs to a kernel module that is not loaded.
+func verifySysctls(settings []sysctlSetting) error {
+	var errs []error
+	for _, setting := range settings {
+		// patterns match several keys, there is no single value to check
+		if strings.Contains(setting.key, "*") {
+			continue
+		}
+		current, err := os.ReadFile(sysctlPath(setting.key))
+		if errors.Is(err, os.ErrNotExist) {
+			errs = append(errs, fmt.Errorf("sysctl %s does not exist, if it belongs to a kernel module add it to spec.instance.kernelModules", setting.key))
+			continue
+		} else if err != nil {
+			errs = append(errs, fmt.Errorf("reading sysctl %s: %w", setting.key, err))
+			continue
+		}
+		if normalizeSysctlValue(string(current)) != normalizeSysctlValue(setting.value) {
+			errs = append(errs, fmt.Errorf("sysctl %s is %q instead of %q, check for other files in %s overriding it", setting.key, strings.TrimSpace(string(current)), setting.value, sysctlConfDir))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// sysctlName returns the key with dots as separators, to compare keys written
+// with either separator.
+func sysctlName(key string) string {
+	return strings.ReplaceAll(strings.TrimSpace(key), "/", ".")
+}
+
+// sysctlPath returns the file of the key in /proc/sys. In keys written with
+// slashes, dots are part of a name, such as a VLAN interface.
+func sysctlPath(key string) string {
+	if strings.Contains(key, "/") {
+		return filepath.Join(procSysDir, key)
+	}
+	return filepath.Join(procSysDir, strings.ReplaceAll(key, ".", "/"))
+}
+
+// normalizeSysctlValue collapses whitespace, since multi-value settings are
+// reported with tabs.
+func normalizeSysctlValue(value string) string {
+	return strings.Join(strings.Fields(value), " ")
+}
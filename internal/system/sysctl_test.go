@@ -0,0 +1,183 @@
+package system
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/aws/eks-hybrid/internal/api"
+)
+
+func TestSysctlSettings(t *testing.T) {
+	g := NewWithT(t)
+	settings := sysctlSettings(&api.NodeConfig{
+		Spec: api.NodeConfigSpec{
+			Instance: api.InstanceOptions{
+				Sysctls: map[string]string{
+					"net.core.somaxconn":               "4096",
+					"net/ipv4/ip_forward":              "1",
+					"fs.inotify.max_user_instances":    "8192",
+					"net.ipv4.conf.eth0/100.rp_filter": "0",
+				},
+			},
+		},
+	})
+	g.Expect(string(generateSysctlConfig(settings))).To(Equal(`vm.overcommit_memory=1
+kernel.panic=10
+kernel.panic_on_oops=1
+net/ipv4/ip_forward=1
+fs.inotify.max_user_instances=8192
+net.core.somaxconn=4096
+net.ipv4.conf.eth0/100.rp_filter=0
+`))
+}
+
+func TestSysctlSettingsIPv6(t *testing.T) {
+	g := NewWithT(t)
+	cfg := &api.NodeConfig{
+		Spec: api.NodeConfigSpec{
+			Cluster: api.ClusterDetails{CIDR: "fd00::/108"},
+		},
+	}
+	g.Expect(string(generateSysctlConfig(sysctlSettings(cfg)))).To(Equal(`vm.overcommit_memory=1
+kernel.panic=10
+kernel.panic_on_oops=1
+net.ipv4.ip_forward=1
+net.ipv6.conf.all.forwarding=1
+`))
+
+	cfg.Spec.Instance.Sysctls = map[string]string{"net/ipv6/conf/all/forwarding": "0"}
+	g.Expect(string(generateSysctlConfig(sysctlSettings(cfg)))).To(Equal(`vm.overcommit_memory=1
+kernel.panic=10
+kernel.panic_on_oops=1
+net.ipv4.ip_forward=1
+net/ipv6/conf/all/forwarding=0
+`))
+}
+
+func TestValidateKernelSettings(t *testing.T) {
+	testCases := []struct {
+		name          string
+		sysctls       map[string]string
+		modules       []string
+		kubeletConfig api.InlineDocument
+		wantErr       string
+	}{
+		{
+			name:    "valid",
+			sysctls: map[string]string{"net.core.somaxconn": "4096", "net/ipv4/conf/eth0.100/rp_filter": "0", "kernel.panic": "10"},
+			modules: []string{"ip_vs", "ip_vs_rr", "nf-conntrack"},
+		},
+		{
+			name:    "invalid key",
+			sysctls: map[string]string{"net.core.somaxconn = 1": "4096"},
+			wantErr: `invalid sysctl key "net.core.somaxconn = 1" in spec.instance.sysctls`,
+		},
+		{
+			name:    "empty value",
+			sysctls: map[string]string{"net.core.somaxconn": ""},
+			wantErr: `invalid value "" for sysctl net.core.somaxconn in spec.instance.sysctls`,
+		},
+		{
+			name:    "protected kernel default",
+			sysctls: map[string]string{"vm.overcommit_memory": "0"},
+			wantErr: "sysctl vm.overcommit_memory=0 in spec.instance.sysctls conflicts with kubelet protectKernelDefaults, which requires 1",
+		},
+		{
+			name:          "protected kernel default disabled",
+			sysctls:       map[string]string{"vm.overcommit_memory": "0"},
+			kubeletConfig: api.InlineDocument{"protectKernelDefaults": runtime.RawExtension{Raw: []byte("false")}},
+		},
+		{
+			name:    "invalid module",
+			modules: []string{"ip_vs rr"},
+			wantErr: `invalid kernel module name "ip_vs rr" in spec.instance.kernelModules`,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			err := ValidateKernelSettings(&api.NodeConfig{
+				Spec: api.NodeConfigSpec{
+					Instance: api.InstanceOptions{Sysctls: tc.sysctls, KernelModules: tc.modules},
+					Kubelet:  api.KubeletOptions{Config: tc.kubeletConfig},
+				},
+			})
+			if tc.wantErr == "" {
+				g.Expect(err).NotTo(HaveOccurred())
+			} else {
+				g.Expect(err).To(MatchError(tc.wantErr))
+			}
+		})
+	}
+}
+
+func TestVerifySysctls(t *testing.T) {
+	g := NewWithT(t)
+	originalProcSysDir := procSysDir
+	procSysDir = t.TempDir()
+	t.Cleanup(func() { procSysDir = originalProcSysDir })
+	for path, value := range map[string]string{
+		"net/core/somaxconn":               "4096\n",
+		"net/ipv4/ip_local_port_range":     "1024\t65000\n",
+		"net/ipv4/conf/eth0.100/rp_filter": "1\n",
+	} {
+		g.Expect(os.MkdirAll(filepath.Join(procSysDir, filepath.Dir(path)), 0o755)).To(Succeed())
+		g.Expect(os.WriteFile(filepath.Join(procSysDir, path), []byte(value), 0o644)).To(Succeed())
+	}
+
+	g.Expect(verifySysctls([]sysctlSetting{
+		{key: "net.core.somaxconn", value: "4096"},
+		{key: "net.ipv4.ip_local_port_range", value: "1024 65000"},
+		{key: "net.ipv4.conf.*.rp_filter", value: "0"},
+	})).To(Succeed())
+
+	err := verifySysctls([]sysctlSetting{
+		{key: "net/ipv4/conf/eth0.100/rp_filter", value: "0"},
+		{key: "net.ipv4.vs.conntrack", value: "1"},
+	})
+	g.Expect(err).To(MatchError(ContainSubstring(`sysctl net/ipv4/conf/eth0.100/rp_filter is "1" instead of "0"`)))
+	g.Expect(err).To(MatchError(ContainSubstring("sysctl net.ipv4.vs.conntrack does not exist")))
+}
+
+func TestKernelModulesConfig(t *testing.T) {
+	g := NewWithT(t)
+	config := KernelModulesConfig("overlay\nbr_netfilter", &api.NodeConfig{
+		Spec: api.NodeConfigSpec{
+			Instance: api.InstanceOptions{KernelModules: []string{"ip_vs", "overlay", "ip_vs_rr"}},
+		},
+	})
+	g.Expect(string(config)).To(Equal("overlay\nbr_netfilter\nip_vs\nip_vs_rr\n"))
+
+	config = KernelModulesConfig("overlay", &api.NodeConfig{
+		Spec: api.NodeConfigSpec{CNI: api.CNIOptions{Plugin: api.CNIPluginCalico}},
+	})
+	g.Expect(string(config)).To(Equal("overlay\nip_set\nxt_set\n"))
+}
+
+func TestRecordPreviousSysctls(t *testing.T) {
+	g := NewWithT(t)
+	useTempAspectStateDir(t)
+	originalProcSysDir := procSysDir
+	procSysDir = t.TempDir()
+	t.Cleanup(func() { procSysDir = originalProcSysDir })
+	g.Expect(os.MkdirAll(filepath.Join(procSysDir, "net/core"), 0o755)).To(Succeed())
+	g.Expect(os.WriteFile(filepath.Join(procSysDir, "net/core/somaxconn"), []byte("128\n"), 0o644)).To(Succeed())
+
+	g.Expect(recordPreviousSysctls([]sysctlSetting{
+		{key: "net.core.somaxconn", value: "4096"},
+		{key: "net.ipv4.conf.*.rp_filter", value: "0"},
+		{key: "net.ipv4.vs.conntrack", value: "1"},
+	})).To(Succeed())
+
+	// the value set by nodeadm is not recorded on the next init
+	g.Expect(os.WriteFile(filepath.Join(procSysDir, "net/core/somaxconn"), []byte("4096\n"), 0o644)).To(Succeed())
+	g.Expect(recordPreviousSysctls([]sysctlSetting{{key: "net.core.somaxconn", value: "4096"}})).To(Succeed())
+
+	var state sysctlState
+	g.Expect(loadAspectState(sysctlAspectName, &state)).To(Succeed())
+	g.Expect(state.Previous).To(Equal(map[string]string{"net.core.somaxconn": "128"}))
+}
@@ -0,0 +1,41 @@
+package system
+
+import (
+	"go.uber.org/zap"
+
+	"github.com/aws/eks-hybrid/internal/api"
+	"github.com/aws/eks-hybrid/internal/trust"
+)
+
+const trustAspectName = "trust"
+
+type trustAspect struct {
+	nodeConfig *api.NodeConfig
+	logger     *zap.Logger
+}
+
+var _ SystemAspect = &trustAspect{}
+
+// NewTrustAspect installs the node's additional CA bundles in the operating
+// system trust store.
+func NewTrustAspect(cfg *api.NodeConfig, logger *zap.Logger) SystemAspect {
+	return &trustAspect{nodeConfig: cfg, logger: logger}
+}
+
+func (t *trustAspect) Name() string {
+	return trustAspectName
+}
+
+func (t *trustAspect) Setup() error {
+	if len(t.nodeConfig.Spec.Trust.AdditionalCABundles) > 0 {
+		t.logger.Info("Installing additional CA bundles in the system trust store...")
+	}
+	return trust.Install(t.nodeConfig)
+}
+
+// Teardown removes the CA bundles nodeadm installed. The trust store only
+// keeps track of its own anchors, so there is no state to record.
+func (t *trustAspect) Teardown() error {
+	t.logger.Info("Removing additional CA bundles...")
+	return trust.Uninstall()
+}
@@ -17,10 +17,14 @@ const trackerFile = "/opt/nodeadm/tracker"
 
 type Tracker struct {
 	Artifacts *InstalledArtifacts
+	// DataDirs are the relocated data directories configured on the node
+	DataDirs []string `json:",omitempty"`
 }
 
 type InstalledArtifacts struct {
 	Containerd              string
+	Runtime                 string
+	Crio                    bool
 	CniPlugins              bool
 	IamAuthenticator        bool
 	IamRolesAnywhere        bool
@@ -29,6 +33,9 @@ type InstalledArtifacts struct {
 	Kubelet                 bool
 	Ssm                     bool
 	Iptables                bool
+	Crictl                  bool
+	Nerdctl                 bool
+	Gvisor                  string
 }
 
 // Add adds a components as installed to the tracker
@@ -50,6 +57,12 @@ func (tracker *Tracker) Add(componentName string) error {
 		tracker.Artifacts.Ssm = true
 	case artifact.Iptables:
 		tracker.Artifacts.Iptables = true
+	case artifact.Crictl:
+		tracker.Artifacts.Crictl = true
+	case artifact.Nerdctl:
+		tracker.Artifacts.Nerdctl = true
+	case artifact.Crio:
+		tracker.Artifacts.Crio = true
 	default:
 		return fmt.Errorf("invalid artifact to track")
 	}
@@ -60,6 +73,21 @@ func (tracker *Tracker) MarkContainerd(source string) {
 	tracker.Artifacts.Containerd = source
 }
 
+// MarkGvisor records gVisor as installed from the release at url
+func (tracker *Tracker) MarkGvisor(url string) {
+	tracker.Artifacts.Gvisor = url
+}
+
+// MarkRuntime records the container runtime of the node
+func (tracker *Tracker) MarkRuntime(runtime string) {
+	tracker.Artifacts.Runtime = runtime
+}
+
+// MarkDataDirs records the relocated data directories of the node
+func (tracker *Tracker) MarkDataDirs(dirs []string) {
+	tracker.DataDirs = dirs
+}
+
 // Save() saves the tracker to file
 func (tracker *Tracker) Save() error {
 	data, err := yaml.Marshal(tracker)
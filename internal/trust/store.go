@@ -0,0 +1,108 @@
+package trust
+
+import (
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/aws/eks-hybrid/internal/api"
+)
+
+const (
+	anchorPrefix = "eks-hybrid-"
+	anchorPerm   = 0o644
+)
+
+// systemStore is an operating system trust store that reads additional anchors
+// from a directory and is refreshed by running a command.
+type systemStore struct {
+	anchorDir string
+	update    []string
+}
+
+var systemStores = []systemStore{
+	// Debian and Ubuntu
+	{anchorDir: "/usr/local/share/ca-certificates", update: []string{"update-ca-certificates"}},
+	// RHEL and Amazon Linux
+	{anchorDir: "/etc/pki/ca-trust/source/anchors", update: []string{"update-ca-trust", "extract"}},
+}
+
+var errNoSystemStore = errors.New("no supported CA trust store found, update-ca-certificates or update-ca-trust is required")
+
+func findSystemStore() (systemStore, error) {
+	for _, store := range systemStores {
+		if _, err := exec.LookPath(store.update[0]); err == nil {
+			return store, nil
+		}
+	}
+	return systemStore{}, errNoSystemStore
+}
+
+// Install writes the node's additional CA bundles to BundlePath and installs
+// them in the operating system trust store. Certificates installed by a previous
+// run that are no longer configured are removed.
+func Install(cfg *api.NodeConfig) error {
+	certs, err := Certificates(cfg)
+	if err != nil {
+		return err
+	}
+	if len(certs) == 0 {
+		return Uninstall()
+	}
+	if err := writeBundle(certs); err != nil {
+		return err
+	}
+	store, err := findSystemStore()
+	if err != nil {
+		return err
+	}
+	return store.install(certs)
+}
+
+// Uninstall removes the CA bundle and the certificates installed in the
+// operating system trust store.
+func Uninstall() error {
+	if err := os.RemoveAll(bundleDir); err != nil {
+		return fmt.Errorf("removing CA bundle: %w", err)
+	}
+	store, err := findSystemStore()
+	if errors.Is(err, errNoSystemStore) {
+		return nil
+	}
+	return store.install(nil)
+}
+
+// install replaces the anchors managed by nodeadm with certs and refreshes the
+// store. The store is not refreshed if there was nothing to add or remove.
+func (s systemStore) install(certs []*x509.Certificate) error {
+	existing, err := filepath.Glob(filepath.Join(s.anchorDir, anchorPrefix+"*.crt"))
+	if err != nil {
+		return err
+	}
+	if len(existing) == 0 && len(certs) == 0 {
+		return nil
+	}
+	for _, anchor := range existing {
+		if err := os.Remove(anchor); err != nil {
+			return fmt.Errorf("removing CA certificate from trust store: %w", err)
+		}
+	}
+	if len(certs) > 0 {
+		if err := os.MkdirAll(s.anchorDir, 0o755); err != nil {
+			return err
+		}
+	}
+	for i, cert := range certs {
+		anchor := filepath.Join(s.anchorDir, fmt.Sprintf("%s%d.crt", anchorPrefix, i))
+		if err := os.WriteFile(anchor, Bundle([]*x509.Certificate{cert}), anchorPerm); err != nil {
+			return fmt.Errorf("adding CA certificate to trust store: %w", err)
+		}
+	}
+	if out, err := exec.Command(s.update[0], s.update[1:]...).CombinedOutput(); err != nil {
+		return fmt.Errorf("running %s: %w, output: %s", s.update[0], err, out)
+	}
+	return nil
+}
@@ -0,0 +1,166 @@
+package trust
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
+	"github.com/aws/aws-sdk-go-v2/config"
+
+	"github.com/aws/eks-hybrid/internal/api"
+)
+
+const (
+	bundleDir = "/etc/eks/trust"
+	// BundlePath is the file where nodeadm writes the additional CA bundles
+	// configured in spec.trust.
+	BundlePath = bundleDir + "/ca-bundle.crt"
+	bundlePerm = 0o644
+
+	sslCertDirEnv = "SSL_CERT_DIR"
+)
+
+// defaultCertDirs are the directories Go reads system certificates from on Linux
+// when SSL_CERT_DIR is not set.
+var defaultCertDirs = []string{"/etc/ssl/certs", "/etc/pki/tls/certs"}
+
+// rootCAs are the system certificates and the additional CA bundles trusted by
+// Configure, nil until it trusts any.
+var rootCAs *x509.CertPool
+
+// Certificates parses the additional CA bundles of the node config.
+func Certificates(cfg *api.NodeConfig) ([]*x509.Certificate, error) {
+	var certs []*x509.Certificate
+	for i, bundle := range cfg.Spec.Trust.AdditionalCABundles {
+		bundleCerts, err := parseBundle([]byte(bundle))
+		if err != nil {
+			return nil, fmt.Errorf("invalid additional CA bundle %d: %w", i, err)
+		}
+		certs = append(certs, bundleCerts...)
+	}
+	return certs, nil
+}
+
+func parseBundle(bundle []byte) ([]*x509.Certificate, error) {
+	var certs []*x509.Certificate
+	for {
+		var block *pem.Block
+		block, bundle = pem.Decode(bundle)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		certs = append(certs, cert)
+	}
+	if len(certs) == 0 {
+		return nil, fmt.Errorf("no PEM-encoded certificates found")
+	}
+	return certs, nil
+}
+
+// Bundle returns the PEM encoding of certs.
+func Bundle(certs []*x509.Certificate) []byte {
+	var buf bytes.Buffer
+	for _, cert := range certs {
+		_ = pem.Encode(&buf, &pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw})
+	}
+	return buf.Bytes()
+}
+
+// Configure makes nodeadm, and any process it executes, trust the node's
+// additional CA bundles. The bundles are written to BundlePath and added to the
+// root CAs of the default HTTP client and of the AWS SDK clients loaded with
+// WithRootCAs. Go reads the system certificates once per process, so the
+// directory of BundlePath is only added to SSL_CERT_DIR for child processes.
+func Configure(cfg *api.NodeConfig) error {
+	if len(cfg.Spec.Trust.AdditionalCABundles) == 0 {
+		return nil
+	}
+	certs, err := Certificates(cfg)
+	if err != nil {
+		return err
+	}
+	if err := writeBundle(certs); err != nil {
+		return err
+	}
+	trustCertificates(certs)
+
+	certDirs := defaultCertDirs
+	if dirs := os.Getenv(sslCertDirEnv); dirs != "" {
+		certDirs = filepath.SplitList(dirs)
+	}
+	if slices.Contains(certDirs, bundleDir) {
+		return nil
+	}
+	certDirs = append([]string{bundleDir}, certDirs...)
+	return os.Setenv(sslCertDirEnv, strings.Join(certDirs, string(filepath.ListSeparator)))
+}
+
+// RootCAs returns the certificates trusted by nodeadm, or nil when Configure
+// didn't add any to the system ones.
+func RootCAs() *x509.CertPool {
+	return rootCAs
+}
+
+// WithRootCAs is an AWS SDK config load option that makes the clients trust the
+// additional CA bundles added by Configure.
+func WithRootCAs(o *config.LoadOptions) error {
+	pool := RootCAs()
+	if pool == nil {
+		return nil
+	}
+	o.HTTPClient = awshttp.NewBuildableClient().WithTransportOptions(func(transport *http.Transport) {
+		transport.TLSClientConfig = tlsConfigWithRootCAs(transport.TLSClientConfig, pool)
+	})
+	return nil
+}
+
+// trustCertificates adds certs to the system certificates trusted by nodeadm
+// and by the default HTTP transport.
+func trustCertificates(certs []*x509.Certificate) {
+	pool, err := x509.SystemCertPool()
+	if err != nil {
+		pool = x509.NewCertPool()
+	}
+	for _, cert := range certs {
+		pool.AddCert(cert)
+	}
+	rootCAs = pool
+	if transport, ok := http.DefaultTransport.(*http.Transport); ok {
+		transport.TLSClientConfig = tlsConfigWithRootCAs(transport.TLSClientConfig, pool)
+	}
+}
+
+func tlsConfigWithRootCAs(tlsConfig *tls.Config, pool *x509.CertPool) *tls.Config {
+	if tlsConfig == nil {
+		tlsConfig = &tls.Config{}
+	} else {
+		tlsConfig = tlsConfig.Clone()
+	}
+	tlsConfig.RootCAs = pool
+	return tlsConfig
+}
+
+func writeBundle(certs []*x509.Certificate) error {
+	if err := os.MkdirAll(bundleDir, 0o755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(BundlePath, Bundle(certs), bundlePerm); err != nil {
+		return fmt.Errorf("writing CA bundle: %w", err)
+	}
+	return nil
+}
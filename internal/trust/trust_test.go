@@ -0,0 +1,165 @@
+package trust
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/config"
+	. "github.com/onsi/gomega"
+
+	"github.com/aws/eks-hybrid/internal/api"
+)
+
+func TestCertificates(t *testing.T) {
+	g := NewWithT(t)
+	ca1, ca2, ca3 := generateCA(g, "ca-1"), generateCA(g, "ca-2"), generateCA(g, "ca-3")
+
+	testCases := []struct {
+		name      string
+		bundles   []string
+		wantNames []string
+		wantErr   string
+	}{
+		{
+			name: "no bundles",
+		},
+		{
+			name:      "multiple bundles",
+			bundles:   []string{ca1, ca2 + ca3},
+			wantNames: []string{"ca-1", "ca-2", "ca-3"},
+		},
+		{
+			name:    "no certificates",
+			bundles: []string{ca1, "not a certificate"},
+			wantErr: "invalid additional CA bundle 1: no PEM-encoded certificates found",
+		},
+		{
+			name:    "invalid certificate",
+			bundles: []string{string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: []byte("invalid")}))},
+			wantErr: "invalid additional CA bundle 0",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			certs, err := Certificates(&api.NodeConfig{
+				Spec: api.NodeConfigSpec{
+					Trust: api.TrustOptions{AdditionalCABundles: tc.bundles},
+				},
+			})
+			if tc.wantErr != "" {
+				g.Expect(err).To(MatchError(ContainSubstring(tc.wantErr)))
+				return
+			}
+			g.Expect(err).To(Succeed())
+			var names []string
+			for _, cert := range certs {
+				names = append(names, cert.Subject.CommonName)
+			}
+			g.Expect(names).To(Equal(tc.wantNames))
+		})
+	}
+}
+
+func TestSystemStoreInstall(t *testing.T) {
+	g := NewWithT(t)
+	dir := t.TempDir()
+	store := systemStore{
+		anchorDir: dir,
+		update:    []string{"touch", filepath.Join(dir, "refreshed")},
+	}
+	userAnchor := filepath.Join(dir, "corp.crt")
+	g.Expect(os.WriteFile(userAnchor, []byte("user"), 0o644)).To(Succeed())
+
+	certs, err := parseBundle([]byte(generateCA(g, "ca-1") + generateCA(g, "ca-2")))
+	g.Expect(err).To(Succeed())
+
+	g.Expect(store.install(certs)).To(Succeed())
+	g.Expect(filepath.Join(dir, "refreshed")).To(BeAnExistingFile())
+	g.Expect(filepath.Glob(filepath.Join(dir, anchorPrefix+"*.crt"))).To(HaveLen(2))
+	anchor, err := os.ReadFile(filepath.Join(dir, anchorPrefix+"1.crt"))
+	g.Expect(err).To(Succeed())
+	g.Expect(anchor).To(Equal(Bundle(certs[1:])))
+
+	g.Expect(store.install(certs[:1])).To(Succeed())
+	g.Expect(filepath.Glob(filepath.Join(dir, anchorPrefix+"*.crt"))).To(HaveLen(1))
+
+	g.Expect(store.install(nil)).To(Succeed())
+	g.Expect(filepath.Glob(filepath.Join(dir, anchorPrefix+"*.crt"))).To(BeEmpty())
+	g.Expect(userAnchor).To(BeAnExistingFile())
+
+	// nothing to add or remove, the store is not refreshed
+	g.Expect(os.Remove(filepath.Join(dir, "refreshed"))).To(Succeed())
+	g.Expect(store.install(nil)).To(Succeed())
+	g.Expect(filepath.Join(dir, "refreshed")).NotTo(BeAnExistingFile())
+}
+
+func TestTrustCertificates(t *testing.T) {
+	g := NewWithT(t)
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	transport := http.DefaultTransport.(*http.Transport)
+	originalTLSConfig, originalRootCAs := transport.TLSClientConfig, rootCAs
+	t.Cleanup(func() {
+		transport.TLSClientConfig, rootCAs = originalTLSConfig, originalRootCAs
+		transport.CloseIdleConnections()
+	})
+
+	// the system certificates are loaded by the first TLS connection
+	_, err := http.Get(server.URL)
+	g.Expect(err).To(MatchError(ContainSubstring("certificate signed by unknown authority")))
+
+	trustCertificates([]*x509.Certificate{server.Certificate()})
+	g.Expect(RootCAs()).NotTo(BeNil())
+
+	resp, err := http.Get(server.URL)
+	g.Expect(err).NotTo(HaveOccurred())
+	resp.Body.Close()
+
+	awsConfig, err := config.LoadDefaultConfig(context.Background(), WithRootCAs, config.WithRegion("us-west-2"))
+	g.Expect(err).NotTo(HaveOccurred())
+	req, err := http.NewRequest(http.MethodGet, server.URL, nil)
+	g.Expect(err).NotTo(HaveOccurred())
+	resp, err = awsConfig.HTTPClient.Do(req)
+	g.Expect(err).NotTo(HaveOccurred())
+	resp.Body.Close()
+}
+
+func generateCA(g *WithT, commonName string) string {
+	cert := &x509.Certificate{
+		SerialNumber: big.NewInt(2025),
+		Subject: pkix.Name{
+			CommonName: commonName,
+		},
+		NotBefore:             time.Now(),
+		NotAfter:              time.Now().AddDate(1, 0, 0),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	certBytes, err := x509.CreateCertificate(rand.Reader, cert, cert, &privateKey.PublicKey, privateKey)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	certPEM := new(bytes.Buffer)
+	g.Expect(pem.Encode(certPEM, &pem.Block{Type: "CERTIFICATE", Bytes: certBytes})).To(Succeed())
+	return certPEM.String()
+}